@@ -0,0 +1,280 @@
+package csp
+
+import (
+	"fmt"
+	"sort"
+)
+
+// VarOrder chooses the next variable to assign.
+type VarOrder int
+
+// Variable orderings.
+const (
+	// MRVDegree picks the variable with the fewest values left, breaking
+	// ties by the most constraints on other unassigned variables.
+	MRVDegree VarOrder = iota
+	// MRV picks the variable with the fewest values left.
+	MRV
+	// Static picks variables in the order they were added.
+	Static
+)
+
+// ValueOrder orders the values tried for a variable.
+type ValueOrder int
+
+// Value orderings.
+const (
+	// LCV tries first the least constraining value: the one that rules
+	// out the fewest values of neighbouring variables.
+	LCV ValueOrder = iota
+	// DomainOrder tries values in the order of the domain.
+	DomainOrder
+)
+
+// Inference is the propagation done after each assignment.
+type Inference int
+
+// Inferences.
+const (
+	// MAC maintains arc consistency with AC-3 after each assignment.
+	MAC Inference = iota
+	// ForwardChecking removes the values of unassigned neighbours that
+	// conflict with the assignment just made.
+	ForwardChecking
+	// NoInference only checks the assignment against assigned variables.
+	NoInference
+)
+
+// Solver solves a Problem by backtracking search.
+// The zero value uses MRVDegree, LCV and MAC.
+type Solver struct {
+	VarOrder   VarOrder
+	ValueOrder ValueOrder
+	Inference  Inference
+
+	// Nodes is the number of assignments tried by the last search
+	// and Backtracks the number of them undone.
+	Nodes, Backtracks int
+
+	p        *Problem
+	assign   []int
+	assigned []bool
+	found    func(assign []int) bool
+}
+
+// Solve returns the first solution found, indexed by Var.
+func (s *Solver) Solve(p *Problem) ([]int, error) {
+	var sol []int
+	s.Search(p, func(assign []int) bool {
+		sol = assign
+		return false
+	})
+	if sol == nil {
+		return nil, fmt.Errorf("csp has no solution")
+	}
+	return sol, nil
+}
+
+// All returns up to limit solutions, or every solution if limit is not positive.
+func (s *Solver) All(p *Problem, limit int) [][]int {
+	sols := [][]int{}
+	s.Search(p, func(assign []int) bool {
+		sols = append(sols, assign)
+		return limit <= 0 || len(sols) < limit
+	})
+	return sols
+}
+
+// Search calls found with each solution, a fresh slice indexed by Var,
+// until found returns false or there are no more.
+//
+// Pseudocode from Russell and Norvig, AIMA 3rd ed. figure 6.5:
+//
+//	function BACKTRACK(assignment, csp) returns a solution, or failure
+//	    if assignment is complete then return assignment
+//	    var := SELECT-UNASSIGNED-VARIABLE(csp)
+//	    for each value in ORDER-DOMAIN-VALUES(var, assignment, csp) do
+//	        if value is consistent with assignment then
+//	            add {var = value} to assignment
+//	            inferences := INFERENCE(csp, var, value)
+//	            if inferences != failure then
+//	                add inferences to assignment
+//	                result := BACKTRACK(assignment, csp)
+//	                if result != failure then return result
+//	        remove {var = value} and inferences from assignment
+//	    return failure
+func (s *Solver) Search(p *Problem, found func(assign []int) bool) {
+	s.Nodes, s.Backtracks = 0, 0
+	s.p = p
+	s.assign = make([]int, len(p.Names))
+	s.assigned = make([]bool, len(p.Names))
+	s.found = found
+	doms, ok := p.nodeConsistent()
+	if ok && s.Inference == MAC {
+		ok = p.ac3(doms, nil, p.allArcs())
+	}
+	if ok {
+		s.backtrack(doms, 0)
+	}
+	s.p, s.found = nil, nil
+}
+
+// backtrack returns false once found asks to stop.
+func (s *Solver) backtrack(doms [][]int, n int) bool {
+	if n == len(s.assign) {
+		return s.found(append([]int{}, s.assign...))
+	}
+	x := s.selectVar(doms)
+	s.assigned[x] = true
+	defer func() { s.assigned[x] = false }()
+	for _, v := range s.orderValues(x, doms) {
+		if !s.consistent(x, v) {
+			continue
+		}
+		s.Nodes++
+		s.assign[x] = v
+		next := append([][]int{}, doms...)
+		next[x] = []int{v}
+		if s.infer(x, v, next) {
+			if !s.backtrack(next, n+1) {
+				return false
+			}
+		}
+		s.Backtracks++
+	}
+	return true
+}
+
+func (s *Solver) selectVar(doms [][]int) Var {
+	best, bestN, bestDeg := Var(-1), 0, 0
+	for i := range doms {
+		x := Var(i)
+		if s.assigned[x] {
+			continue
+		}
+		if s.VarOrder == Static {
+			return x
+		}
+		n := len(doms[x])
+		if best >= 0 && n > bestN {
+			continue
+		}
+		deg := 0
+		if s.VarOrder == MRVDegree {
+			deg = s.degree(x)
+		}
+		if best < 0 || n < bestN || deg > bestDeg {
+			best, bestN, bestDeg = x, n, deg
+		}
+	}
+	return best
+}
+
+// degree counts the constraints on x and other unassigned variables.
+func (s *Solver) degree(x Var) int {
+	d := 0
+	for _, c := range s.p.byVar[x] {
+		for _, y := range c.Vars {
+			if y != x && !s.assigned[y] {
+				d++
+				break
+			}
+		}
+	}
+	return d
+}
+
+func (s *Solver) orderValues(x Var, doms [][]int) []int {
+	vv := append([]int{}, doms[x]...)
+	if s.ValueOrder != LCV || len(vv) < 2 {
+		return vv
+	}
+	ruled := make(map[int]int, len(vv))
+	for _, v := range vv {
+		for _, c := range s.p.byVar[x] {
+			if c.binary == nil {
+				continue
+			}
+			y := c.Vars[0]
+			if y == x {
+				y = c.Vars[1]
+			}
+			if s.assigned[y] {
+				continue
+			}
+			for _, w := range doms[y] {
+				if !c.holds(x, v, y, w) {
+					ruled[v]++
+				}
+			}
+		}
+	}
+	sort.SliceStable(vv, func(i, j int) bool { return ruled[vv[i]] < ruled[vv[j]] })
+	return vv
+}
+
+// consistent reports whether x=v satisfies every constraint whose
+// other variables are all assigned.
+func (s *Solver) consistent(x Var, v int) bool {
+	s.assign[x] = v
+	for _, c := range s.p.byVar[x] {
+		if s.allAssigned(c) && !c.Satisfied(s.assign) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Solver) allAssigned(c *Constraint) bool {
+	for _, y := range c.Vars {
+		if !s.assigned[y] {
+			return false
+		}
+	}
+	return true
+}
+
+// infer propagates x=v through doms, returning false if a domain empties.
+// Both inferences forward check every constraint on x, which for an
+// n-ary constraint prunes the one variable left unassigned, if any;
+// MAC then makes the binary constraints arc consistent.
+func (s *Solver) infer(x Var, v int, doms [][]int) bool {
+	if s.Inference == NoInference {
+		return true
+	}
+	for _, c := range s.p.byVar[x] {
+		if !s.forwardCheck(c, doms) {
+			return false
+		}
+	}
+	if s.Inference == MAC {
+		return s.p.ac3(doms, s.assigned, s.p.arcsInto(x, s.assigned, x))
+	}
+	return true
+}
+
+// forwardCheck removes the values of the only unassigned variable of c,
+// if there is exactly one, that would violate c.
+func (s *Solver) forwardCheck(c *Constraint, doms [][]int) bool {
+	y := Var(-1)
+	for _, z := range c.Vars {
+		if !s.assigned[z] {
+			if y >= 0 && z != y {
+				return true
+			}
+			y = z
+		}
+	}
+	if y < 0 {
+		return true
+	}
+	kept := []int{}
+	for _, w := range doms[y] {
+		s.assign[y] = w
+		if c.Satisfied(s.assign) {
+			kept = append(kept, w)
+		}
+	}
+	doms[y] = kept
+	return len(kept) > 0
+}
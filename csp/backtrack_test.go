@@ -0,0 +1,168 @@
+package csp
+
+import (
+	"fmt"
+	"testing"
+)
+
+func ExampleSolver() {
+	// the zebra puzzle: each attribute is a variable whose value is
+	// the number of the house, 1 to 5, that has it.
+	p := NewProblem()
+	v := map[string]Var{}
+	for _, group := range [][]string{
+		{"red", "green", "ivory", "yellow", "blue"},
+		{"English", "Spanish", "Ukrainian", "Norwegian", "Japanese"},
+		{"coffee", "tea", "milk", "orange juice", "water"},
+		{"Old Gold", "Kools", "Chesterfield", "Lucky Strike", "Parliament"},
+		{"dog", "snails", "fox", "horse", "zebra"},
+	} {
+		vars := []Var{}
+		for _, name := range group {
+			v[name] = p.AddVar(name, 1, 2, 3, 4, 5)
+			vars = append(vars, v[name])
+		}
+		p.AllDifferent(vars...)
+	}
+	same := func(a, b string) { p.Equal(v[a], v[b]) }
+	nextTo := func(a, b string) {
+		p.Binary(v[a], v[b], func(x, y int) bool { return x-y == 1 || y-x == 1 })
+	}
+	at := func(a string, house int) { p.Unary(v[a], func(x int) bool { return x == house }) }
+
+	same("English", "red")
+	same("Spanish", "dog")
+	same("coffee", "green")
+	same("Ukrainian", "tea")
+	p.Binary(v["green"], v["ivory"], func(g, i int) bool { return g == i+1 })
+	same("Old Gold", "snails")
+	same("Kools", "yellow")
+	at("milk", 3)
+	at("Norwegian", 1)
+	nextTo("Chesterfield", "fox")
+	nextTo("Kools", "horse")
+	same("Lucky Strike", "orange juice")
+	same("Japanese", "Parliament")
+	nextTo("Norwegian", "blue")
+
+	s := &Solver{}
+	sols := s.All(p, 0)
+	fmt.Println(len(sols), "solution")
+	owner := func(thing string) string {
+		for _, n := range []string{"English", "Spanish", "Ukrainian", "Norwegian", "Japanese"} {
+			if sols[0][v[n]] == sols[0][v[thing]] {
+				return n
+			}
+		}
+		return ""
+	}
+	fmt.Println(owner("zebra"), "owns the zebra.")
+	fmt.Println(owner("water"), "drinks water.")
+	// Output:
+	// 1 solution
+	// Japanese owns the zebra.
+	// Norwegian drinks water.
+}
+
+// queens places n queens on an n by n board, one per column; the value
+// of each variable is the queen's row.
+func queens(n int) *Problem {
+	p := NewProblem()
+	for c := 0; c < n; c++ {
+		rows := []int{}
+		for r := 0; r < n; r++ {
+			rows = append(rows, r)
+		}
+		p.AddVar(fmt.Sprint("Q", c), rows...)
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			d := j - i
+			p.Binary(Var(i), Var(j), func(a, b int) bool { return a != b && a-b != d && b-a != d })
+		}
+	}
+	return p
+}
+
+func TestQueensAllConfigurations(t *testing.T) {
+	for _, vo := range []VarOrder{MRVDegree, MRV, Static} {
+		for _, val := range []ValueOrder{LCV, DomainOrder} {
+			for _, inf := range []Inference{MAC, ForwardChecking, NoInference} {
+				s := &Solver{VarOrder: vo, ValueOrder: val, Inference: inf}
+				sols := s.All(queens(8), 0)
+				if len(sols) != 92 {
+					t.Errorf("%v/%v/%v: found %d solutions", vo, val, inf, len(sols))
+				}
+				for _, sol := range sols {
+					if !queens(8).Consistent(sol) {
+						t.Fatalf("%v/%v/%v: bad solution %v", vo, val, inf, sol)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestInferencePrunes(t *testing.T) {
+	nodes := []int{}
+	for _, inf := range []Inference{MAC, ForwardChecking, NoInference} {
+		s := &Solver{VarOrder: Static, ValueOrder: DomainOrder, Inference: inf}
+		if _, err := s.Solve(queens(20)); err != nil {
+			t.Fatal(err)
+		}
+		nodes = append(nodes, s.Nodes)
+	}
+	if !(nodes[0] <= nodes[1] && nodes[1] < nodes[2]) {
+		t.Errorf("nodes for MAC, forward checking, none: %v", nodes)
+	}
+}
+
+func TestLargeQueens(t *testing.T) {
+	s := &Solver{}
+	sol, err := s.Solve(queens(60))
+	if err != nil || !queens(60).Consistent(sol) {
+		t.Errorf("no solution: %v %v", sol, err)
+	}
+}
+
+func TestNaryCryptarithm(t *testing.T) {
+	// TWO + TWO = FOUR, AIMA figure 6.2, with carry variables.
+	p := NewProblem()
+	digits := func(name string, lo int) Var {
+		d := []int{}
+		for i := lo; i <= 9; i++ {
+			d = append(d, i)
+		}
+		return p.AddVar(name, d...)
+	}
+	T, W, O := digits("T", 1), digits("W", 0), digits("O", 0)
+	F, U, R := digits("F", 1), digits("U", 0), digits("R", 0)
+	c1, c2 := p.AddVar("C1", 0, 1), p.AddVar("C2", 0, 1)
+	c3 := p.AddVar("C3", 0, 1)
+	p.AllDifferent(T, W, O, F, U, R)
+	p.Nary([]Var{O, R, c1}, func(v []int) bool { return 2*v[0] == v[1]+10*v[2] })
+	p.Nary([]Var{c1, W, U, c2}, func(v []int) bool { return v[0]+2*v[1] == v[2]+10*v[3] })
+	p.Nary([]Var{c2, T, O, c3}, func(v []int) bool { return v[0]+2*v[1] == v[2]+10*v[3] })
+	p.Equal(c3, F)
+
+	for _, inf := range []Inference{MAC, ForwardChecking, NoInference} {
+		s := &Solver{Inference: inf}
+		sols := s.All(p, 0)
+		for _, sol := range sols {
+			two := 100*sol[T] + 10*sol[W] + sol[O]
+			four := 1000*sol[F] + 100*sol[O] + 10*sol[U] + sol[R]
+			if two+two != four {
+				t.Errorf("%v: %d + %d != %d", inf, two, two, four)
+			}
+		}
+		if len(sols) != 7 {
+			t.Errorf("%v: found %d solutions", inf, len(sols))
+		}
+	}
+}
+
+func TestAllLimit(t *testing.T) {
+	if n := len((&Solver{}).All(queens(8), 5)); n != 5 {
+		t.Errorf("found %d solutions", n)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+)
+
+var solver = flag.String("solver", "logic", "logic: naked and hidden singles plus search;\ncsp: the csp package's backtracking search;\nprop: logic, with its solutions checked in propositional logic through know")
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: sudoku [flags] [file...]\n\n"+
+			"Reads puzzles from the files, or standard input, one per line or\n"+
+			"drawn over several lines and separated by blank lines. Empty cells\n"+
+			"are . 0 or _ and values above 9 are letters from A.\n\nflags:\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	pp := []*puzzle{}
+	if flag.NArg() == 0 {
+		pp = read(os.Stdin, "stdin")
+	}
+	for _, name := range flag.Args() {
+		f, err := os.Open(name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		pp = append(pp, read(f, name)...)
+		f.Close()
+	}
+
+	for i, p := range pp {
+		fmt.Printf("Puzzle %d:\n%s\n", i+1, p.format(p.cells))
+		start := time.Now()
+		sols, st, err := solve(p)
+		if err != nil {
+			log.Fatal(err)
+		}
+		elapsed := time.Since(start)
+		switch len(sols) {
+		case 0:
+			fmt.Println("No solution.")
+		case 1:
+			fmt.Printf("Solution:\n%s", p.format(sols[0]))
+		default:
+			fmt.Printf("More than one solution, for example:\n%s\nand\n%s", p.format(sols[0]), p.format(sols[1]))
+		}
+		fmt.Printf("%s solver: %v in %v\n\n", *solver, st, elapsed.Round(time.Microsecond))
+	}
+}
+
+func read(r io.Reader, name string) []*puzzle {
+	pp, err := readPuzzles(r)
+	if err != nil {
+		log.Fatalf("%s: %v", name, err)
+	}
+	return pp
+}
+
+// solve returns up to two solutions of p, enough to tell whether it has
+// exactly one, using the chosen solver. The prop solver returns an error
+// if its check fails.
+func solve(p *puzzle) ([][]int, stats, error) {
+	switch *solver {
+	case "logic":
+		sols, st := solveLogic(p, 2)
+		return sols, st, nil
+	case "csp":
+		sols, st := solveCSP(p, 2)
+		return sols, st, nil
+	case "prop":
+		sols, st := solveLogic(p, 2)
+		if err := checkProp(p, sols, 2); err != nil {
+			return nil, st, fmt.Errorf("propositional check failed: %v", err)
+		}
+		return sols, st, nil
+	}
+	return nil, stats{}, fmt.Errorf("unknown solver %q", *solver)
+}
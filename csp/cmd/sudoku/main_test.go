@@ -0,0 +1,170 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const easy = "53..7....6..195....98....6.8...6...34..8.3..17...2...6.6....28....419..5....8..79"
+const hard = "4.....8.5.3..........7......2.....6.....8.4......1.......6.3.7.5..2.....1.4......"
+
+func parse(t *testing.T, s string) *puzzle {
+	t.Helper()
+	pp, err := readPuzzles(strings.NewReader(s))
+	if err != nil || len(pp) != 1 {
+		t.Fatalf("read %d puzzles: %v", len(pp), err)
+	}
+	return pp[0]
+}
+
+// valid reports whether sol completes p with every unit all different.
+func valid(p *puzzle, sol []int) bool {
+	for i, v := range p.cells {
+		if v != 0 && sol[i] != v {
+			return false
+		}
+	}
+	for _, u := range p.units() {
+		seen := map[int]bool{}
+		for _, cell := range u {
+			v := sol[cell]
+			if v < 1 || v > p.size() || seen[v] {
+				return false
+			}
+			seen[v] = true
+		}
+	}
+	return true
+}
+
+type solverFunc func(p *puzzle, limit int) ([][]int, error)
+
+func solvers() map[string]solverFunc {
+	return map[string]solverFunc{
+		"logic": func(p *puzzle, limit int) ([][]int, error) { s, _ := solveLogic(p, limit); return s, nil },
+		"csp":   func(p *puzzle, limit int) ([][]int, error) { s, _ := solveCSP(p, limit); return s, nil },
+		"prop": func(p *puzzle, limit int) ([][]int, error) {
+			s, _, err := solveProp(p, limit)
+			return s, err
+		},
+	}
+}
+
+func TestReadPuzzles(t *testing.T) {
+	pp, err := readPuzzles(strings.NewReader(easy + "\n" + hard + "\n\n" + `
+1 . | . .
+. . | 3 .
+----+----
+. 4 | . .
+. . | . 2
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pp) != 3 || pp[0].n != 3 || pp[1].n != 3 || pp[2].n != 2 {
+		t.Fatalf("unexpected puzzles: %d", len(pp))
+	}
+	if pp[2].cells[6] != 3 || pp[2].cells[15] != 2 {
+		t.Errorf("unexpected cells: %v", pp[2].cells)
+	}
+	again := parse(t, pp[0].format(pp[0].cells))
+	if !reflect.DeepEqual(again.cells, pp[0].cells) {
+		t.Errorf("formatted puzzle reads back differently:\n%s", pp[0].format(pp[0].cells))
+	}
+
+	tooLarge := strings.Repeat(".", 36*36) + "\n"
+	for _, bad := range []string{"123\n456\n", "1x34\n", "5...\n....\n....\n....\n", tooLarge} {
+		if _, err := readPuzzles(strings.NewReader(bad)); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestSolversAgree(t *testing.T) {
+	for _, s := range []string{easy, hard} {
+		p := parse(t, s)
+		var want [][]int
+		for name, solve := range solvers() {
+			sols, err := solve(p, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(sols) != 1 || !valid(p, sols[0]) {
+				t.Fatalf("%s: %d solutions", name, len(sols))
+			}
+			if want == nil {
+				want = sols
+			} else if !reflect.DeepEqual(sols, want) {
+				t.Errorf("%s: solution differs", name)
+			}
+		}
+	}
+}
+
+func TestMultipleAndNoSolutions(t *testing.T) {
+	empty := parse(t, "....\n....\n....\n....\n")
+	clash := parse(t, "1 1 . .\n. . . .\n. . . .\n. . . .\n")
+	for name, solve := range solvers() {
+		sols, err := solve(empty, 2)
+		if err != nil || len(sols) != 2 || reflect.DeepEqual(sols[0], sols[1]) {
+			t.Errorf("%s: empty grid gave %d solutions, %v", name, len(sols), err)
+		}
+		if sols, _ := solve(clash, 2); len(sols) != 0 {
+			t.Errorf("%s: clashing givens solved", name)
+		}
+	}
+}
+
+func TestCheckProp(t *testing.T) {
+	p := parse(t, "1 . . .\n. . 3 .\n. 4 . .\n. . . 2\n")
+	sols, _ := solveLogic(p, 2)
+	if len(sols) != 1 {
+		t.Fatalf("%d solutions", len(sols))
+	}
+	if err := checkProp(p, sols, 2); err != nil {
+		t.Error(err)
+	}
+
+	tampered := append([]int{}, sols[0]...)
+	tampered[1], tampered[2] = tampered[2], tampered[1]
+	if err := checkProp(p, [][]int{tampered}, 2); err == nil {
+		t.Error("tampered solution passed")
+	}
+	if err := checkProp(p, nil, 2); err == nil {
+		t.Error("missed solution passed")
+	}
+	if err := checkProp(parse(t, "2...\n....\n....\n....\n"), sols, 1); err == nil {
+		t.Error("solution of another puzzle passed")
+	}
+}
+
+func TestLogicUsesSingles(t *testing.T) {
+	sols, st := solveLogic(parse(t, easy), 2)
+	if len(sols) != 1 || st.Nodes != 0 || st.NakedSingles+st.HiddenSingles != strings.Count(easy, ".") {
+		t.Errorf("%d solutions, %v", len(sols), st)
+	}
+}
+
+func TestSixteenBySixteen(t *testing.T) {
+	// a known grid with every third cell cleared.
+	var sb strings.Builder
+	for r := 0; r < 16; r++ {
+		for c := 0; c < 16; c++ {
+			if (r*16+c)%3 == 0 {
+				sb.WriteByte('.')
+			} else {
+				sb.WriteByte(symbols[(r*4+r/4+c)%16])
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	p := parse(t, sb.String())
+	if p.n != 4 {
+		t.Fatalf("box size %d", p.n)
+	}
+	sols, _ := solveLogic(p, 2)
+	if len(sols) == 0 || !valid(p, sols[0]) {
+		t.Errorf("no valid solution among %d", len(sols))
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// puzzle is a sudoku of n²×n² cells, numbered row by row, made of n×n boxes.
+// Cells hold 1 to n², or 0 when empty.
+type puzzle struct {
+	n     int
+	cells []int
+}
+
+func (p *puzzle) size() int {
+	return p.n * p.n
+}
+
+// units returns the rows, columns and boxes, each a list of cells.
+func (p *puzzle) units() [][]int {
+	sz := p.size()
+	uu := [][]int{}
+	for i := 0; i < sz; i++ {
+		row, col, box := []int{}, []int{}, []int{}
+		br, bc := i/p.n*p.n, i%p.n*p.n
+		for j := 0; j < sz; j++ {
+			row = append(row, i*sz+j)
+			col = append(col, j*sz+i)
+			box = append(box, (br+j/p.n)*sz+bc+j%p.n)
+		}
+		uu = append(uu, row, col, box)
+	}
+	return uu
+}
+
+// symbols are used for values 1 upwards, so 16×16 puzzles use 1-9 and A-G.
+const symbols = "123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// value returns the value of a cell character, 0 for an empty cell
+// and -1 for a character that is not a cell.
+func value(c rune) int {
+	switch c {
+	case '.', '0', '_':
+		return 0
+	}
+	if i := strings.IndexRune(symbols, c); i >= 0 {
+		return i + 1
+	}
+	if i := strings.IndexRune(strings.ToLower(symbols), c); i >= 9 {
+		return i + 1
+	}
+	return -1
+}
+
+// boxSize returns n if cells is n⁴ for a puzzle of at least 4×4 cells.
+func boxSize(cells int) (int, bool) {
+	for n := 2; n*n*n*n <= cells; n++ {
+		if n*n*n*n == cells {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+// readPuzzles reads puzzles separated by blank lines. Spaces and the
+// characters | + - used to draw boxes are ignored. A line holding a whole
+// 9×9 or larger puzzle on its own is a puzzle, so files with one puzzle
+// per line need no blank lines.
+func readPuzzles(r io.Reader) ([]*puzzle, error) {
+	pp := []*puzzle{}
+	cells := []int{}
+	line := 0
+	flush := func() error {
+		if len(cells) == 0 {
+			return nil
+		}
+		n, ok := boxSize(len(cells))
+		if !ok {
+			return fmt.Errorf("line %d: puzzle of %d cells is not square", line, len(cells))
+		}
+		if n*n > len(symbols) {
+			return fmt.Errorf("line %d: %d×%d puzzle too large, at most %d symbols", line, n*n, n*n, len(symbols))
+		}
+		for _, v := range cells {
+			if v > n*n {
+				return fmt.Errorf("line %d: value %c too large for a %d×%d puzzle", line, symbols[v-1], n*n, n*n)
+			}
+		}
+		pp = append(pp, &puzzle{n: n, cells: cells})
+		cells = []int{}
+		return nil
+	}
+
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line++
+		row := []int{}
+		for _, c := range sc.Text() {
+			switch c {
+			case ' ', '\t', '|', '+', '-':
+				continue
+			}
+			v := value(c)
+			if v < 0 {
+				return nil, fmt.Errorf("line %d: unexpected character %q", line, c)
+			}
+			row = append(row, v)
+		}
+		if strings.TrimSpace(sc.Text()) == "" {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if n, ok := boxSize(len(row)); ok && n >= 3 && len(cells) == 0 {
+			cells = row
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		cells = append(cells, row...)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return pp, nil
+}
+
+// format draws cells, which may be a solution of p, with lines between the boxes.
+func (p *puzzle) format(cells []int) string {
+	var sb strings.Builder
+	sz := p.size()
+	for r := 0; r < sz; r++ {
+		if r > 0 && r%p.n == 0 {
+			for b := 0; b < p.n; b++ {
+				if b > 0 {
+					sb.WriteString("-+")
+				}
+				sb.WriteString(strings.Repeat("-", 2*p.n))
+			}
+			sb.WriteByte('\n')
+		}
+		for c := 0; c < sz; c++ {
+			if c > 0 && c%p.n == 0 {
+				sb.WriteString(" |")
+			}
+			sb.WriteByte(' ')
+			if v := cells[r*sz+c]; v == 0 {
+				sb.WriteByte('.')
+			} else {
+				sb.WriteByte(symbols[v-1])
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
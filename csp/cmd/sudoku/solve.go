@@ -0,0 +1,326 @@
+package main
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/siuyin/ai/csp"
+	k "github.com/siuyin/ai/know"
+)
+
+// stats counts the work done by a solver.
+type stats struct {
+	Nodes         int // guesses made by search
+	NakedSingles  int // cells with one candidate left
+	HiddenSingles int // values with one place left in a unit
+	Backtracks    int
+}
+
+func (s stats) String() string {
+	return fmt.Sprintf("%d nodes, %d naked singles, %d hidden singles, %d backtracks",
+		s.Nodes, s.NakedSingles, s.HiddenSingles, s.Backtracks)
+}
+
+// logic solves by propagating naked and hidden singles, guessing only
+// when neither applies, in the cell with fewest candidates.
+type logic struct {
+	p     *puzzle
+	units [][]int
+	peers [][]int
+	limit int
+	sols  [][]int
+	stats stats
+}
+
+// candidates holds a bit set of the values still possible in each cell,
+// bit v-1 for value v.
+type candidates []uint64
+
+// solveLogic returns up to limit solutions of p.
+func solveLogic(p *puzzle, limit int) ([][]int, stats) {
+	l := &logic{p: p, units: p.units(), limit: limit}
+	l.peers = make([][]int, len(p.cells))
+	for cell := range p.cells {
+		seen := map[int]bool{cell: true}
+		for _, u := range l.units {
+			if !containsInt(u, cell) {
+				continue
+			}
+			for _, o := range u {
+				if !seen[o] {
+					seen[o] = true
+					l.peers[cell] = append(l.peers[cell], o)
+				}
+			}
+		}
+	}
+
+	all := uint64(1)<<uint(p.size()) - 1
+	cand := make(candidates, len(p.cells))
+	for i := range cand {
+		cand[i] = all
+	}
+	for i, v := range p.cells {
+		if v != 0 && !l.assign(cand, i, v) {
+			return nil, l.stats
+		}
+	}
+	if l.propagate(cand) {
+		l.search(cand)
+	}
+	return l.sols, l.stats
+}
+
+func containsInt(xx []int, x int) bool {
+	for _, y := range xx {
+		if y == x {
+			return true
+		}
+	}
+	return false
+}
+
+// assign sets cell to v and removes v from its peers, returning false
+// if a peer is left with no candidates.
+func (l *logic) assign(cand candidates, cell, v int) bool {
+	bit := uint64(1) << uint(v-1)
+	if cand[cell]&bit == 0 {
+		return false
+	}
+	cand[cell] = bit
+	for _, o := range l.peers[cell] {
+		if cand[o]&bit == 0 {
+			continue
+		}
+		cand[o] &^= bit
+		if cand[o] == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// propagate applies naked and hidden singles until neither applies,
+// returning false on a contradiction.
+func (l *logic) propagate(cand candidates) bool {
+	done := make([]bool, len(cand)) // singles whose peers have been cleared
+	for cell, c := range cand {
+		done[cell] = bits.OnesCount64(c) == 1 && l.cleared(cand, cell)
+	}
+	for changed := true; changed; {
+		changed = false
+		for cell, c := range cand {
+			if done[cell] || bits.OnesCount64(c) != 1 {
+				continue
+			}
+			done[cell] = true
+			l.stats.NakedSingles++
+			if !l.assign(cand, cell, bits.TrailingZeros64(c)+1) {
+				return false
+			}
+			changed = true
+		}
+		for _, u := range l.units {
+			for v := 1; v <= l.p.size(); v++ {
+				bit := uint64(1) << uint(v-1)
+				place, n := -1, 0
+				for _, cell := range u {
+					if cand[cell]&bit != 0 {
+						place, n = cell, n+1
+					}
+				}
+				if n == 0 {
+					return false
+				}
+				if n == 1 && cand[place] != bit {
+					l.stats.HiddenSingles++
+					if !l.assign(cand, place, v) {
+						return false
+					}
+					done[place] = true
+					changed = true
+				}
+			}
+		}
+	}
+	return true
+}
+
+// cleared reports whether no peer of cell still has its value as a candidate.
+func (l *logic) cleared(cand candidates, cell int) bool {
+	for _, o := range l.peers[cell] {
+		if cand[o]&cand[cell] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// search returns false once limit solutions are found.
+func (l *logic) search(cand candidates) bool {
+	best, bestN := -1, 0
+	for cell, c := range cand {
+		if n := bits.OnesCount64(c); n > 1 && (best < 0 || n < bestN) {
+			best, bestN = cell, n
+		}
+	}
+	if best < 0 {
+		sol := make([]int, len(cand))
+		for i, c := range cand {
+			sol[i] = bits.TrailingZeros64(c) + 1
+		}
+		l.sols = append(l.sols, sol)
+		return len(l.sols) < l.limit
+	}
+	for c := cand[best]; c != 0; c &= c - 1 {
+		l.stats.Nodes++
+		next := append(candidates{}, cand...)
+		if l.assign(next, best, bits.TrailingZeros64(c)+1) && l.propagate(next) {
+			if !l.search(next) {
+				return false
+			}
+		}
+		l.stats.Backtracks++
+	}
+	return true
+}
+
+// solveCSP returns up to limit solutions of p found by the csp package,
+// with a variable for each cell and the rows, columns and boxes all different.
+func solveCSP(p *puzzle, limit int) ([][]int, stats) {
+	prob := csp.NewProblem()
+	values := []int{}
+	for v := 1; v <= p.size(); v++ {
+		values = append(values, v)
+	}
+	for i, v := range p.cells {
+		if v == 0 {
+			prob.AddVar(fmt.Sprint(i), values...)
+		} else {
+			prob.AddVar(fmt.Sprint(i), v)
+		}
+	}
+	for _, u := range p.units() {
+		vv := []csp.Var{}
+		for _, cell := range u {
+			vv = append(vv, csp.Var(cell))
+		}
+		prob.AllDifferent(vv...)
+	}
+	s := &csp.Solver{}
+	sols := s.All(prob, limit)
+	return sols, stats{Nodes: s.Nodes, Backtracks: s.Backtracks}
+}
+
+// propSym is the symbol for cell holding v.
+func propSym(cell, v int) k.Prop {
+	return k.Sym(fmt.Sprintf("%d=%d", cell, v))
+}
+
+// encodeProp encodes p in propositional logic, with a symbol for each
+// value in each cell. Its models are the solutions of p.
+func encodeProp(p *puzzle) k.Prop {
+	sz := p.size()
+	kb := k.And()
+	for cell, given := range p.cells {
+		if given != 0 {
+			kb = kb.Add(propSym(cell, given))
+		}
+		some := k.Or()
+		for v := 1; v <= sz; v++ {
+			some = some.Add(propSym(cell, v))
+			for w := v + 1; w <= sz; w++ {
+				kb = kb.Add(k.Not(k.And(propSym(cell, v), propSym(cell, w))))
+			}
+		}
+		kb = kb.Add(some)
+	}
+	for _, u := range p.units() {
+		for v := 1; v <= sz; v++ {
+			some := k.Or()
+			for i, a := range u {
+				some = some.Add(propSym(a, v))
+				for _, b := range u[i+1:] {
+					kb = kb.Add(k.Not(k.And(propSym(a, v), propSym(b, v))))
+				}
+			}
+			kb = kb.Add(some)
+		}
+	}
+	return kb
+}
+
+// excludeProp returns a clause that only the model of sol falsifies.
+func excludeProp(sol []int) k.Prop {
+	other := k.Or()
+	for cell, v := range sol {
+		other = other.Add(k.Not(propSym(cell, v)))
+	}
+	return other
+}
+
+// solveProp returns up to limit solutions of p found by asking know for
+// models of its propositional encoding. After each model is found, a
+// clause ruling it out is added before asking again.
+func solveProp(p *puzzle, limit int) ([][]int, stats, error) {
+	kb := encodeProp(p)
+	sols := [][]int{}
+	for len(sols) < limit {
+		model, ok, err := k.Satisfiable(kb)
+		if err != nil {
+			return nil, stats{}, err
+		}
+		if !ok {
+			break
+		}
+		sol := make([]int, len(p.cells))
+		for cell := range sol {
+			for v := 1; v <= p.size(); v++ {
+				if model[propSym(cell, v)] {
+					sol[cell] = v
+				}
+			}
+		}
+		sols = append(sols, sol)
+		kb = k.And(kb, excludeProp(sol))
+	}
+	return sols, stats{}, nil
+}
+
+// checkProp checks up to limit solutions of p found by another solver
+// against its propositional encoding. Each must be a model of the
+// encoding and, if fewer than limit were found, the encoding must have
+// no other models.
+func checkProp(p *puzzle, sols [][]int, limit int) error {
+	kb := encodeProp(p)
+	for i, sol := range sols {
+		model := map[k.Prop]bool{}
+		for cell := range sol {
+			for v := 1; v <= p.size(); v++ {
+				model[propSym(cell, v)] = sol[cell] == v
+			}
+		}
+		ok, err := kb.Evaluate(model)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("solution %d breaks the rules", i+1)
+		}
+	}
+	if len(sols) >= limit {
+		return nil
+	}
+	rest := kb
+	for _, sol := range sols {
+		rest = k.And(rest, excludeProp(sol))
+	}
+	_, ok, err := k.Satisfiable(rest)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return fmt.Errorf("a solution was missed")
+	}
+	return nil
+}
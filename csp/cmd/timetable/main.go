@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/siuyin/ai/csp"
+)
+
+var (
+	maxNodes = flag.Int("max", 0, "stop after this many nodes with the best timetable so far, if positive")
+	verbose  = flag.Bool("v", false, "print the penalty of each better timetable as it is found")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), `usage: timetable [flags] scenario.yaml|scenario.json
+
+Finds the timetable, or other schedule, that meets the hard constraints
+of the scenario and breaks the fewest soft ones by weight. See testdata
+for example scenarios.
+
+flags:
+`)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	sc, err := readScenario(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	p, err := sc.problem()
+	if err != nil {
+		log.Fatal(err)
+	}
+	o := &csp.Optimizer{MaxNodes: *maxNodes}
+	start := time.Now()
+	if *verbose {
+		o.Improved = func(_ []int, penalty int) {
+			fmt.Printf("penalty %d after %d nodes, %v\n", penalty, o.Nodes, time.Since(start).Round(time.Microsecond))
+		}
+	}
+	sol, err := o.Optimize(p)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if sc.Name != "" {
+		fmt.Println(sc.Name)
+	}
+	fmt.Print(sc.format(sol))
+	fmt.Println()
+	fmt.Print(explain(p, sol))
+	proof := "best found"
+	if o.Optimal {
+		proof = "optimal"
+	}
+	fmt.Printf("%s after %d nodes in %v\n", proof, o.Nodes, time.Since(start).Round(time.Microsecond))
+}
+
+// format lists the variables taking each value, in the order of the values.
+func (sc *scenario) format(sol []int) string {
+	width := 0
+	for _, v := range sc.Values {
+		if len(v) > width {
+			width = len(v)
+		}
+	}
+	var sb strings.Builder
+	for v, name := range sc.Values {
+		vars := []string{}
+		for x, w := range sol {
+			if w == v {
+				vars = append(vars, sc.Variables[x])
+			}
+		}
+		fmt.Fprintln(&sb, strings.TrimSpace(fmt.Sprintf("%-*s  %s", width, name, strings.Join(vars, ", "))))
+	}
+	return sb.String()
+}
+
+// explain gives the penalty of sol and the soft constraints it violates.
+func explain(p *csp.Problem, sol []int) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "penalty %d\n", p.Penalty(sol))
+	for _, c := range p.Violated(sol) {
+		fmt.Fprintf(&sb, "%5d  %s\n", c.Weight, c.Name)
+	}
+	return sb.String()
+}
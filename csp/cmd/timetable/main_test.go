@@ -0,0 +1,103 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/siuyin/ai/csp"
+)
+
+func optimize(t *testing.T, name string) (*scenario, *csp.Problem, []int) {
+	t.Helper()
+	sc, err := readScenario(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := sc.problem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := &csp.Optimizer{}
+	sol, err := o.Optimize(p)
+	if err != nil || !o.Optimal {
+		t.Fatalf("%s: optimal %v: %v", name, o.Optimal, err)
+	}
+	return sc, p, sol
+}
+
+func TestExams(t *testing.T) {
+	sc, p, sol := optimize(t, "testdata/exams.yaml")
+	at := func(exam string) string { return sc.Values[sol[indexOf(sc.Variables, exam)]] }
+	if at("maths") == at("physics") || at("english") == at("history") || at("biology") == at("chemistry") {
+		t.Error("exams with shared students clash")
+	}
+	if strings.Contains(sc.format(sol), "Wed PM  ") {
+		t.Errorf("exam in the hall during prize giving:\n%s", sc.format(sol))
+	}
+	want := "penalty 1\n    1  languages together: french and english same\n"
+	if got := explain(p, sol); got != want {
+		t.Errorf("got explanation\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestShifts(t *testing.T) {
+	sc, p, sol := optimize(t, "testdata/shifts.json")
+	if !p.Consistent(sol) || p.Penalty(sol) != 1 {
+		t.Errorf("penalty %d:\n%s", p.Penalty(sol), sc.format(sol))
+	}
+	if v := p.Violated(sol); len(v) != 1 || !strings.HasPrefix(v[0].Name, "fair share:") {
+		t.Errorf("violated %v", v)
+	}
+}
+
+func TestYAMLAndJSONAgree(t *testing.T) {
+	dir, err := ioutil.TempDir("", "timetable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"a.yaml": "name: t\nvalues: [x, y]\nvariables: [a, b]\nconstraints:\n- {type: different, vars: [a, b], weight: 2}\n",
+		"a.json": `{"name": "t", "values": ["x", "y"], "variables": ["a", "b"],
+			"constraints": [{"type": "different", "vars": ["a", "b"], "weight": 2}]}`,
+	}
+	got := []*scenario{}
+	for name, s := range files {
+		name = filepath.Join(dir, name)
+		if err := ioutil.WriteFile(name, []byte(s), 0644); err != nil {
+			t.Fatal(err)
+		}
+		sc, err := readScenario(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, sc)
+	}
+	if !reflect.DeepEqual(got[0], got[1]) {
+		t.Errorf("%+v differs from %+v", got[0], got[1])
+	}
+}
+
+func TestScenarioErrors(t *testing.T) {
+	for _, c := range []struct {
+		c    constraint
+		want string
+	}{
+		{constraint{Type: "different", Vars: []string{"a", "z"}}, `unknown variable "z"`},
+		{constraint{Type: "in", Vars: []string{"a"}, Values: []string{"w"}}, `unknown value "w"`},
+		{constraint{Type: "in", Vars: []string{"a"}}, "no values"},
+		{constraint{Type: "atmost", Vars: []string{"a", "b"}}, "count must be positive"},
+		{constraint{Type: "nearby", Vars: []string{"a"}}, "unknown type"},
+		{constraint{Type: "same", Vars: []string{"a", "b"}, Weight: -1}, "negative weight -1"},
+	} {
+		sc := &scenario{Values: []string{"x", "y"}, Variables: []string{"a", "b"}, Constraints: []constraint{c.c}}
+		_, err := sc.problem()
+		if err == nil || !strings.HasSuffix(err.Error(), c.want) {
+			t.Errorf("%+v: got error %v, want %s", c.c, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/siuyin/ai/csp"
+	"gopkg.in/yaml.v2"
+)
+
+// scenario describes a timetabling or scheduling problem: each variable,
+// such as an exam or a shift, takes one of the values, such as a time
+// slot or a member of staff. Values are listed in order, which before
+// and apart use.
+type scenario struct {
+	Name        string       `json:"name" yaml:"name"`
+	Values      []string     `json:"values" yaml:"values"`
+	Variables   []string     `json:"variables" yaml:"variables"`
+	Constraints []constraint `json:"constraints" yaml:"constraints"`
+}
+
+// constraint is a hard constraint when Weight is 0. Otherwise it is soft,
+// and each variable, pair or group of variables violating it costs Weight.
+//
+// Types:
+//
+//	different  vars take different values
+//	same       vars take the same value
+//	before     vars take values in increasing order
+//	apart      vars take values at least min apart in order (min 0 means 1)
+//	in         each of vars takes one of values
+//	notin      none of vars takes any of values
+//	atmost     no more than count of vars take any one value, or any one of values if given
+type constraint struct {
+	Type   string   `json:"type" yaml:"type"`
+	Name   string   `json:"name" yaml:"name"` // used in explanations in place of a description made from Type
+	Vars   []string `json:"vars" yaml:"vars"`
+	Values []string `json:"values" yaml:"values"`
+	Min    int      `json:"min" yaml:"min"`
+	Count  int      `json:"count" yaml:"count"`
+	Weight int      `json:"weight" yaml:"weight"`
+}
+
+// readScenario reads a scenario from a .json file, or otherwise from YAML.
+func readScenario(name string) (*scenario, error) {
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	sc := &scenario{}
+	if strings.ToLower(filepath.Ext(name)) == ".json" {
+		d := json.NewDecoder(bytes.NewReader(b))
+		d.DisallowUnknownFields()
+		err = d.Decode(sc)
+	} else {
+		err = yaml.UnmarshalStrict(b, sc)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return sc, nil
+}
+
+// problem returns the csp.Problem of sc. Variables are added in the
+// order listed, and values are numbered from 0 in the order listed.
+func (sc *scenario) problem() (*csp.Problem, error) {
+	if len(sc.Values) == 0 || len(sc.Variables) == 0 {
+		return nil, fmt.Errorf("scenario needs values and variables")
+	}
+	p := csp.NewProblem()
+	dom := []int{}
+	for i := range sc.Values {
+		dom = append(dom, i)
+	}
+	vars := map[string]csp.Var{}
+	for _, n := range sc.Variables {
+		if _, ok := vars[n]; ok {
+			return nil, fmt.Errorf("variable %q listed twice", n)
+		}
+		vars[n] = p.AddVar(n, dom...)
+	}
+	for i, c := range sc.Constraints {
+		if err := sc.add(p, vars, c); err != nil {
+			return nil, fmt.Errorf("constraint %d (%s): %v", i+1, c.Type, err)
+		}
+	}
+	return p, nil
+}
+
+// add adds c to p, split into unary or binary constraints where it can
+// be, so that hard ones propagate and soft ones are explained precisely.
+func (sc *scenario) add(p *csp.Problem, vars map[string]csp.Var, c constraint) error {
+	if c.Weight < 0 {
+		return fmt.Errorf("negative weight %d", c.Weight)
+	}
+	xx := []csp.Var{}
+	for _, n := range c.Vars {
+		x, ok := vars[n]
+		if !ok {
+			return fmt.Errorf("unknown variable %q", n)
+		}
+		xx = append(xx, x)
+	}
+	if len(xx) == 0 {
+		return fmt.Errorf("no vars")
+	}
+	values := map[int]bool{}
+	for _, n := range c.Values {
+		v := indexOf(sc.Values, n)
+		if v < 0 {
+			return fmt.Errorf("unknown value %q", n)
+		}
+		values[v] = true
+	}
+	name := func(desc string) string {
+		if c.Name != "" {
+			return c.Name + ": " + desc
+		}
+		return desc
+	}
+
+	switch c.Type {
+	case "different", "same", "apart":
+		ok := map[string]func(a, b int) bool{
+			"different": func(a, b int) bool { return a != b },
+			"same":      func(a, b int) bool { return a == b },
+			"apart":     func(a, b int) bool { return abs(a-b) >= max(c.Min, 1) },
+		}[c.Type]
+		for i, x := range xx {
+			for _, y := range xx[i+1:] {
+				desc := fmt.Sprintf("%s and %s %s", p.Name(x), p.Name(y), c.Type)
+				if c.Type == "apart" {
+					desc += fmt.Sprintf(" by %d", max(c.Min, 1))
+				}
+				binary(p, name(desc), x, y, ok, c.Weight)
+			}
+		}
+	case "before":
+		for i := 1; i < len(xx); i++ {
+			desc := fmt.Sprintf("%s before %s", p.Name(xx[i-1]), p.Name(xx[i]))
+			binary(p, name(desc), xx[i-1], xx[i], func(a, b int) bool { return a < b }, c.Weight)
+		}
+	case "in", "notin":
+		if len(values) == 0 {
+			return fmt.Errorf("no values")
+		}
+		want := c.Type == "in"
+		ok := func(a int) bool { return values[a] == want }
+		for _, x := range xx {
+			if c.Weight == 0 {
+				p.Unary(x, ok)
+				continue
+			}
+			desc := fmt.Sprintf("%s %s %s", p.Name(x), strings.Replace(c.Type, "not", "not ", 1), strings.Join(c.Values, ", "))
+			p.AddSoft(&csp.Constraint{Name: name(desc), Vars: []csp.Var{x},
+				Check: func(vv []int) bool { return ok(vv[0]) }}, c.Weight)
+		}
+	case "atmost":
+		if c.Count <= 0 {
+			return fmt.Errorf("count must be positive")
+		}
+		desc := fmt.Sprintf("at most %d of %s on any one value", c.Count, strings.Join(c.Vars, ", "))
+		if len(values) > 0 {
+			desc = fmt.Sprintf("at most %d of %s on %s", c.Count, strings.Join(c.Vars, ", "), strings.Join(c.Values, ", ")+" each")
+		}
+		check := func(vv []int) bool {
+			n := map[int]int{}
+			for _, v := range vv {
+				if len(values) > 0 && !values[v] {
+					continue
+				}
+				if n[v]++; n[v] > c.Count {
+					return false
+				}
+			}
+			return true
+		}
+		if c.Weight == 0 {
+			p.Nary(xx, check)
+		} else {
+			p.AddSoft(&csp.Constraint{Name: name(desc), Vars: xx, Check: check}, c.Weight)
+		}
+	default:
+		return fmt.Errorf("unknown type")
+	}
+	return nil
+}
+
+// binary adds a hard binary constraint if weight is 0, else a soft one.
+func binary(p *csp.Problem, name string, x, y csp.Var, ok func(a, b int) bool, weight int) {
+	if weight == 0 {
+		p.Binary(x, y, ok)
+		return
+	}
+	p.AddSoft(&csp.Constraint{Name: name, Vars: []csp.Var{x, y},
+		Check: func(vv []int) bool { return ok(vv[0], vv[1]) }}, weight)
+}
+
+func indexOf(ss []string, s string) int {
+	for i, t := range ss {
+		if t == s {
+			return i
+		}
+	}
+	return -1
+}
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
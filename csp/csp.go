@@ -0,0 +1,295 @@
+// Package csp -- given variables, each with a finite domain of values,
+// and constraints on the values they may take together.
+// Find assignments of a value to every variable that satisfy all the constraints.
+package csp
+
+// Var identifies a variable of a Problem. Variables are numbered from 0
+// in the order they are added.
+type Var int
+
+// Constraint restricts the values its variables may take together.
+type Constraint struct {
+	// Name describes the constraint, e.g. in explanations of a solution.
+	Name string
+	// Vars are the variables constrained.
+	Vars []Var
+	// Check reports whether values, given in the order of Vars, are allowed.
+	Check func(values []int) bool
+
+	binary func(a, b int) bool // set for binary constraints, for fast checks
+}
+
+// Soft is a constraint that an assignment may violate at a cost of Weight.
+type Soft struct {
+	*Constraint
+	Weight int
+}
+
+// Problem is a constraint satisfaction problem. Its Constraints must
+// hold; its Soft constraints should, and are weighed by an Optimizer.
+type Problem struct {
+	Names       []string
+	Domains     [][]int
+	Constraints []*Constraint
+	Soft        []Soft
+
+	byVar [][]*Constraint // constraints on each variable
+}
+
+// NewProblem returns a problem with no variables.
+func NewProblem() *Problem {
+	return &Problem{}
+}
+
+// AddVar adds a variable with a name and domain.
+func (p *Problem) AddVar(name string, domain ...int) Var {
+	p.Names = append(p.Names, name)
+	p.Domains = append(p.Domains, append([]int{}, domain...))
+	p.byVar = append(p.byVar, nil)
+	return Var(len(p.Names) - 1)
+}
+
+// Name returns the name of variable x.
+func (p *Problem) Name(x Var) string {
+	return p.Names[x]
+}
+
+// Add adds constraint c.
+func (p *Problem) Add(c *Constraint) {
+	p.Constraints = append(p.Constraints, c)
+	for i, x := range c.Vars {
+		if !containsVar(c.Vars[:i], x) {
+			p.byVar[x] = append(p.byVar[x], c)
+		}
+	}
+}
+
+// AddSoft adds c as a soft constraint that costs weight, which should be
+// positive, when violated.
+func (p *Problem) AddSoft(c *Constraint, weight int) {
+	p.Soft = append(p.Soft, Soft{c, weight})
+}
+
+func containsVar(xx []Var, x Var) bool {
+	for _, y := range xx {
+		if y == x {
+			return true
+		}
+	}
+	return false
+}
+
+// Unary allows x only the values a for which ok(a) is true.
+func (p *Problem) Unary(x Var, ok func(a int) bool) {
+	p.Add(&Constraint{Vars: []Var{x}, Check: func(vv []int) bool { return ok(vv[0]) }})
+}
+
+// Binary allows x and y only the pairs of values a and b for which
+// ok(a, b) is true.
+func (p *Problem) Binary(x, y Var, ok func(a, b int) bool) {
+	p.Add(&Constraint{
+		Vars:   []Var{x, y},
+		Check:  func(vv []int) bool { return ok(vv[0], vv[1]) },
+		binary: ok,
+	})
+}
+
+// Nary allows vars only the combinations of values for which ok is true.
+func (p *Problem) Nary(vars []Var, ok func(values []int) bool) {
+	p.Add(&Constraint{Vars: append([]Var{}, vars...), Check: ok})
+}
+
+// AllDifferent requires vars to take different values. It is added as a
+// binary constraint between every pair, which propagates well.
+func (p *Problem) AllDifferent(vars ...Var) {
+	for i, x := range vars {
+		for _, y := range vars[i+1:] {
+			p.Binary(x, y, notEqual)
+		}
+	}
+}
+
+func notEqual(a, b int) bool { return a != b }
+
+// Equal requires x and y to take the same value.
+func (p *Problem) Equal(x, y Var) {
+	p.Binary(x, y, func(a, b int) bool { return a == b })
+}
+
+// Satisfied reports whether c holds for a complete assignment,
+// indexed by Var.
+func (c *Constraint) Satisfied(assign []int) bool {
+	if c.binary != nil {
+		return c.binary(assign[c.Vars[0]], assign[c.Vars[1]])
+	}
+	vv := make([]int, len(c.Vars))
+	for i, x := range c.Vars {
+		vv[i] = assign[x]
+	}
+	return c.Check(vv)
+}
+
+// Consistent reports whether a complete assignment satisfies every constraint.
+func (p *Problem) Consistent(assign []int) bool {
+	for _, c := range p.Constraints {
+		if !c.Satisfied(assign) {
+			return false
+		}
+	}
+	return true
+}
+
+// Neighbours returns the variables that share a constraint with x.
+func (p *Problem) Neighbours(x Var) []Var {
+	nn := []Var{}
+	for _, c := range p.byVar[x] {
+		for _, y := range c.Vars {
+			if y != x && !containsVar(nn, y) {
+				nn = append(nn, y)
+			}
+		}
+	}
+	return nn
+}
+
+// AC3 returns the domains of p made arc consistent: every value left in
+// the domain of a variable has a supporting value in the domain of every
+// other variable it shares a binary constraint with. Unary constraints
+// are applied first. It returns false if some domain becomes empty, in
+// which case p has no solution.
+//
+// Pseudocode from Russell and Norvig, AIMA 3rd ed. figure 6.3:
+//
+//	function AC-3(csp) returns false if an inconsistency is found and true otherwise
+//	    queue := a queue of arcs, initially all the arcs in csp
+//	    while queue is not empty do
+//	        (Xi, Xj) := REMOVE-FIRST(queue)
+//	        if REVISE(csp, Xi, Xj) then
+//	            if size of Di = 0 then return false
+//	            for each Xk in Xi.NEIGHBORS - {Xj} do
+//	                add (Xk, Xi) to queue
+//	    return true
+func AC3(p *Problem) ([][]int, bool) {
+	doms, ok := p.nodeConsistent()
+	if !ok {
+		return doms, false
+	}
+	return doms, p.ac3(doms, nil, p.allArcs())
+}
+
+// nodeConsistent returns copies of the domains with the unary constraints applied.
+func (p *Problem) nodeConsistent() ([][]int, bool) {
+	doms := make([][]int, len(p.Domains))
+	for x, d := range p.Domains {
+		doms[x] = append([]int{}, d...)
+	}
+	for _, c := range p.Constraints {
+		if len(c.Vars) != 1 {
+			continue
+		}
+		x := c.Vars[0]
+		kept := doms[x][:0]
+		for _, a := range doms[x] {
+			if c.Check([]int{a}) {
+				kept = append(kept, a)
+			}
+		}
+		doms[x] = kept
+		if len(kept) == 0 {
+			return doms, false
+		}
+	}
+	return doms, true
+}
+
+// arc is the directed arc from x to y of a binary constraint: revising
+// it removes the values of x with no support in y.
+type arc struct {
+	x, y Var
+	c    *Constraint
+}
+
+func (p *Problem) allArcs() []arc {
+	aa := []arc{}
+	for _, c := range p.Constraints {
+		if c.binary != nil && c.Vars[0] != c.Vars[1] {
+			aa = append(aa, arc{c.Vars[0], c.Vars[1], c}, arc{c.Vars[1], c.Vars[0], c})
+		}
+	}
+	return aa
+}
+
+// arcsInto returns the arcs from the unassigned neighbours of y into y.
+func (p *Problem) arcsInto(y Var, assigned []bool, except Var) []arc {
+	aa := []arc{}
+	for _, c := range p.byVar[y] {
+		if c.binary == nil {
+			continue
+		}
+		x := c.Vars[0]
+		if x == y {
+			x = c.Vars[1]
+		}
+		if x != y && x != except && (assigned == nil || !assigned[x]) {
+			aa = append(aa, arc{x, y, c})
+		}
+	}
+	return aa
+}
+
+// ac3 makes doms arc consistent, starting from queue. Domains are
+// replaced, never modified in place, so callers may share them.
+// Variables marked in assigned are not revised.
+func (p *Problem) ac3(doms [][]int, assigned []bool, queue []arc) bool {
+	for len(queue) > 0 {
+		a := queue[0]
+		queue = queue[1:]
+		if !revise(doms, a) {
+			continue
+		}
+		if len(doms[a.x]) == 0 {
+			return false
+		}
+		queue = append(queue, p.arcsInto(a.x, assigned, a.y)...)
+	}
+	return true
+}
+
+// revise removes the values of a.x that no value of a.y supports.
+// It reports whether any were removed.
+func revise(doms [][]int, a arc) bool {
+	var kept []int
+	for i, v := range doms[a.x] {
+		if supported(doms, a, v) {
+			if kept != nil {
+				kept = append(kept, v)
+			}
+			continue
+		}
+		if kept == nil {
+			kept = append(make([]int, 0, len(doms[a.x])), doms[a.x][:i]...)
+		}
+	}
+	if kept == nil {
+		return false
+	}
+	doms[a.x] = kept
+	return true
+}
+
+func supported(doms [][]int, a arc, v int) bool {
+	for _, w := range doms[a.y] {
+		if a.c.holds(a.x, v, a.y, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// holds reports whether binary constraint c allows x=a and y=b.
+func (c *Constraint) holds(x Var, a int, y Var, b int) bool {
+	if c.Vars[0] == x {
+		return c.binary(a, b)
+	}
+	return c.binary(b, a)
+}
@@ -0,0 +1,88 @@
+package csp
+
+import (
+	"fmt"
+	"testing"
+)
+
+// australia is the map colouring problem of AIMA figure 6.1.
+func australia() (*Problem, map[string]Var) {
+	p := NewProblem()
+	vv := map[string]Var{}
+	for _, n := range []string{"WA", "NT", "Q", "NSW", "V", "SA", "T"} {
+		vv[n] = p.AddVar(n, 0, 1, 2)
+	}
+	for _, b := range [][2]string{
+		{"SA", "WA"}, {"SA", "NT"}, {"SA", "Q"}, {"SA", "NSW"}, {"SA", "V"},
+		{"WA", "NT"}, {"NT", "Q"}, {"Q", "NSW"}, {"NSW", "V"},
+	} {
+		p.AllDifferent(vv[b[0]], vv[b[1]])
+	}
+	return p, vv
+}
+
+func ExampleAC3() {
+	p, vv := australia()
+	p.Unary(vv["SA"], func(a int) bool { return a == 0 })
+	p.Unary(vv["NT"], func(a int) bool { return a == 1 })
+	doms, ok := AC3(p)
+	fmt.Println(ok)
+	for x, d := range doms {
+		fmt.Println(p.Name(Var(x)), d)
+	}
+	// Output:
+	// true
+	// WA [2]
+	// NT [1]
+	// Q [2]
+	// NSW [1]
+	// V [2]
+	// SA [0]
+	// T [0 1 2]
+}
+
+func TestAC3IsIncomplete(t *testing.T) {
+	// every arc is consistent, though three variables need three values.
+	p := NewProblem()
+	x := p.AddVar("x", 0, 1)
+	y := p.AddVar("y", 0, 1)
+	z := p.AddVar("z", 0, 1)
+	p.AllDifferent(x, y, z)
+	if doms, ok := AC3(p); !ok || len(doms[x]) != 2 {
+		t.Errorf("arc consistency removed values it should not: %v", doms)
+	}
+	if _, err := (&Solver{}).Solve(p); err == nil {
+		t.Error("search found a solution")
+	}
+}
+
+func TestAC3EmptiesDomain(t *testing.T) {
+	p := NewProblem()
+	x := p.AddVar("x", 1, 2, 3)
+	y := p.AddVar("y", 1, 2, 3)
+	p.Binary(x, y, func(a, b int) bool { return a > b })
+	p.Unary(y, func(b int) bool { return b >= 3 })
+	if _, ok := AC3(p); ok {
+		t.Error("inconsistency not found")
+	}
+}
+
+func TestNeighbours(t *testing.T) {
+	p, vv := australia()
+	if n := p.Neighbours(vv["SA"]); len(n) != 5 {
+		t.Errorf("SA has %d neighbours", len(n))
+	}
+	if n := p.Neighbours(vv["T"]); len(n) != 0 {
+		t.Errorf("T has %d neighbours", len(n))
+	}
+}
+
+func TestConsistent(t *testing.T) {
+	p, _ := australia()
+	if !p.Consistent([]int{0, 1, 0, 1, 0, 2, 0}) {
+		t.Error("solution rejected")
+	}
+	if p.Consistent([]int{0, 0, 1, 0, 1, 2, 0}) {
+		t.Error("WA and NT share a colour")
+	}
+}
@@ -0,0 +1,217 @@
+package csp
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// MinConflicts solves a Problem by local search, repairing a complete
+// assignment one variable at a time. It scales to problems far beyond
+// backtracking, but cannot prove that a problem has no solution.
+type MinConflicts struct {
+	MaxSteps int   // steps before restarting; 0 means 100000
+	Restarts int   // restarts after the first attempt
+	Tabu     int   // steps a changed variable may not change again, unless every conflicted variable is tabu
+	Seed     int64 // runs with the same seed give the same result
+
+	// Trace, if not nil, is called after every step with the number of
+	// steps taken so far, across restarts, and the number of constraints
+	// the current assignment violates.
+	Trace func(step, conflicts int)
+
+	// Steps is the number of steps taken by the last Solve and
+	// Conflicts the number of constraints its result violates.
+	Steps, Conflicts int
+}
+
+// mcState is the current assignment with, for quick repair, the
+// constraints it violates and the variables in them.
+type mcState struct {
+	p          *Problem
+	cons       [][]int // indexes into p.Constraints for each variable
+	assign     []int
+	violated   []bool // for each constraint
+	nViolated  int
+	varConf    []int // violated constraints on each variable
+	conflicted []Var // variables with varConf > 0
+	pos        []int // index of each variable in conflicted, or -1
+}
+
+// Solve returns a solution, indexed by Var, or if none is found the
+// assignment with fewest violated constraints and an error.
+//
+// Pseudocode from Russell and Norvig, AIMA 3rd ed. figure 6.8:
+//
+//	function MIN-CONFLICTS(csp, max_steps) returns a solution or failure
+//	    current := an initial complete assignment for csp
+//	    for i = 1 to max_steps do
+//	        if current is a solution for csp then return current
+//	        var := a randomly chosen conflicted variable from csp.VARIABLES
+//	        value := the value v for var that minimizes CONFLICTS(var, v, current, csp)
+//	        set var = value in current
+//	    return failure
+//
+// Ties between values are broken at random.
+func (m *MinConflicts) Solve(p *Problem) ([]int, error) {
+	m.Steps, m.Conflicts = 0, 0
+	doms, ok := p.nodeConsistent()
+	if !ok {
+		return nil, fmt.Errorf("csp has no solution")
+	}
+	maxSteps := m.MaxSteps
+	if maxSteps <= 0 {
+		maxSteps = 100000
+	}
+	r := rand.New(rand.NewSource(m.Seed))
+	cons := make([][]int, len(p.Names))
+	for i, c := range p.Constraints {
+		for j, x := range c.Vars {
+			if !containsVar(c.Vars[:j], x) {
+				cons[x] = append(cons[x], i)
+			}
+		}
+	}
+
+	var best []int
+	bestConf := -1
+	for attempt := 0; attempt <= m.Restarts; attempt++ {
+		st := newMCState(p, cons, doms, r)
+		changed := make([]int, len(p.Names)) // step after which each variable may change again
+		for step := 0; step < maxSteps && st.nViolated > 0; step++ {
+			x := st.pick(r, changed, m.Steps)
+			st.set(x, st.bestValue(x, doms[x], r))
+			changed[x] = m.Steps + 1 + m.Tabu
+			m.Steps++
+			if m.Trace != nil {
+				m.Trace(m.Steps, st.nViolated)
+			}
+		}
+		if bestConf < 0 || st.nViolated < bestConf {
+			best, bestConf = append([]int{}, st.assign...), st.nViolated
+		}
+		if bestConf == 0 {
+			break
+		}
+	}
+	m.Conflicts = bestConf
+	if bestConf > 0 {
+		return best, fmt.Errorf("min-conflicts failed to find a solution: %d constraints violated", bestConf)
+	}
+	return best, nil
+}
+
+func newMCState(p *Problem, cons [][]int, doms [][]int, r *rand.Rand) *mcState {
+	n := len(p.Names)
+	st := &mcState{
+		p:        p,
+		cons:     cons,
+		assign:   make([]int, n),
+		violated: make([]bool, len(p.Constraints)),
+		varConf:  make([]int, n),
+		pos:      make([]int, n),
+	}
+	for x := range st.assign {
+		st.assign[x] = doms[x][r.Intn(len(doms[x]))]
+		st.pos[x] = -1
+	}
+	for i, c := range p.Constraints {
+		if !c.Satisfied(st.assign) {
+			st.mark(i, true)
+		}
+	}
+	return st
+}
+
+// mark records whether constraint i is violated.
+func (st *mcState) mark(i int, violated bool) {
+	if st.violated[i] == violated {
+		return
+	}
+	st.violated[i] = violated
+	d := 1
+	if !violated {
+		d = -1
+	}
+	st.nViolated += d
+	c := st.p.Constraints[i]
+	for j, x := range c.Vars {
+		if containsVar(c.Vars[:j], x) {
+			continue
+		}
+		st.varConf[x] += d
+		switch {
+		case st.varConf[x] > 0 && st.pos[x] < 0:
+			st.pos[x] = len(st.conflicted)
+			st.conflicted = append(st.conflicted, x)
+		case st.varConf[x] == 0 && st.pos[x] >= 0:
+			last := st.conflicted[len(st.conflicted)-1]
+			st.conflicted[st.pos[x]] = last
+			st.pos[last] = st.pos[x]
+			st.conflicted = st.conflicted[:len(st.conflicted)-1]
+			st.pos[x] = -1
+		}
+	}
+}
+
+// pick returns a random conflicted variable that is not tabu at step,
+// or any conflicted variable if all are.
+func (st *mcState) pick(r *rand.Rand, changed []int, step int) Var {
+	x, n := Var(-1), 0
+	for _, y := range st.conflicted {
+		if changed[y] > step {
+			continue
+		}
+		n++
+		if r.Intn(n) == 0 {
+			x = y
+		}
+	}
+	if x < 0 {
+		x = st.conflicted[r.Intn(len(st.conflicted))]
+	}
+	return x
+}
+
+// conflicts counts the constraints on x violated if x took value v.
+func (st *mcState) conflicts(x Var, v int) int {
+	old := st.assign[x]
+	st.assign[x] = v
+	n := 0
+	for _, i := range st.cons[x] {
+		if !st.p.Constraints[i].Satisfied(st.assign) {
+			n++
+		}
+	}
+	st.assign[x] = old
+	return n
+}
+
+// bestValue returns a value of x with fewest conflicts, chosen at
+// random among equals.
+func (st *mcState) bestValue(x Var, dom []int, r *rand.Rand) int {
+	best, bestN, ties := st.assign[x], -1, 0
+	for _, v := range dom {
+		n := st.conflicts(x, v)
+		switch {
+		case bestN < 0 || n < bestN:
+			best, bestN, ties = v, n, 1
+		case n == bestN:
+			ties++
+			if r.Intn(ties) == 0 {
+				best = v
+			}
+		}
+	}
+	return best
+}
+
+// set assigns v to x and updates the violated constraints.
+func (st *mcState) set(x Var, v int) {
+	if st.assign[x] == v {
+		return
+	}
+	st.assign[x] = v
+	for _, i := range st.cons[x] {
+		st.mark(i, !st.p.Constraints[i].Satisfied(st.assign))
+	}
+}
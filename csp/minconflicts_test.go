@@ -0,0 +1,99 @@
+package csp
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func ExampleMinConflicts() {
+	p := queens(100)
+	m := &MinConflicts{Seed: 1}
+	sol, err := m.Solve(p)
+	fmt.Println(err, p.Consistent(sol), m.Conflicts)
+	// Output:
+	// <nil> true 0
+}
+
+// planted returns a graph colouring problem with n vertices, each edge
+// joining vertices of different colours in a hidden colouring, so that
+// a solution exists.
+func planted(n, edges, colours int, seed int64) *Problem {
+	r := rand.New(rand.NewSource(seed))
+	hidden := make([]int, n)
+	p := NewProblem()
+	for i := range hidden {
+		hidden[i] = r.Intn(colours)
+		cc := []int{}
+		for c := 0; c < colours; c++ {
+			cc = append(cc, c)
+		}
+		p.AddVar(fmt.Sprint("v", i), cc...)
+	}
+	for e := 0; e < edges; {
+		a, b := r.Intn(n), r.Intn(n)
+		if hidden[a] == hidden[b] {
+			continue
+		}
+		p.AllDifferent(Var(a), Var(b))
+		e++
+	}
+	return p
+}
+
+func TestMinConflictsThousandsOfVariables(t *testing.T) {
+	p := planted(3000, 12000, 4, 1)
+	m := &MinConflicts{Tabu: 5, Seed: 1}
+	sol, err := m.Solve(p)
+	if err != nil || !p.Consistent(sol) {
+		t.Errorf("not solved after %d steps: %v", m.Steps, err)
+	}
+}
+
+func TestMinConflictsTrace(t *testing.T) {
+	trace := []int{}
+	m := &MinConflicts{Seed: 2, Trace: func(step, conflicts int) {
+		if step != len(trace)+1 {
+			t.Fatalf("step %d reported after %d steps", step, len(trace))
+		}
+		trace = append(trace, conflicts)
+	}}
+	if _, err := m.Solve(queens(50)); err != nil {
+		t.Fatal(err)
+	}
+	if len(trace) != m.Steps || trace[len(trace)-1] != 0 {
+		t.Errorf("trace of %d steps ends with %v, took %d steps", len(trace), trace[len(trace)-1], m.Steps)
+	}
+}
+
+func TestMinConflictsRestartsAndFails(t *testing.T) {
+	// three mutually different variables with two values cannot be solved.
+	p := NewProblem()
+	x, y, z := p.AddVar("x", 0, 1), p.AddVar("y", 0, 1), p.AddVar("z", 0, 1)
+	p.AllDifferent(x, y, z)
+	m := &MinConflicts{MaxSteps: 50, Restarts: 3, Tabu: 1}
+	sol, err := m.Solve(p)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if m.Steps != 200 || m.Conflicts != 1 || len(sol) != 3 {
+		t.Errorf("%d steps, %d conflicts in best assignment %v", m.Steps, m.Conflicts, sol)
+	}
+}
+
+func TestMinConflictsNary(t *testing.T) {
+	// ten digits summing to 45 with no two alike, and the first three in order.
+	p := NewProblem()
+	vv := []Var{}
+	for i := 0; i < 10; i++ {
+		vv = append(vv, p.AddVar(fmt.Sprint("d", i), 0, 1, 2, 3, 4, 5, 6, 7, 8, 9))
+	}
+	p.AllDifferent(vv...)
+	p.Nary(vv[:3], func(v []int) bool { return v[0] < v[1] && v[1] < v[2] })
+	p.Unary(vv[9], func(a int) bool { return a == 0 })
+	m := &MinConflicts{Tabu: 2, Restarts: 5, Seed: 3}
+	sol, err := m.Solve(p)
+	if err != nil || !p.Consistent(sol) {
+		t.Errorf("not solved: %v %v", sol, err)
+	}
+}
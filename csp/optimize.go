@@ -0,0 +1,237 @@
+package csp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Penalty returns the total weight of the soft constraints that a
+// complete assignment, indexed by Var, violates.
+func (p *Problem) Penalty(assign []int) int {
+	n := 0
+	for _, c := range p.Violated(assign) {
+		n += c.Weight
+	}
+	return n
+}
+
+// Violated returns the soft constraints that a complete assignment
+// violates, heaviest first, to explain its penalty.
+func (p *Problem) Violated(assign []int) []Soft {
+	vv := []Soft{}
+	for _, c := range p.Soft {
+		if !c.Satisfied(assign) {
+			vv = append(vv, c)
+		}
+	}
+	sort.SliceStable(vv, func(i, j int) bool { return vv[i].Weight > vv[j].Weight })
+	return vv
+}
+
+// Optimizer finds the assignment of a Problem with the lowest penalty
+// among those satisfying every hard constraint, by depth-first branch
+// and bound. The zero value uses MRVDegree and MAC for the hard
+// constraints and searches until the best assignment is proved optimal.
+type Optimizer struct {
+	VarOrder  VarOrder
+	Inference Inference
+	MaxNodes  int // nodes before stopping with the best assignment so far; 0 means no limit
+
+	// Improved, if not nil, is called with each assignment found that
+	// has a lower penalty than those found before it.
+	Improved func(assign []int, penalty int)
+
+	// Nodes is the number of assignments tried by the last Optimize and
+	// Backtracks the number of them undone. Penalty is the penalty of
+	// its result, and Optimal whether the search finished, so that no
+	// assignment has a lower penalty.
+	Nodes, Backtracks int
+	Penalty           int
+	Optimal           bool
+
+	s       *Solver
+	soft    [][]int // indexes into p.Soft for each variable
+	best    []int
+	bound   int // penalty of best, which later assignments must beat
+	stopped bool
+}
+
+// Optimize returns the assignment, indexed by Var, with the lowest
+// penalty found. Values are tried cheapest first, so good assignments
+// are found early, and a branch is cut once a lower bound on the
+// penalty of any assignment below it reaches that of the best so far.
+// The bound adds to the penalty of the soft constraints already
+// decided, for each unassigned variable, the least that its soft
+// constraints whose other variables are assigned would add.
+//
+// Pseudocode of depth-first branch and bound:
+//
+//	function BRANCH-AND-BOUND(assignment, cost, csp) returns nothing
+//	    if LOWER-BOUND(assignment, cost, csp) >= best_cost then return
+//	    if assignment is complete then best, best_cost := assignment, cost; return
+//	    var := SELECT-UNASSIGNED-VARIABLE(csp)
+//	    for each value of var, in order of increasing added cost do
+//	        if value is consistent with assignment and INFERENCE succeeds then
+//	            BRANCH-AND-BOUND(assignment ∪ {var = value}, cost + added cost, csp)
+func (o *Optimizer) Optimize(p *Problem) ([]int, error) {
+	o.Nodes, o.Backtracks, o.Penalty, o.Optimal = 0, 0, 0, false
+	o.s = &Solver{VarOrder: o.VarOrder, Inference: o.Inference, p: p}
+	o.s.assign = make([]int, len(p.Names))
+	o.s.assigned = make([]bool, len(p.Names))
+	o.soft = make([][]int, len(p.Names))
+	for i, c := range p.Soft {
+		for j, x := range c.Vars {
+			if !containsVar(c.Vars[:j], x) {
+				o.soft[x] = append(o.soft[x], i)
+			}
+		}
+	}
+	o.best, o.bound, o.stopped = nil, math.MaxInt32, false
+
+	doms, ok := p.nodeConsistent()
+	if ok && o.Inference == MAC {
+		ok = p.ac3(doms, nil, p.allArcs())
+	}
+	if ok {
+		o.branch(doms, 0, 0)
+	}
+	o.s, o.soft = nil, nil
+	if o.best == nil {
+		if o.stopped {
+			return nil, fmt.Errorf("no assignment found in %d nodes", o.MaxNodes)
+		}
+		return nil, fmt.Errorf("csp has no solution")
+	}
+	o.Penalty, o.Optimal = o.bound, !o.stopped
+	return o.best, nil
+}
+
+// branch searches below the current assignment, whose soft constraints
+// decided so far cost cost. It returns false to stop the search.
+func (o *Optimizer) branch(doms [][]int, n, cost int) bool {
+	s := o.s
+	if n == len(s.assign) {
+		o.best, o.bound = append([]int{}, s.assign...), cost
+		if o.Improved != nil {
+			o.Improved(o.best, cost)
+		}
+		return cost > 0
+	}
+	doms, ok := o.prune(doms, cost)
+	if !ok {
+		return true
+	}
+	x := s.selectVar(doms)
+	s.assigned[x] = true
+	defer func() { s.assigned[x] = false }()
+	vv, dd := o.orderValues(x, doms[x])
+	for i, v := range vv {
+		if cost+dd[i] >= o.bound {
+			break
+		}
+		if !s.consistent(x, v) {
+			continue
+		}
+		if o.MaxNodes > 0 && o.Nodes >= o.MaxNodes {
+			o.stopped = true
+			return false
+		}
+		o.Nodes++
+		s.assign[x] = v
+		next := append([][]int{}, doms...)
+		next[x] = []int{v}
+		if s.infer(x, v, next) {
+			if !o.branch(next, n+1, cost+dd[i]) {
+				return false
+			}
+		}
+		o.Backtracks++
+	}
+	return true
+}
+
+// added returns the weight of the soft constraints on x, with every
+// other variable assigned, that x=v violates.
+func (o *Optimizer) added(x Var, v int) int {
+	s := o.s
+	was := s.assigned[x]
+	s.assigned[x], s.assign[x] = true, v
+	n := 0
+	for _, i := range o.soft[x] {
+		c := s.p.Soft[i]
+		if s.allAssigned(c.Constraint) && !c.Satisfied(s.assign) {
+			n += c.Weight
+		}
+	}
+	s.assigned[x] = was
+	return n
+}
+
+// orderValues returns the values of x in dom, cheapest first, with the
+// penalty each adds.
+func (o *Optimizer) orderValues(x Var, dom []int) ([]int, []int) {
+	vv := append([]int{}, dom...)
+	dd := make([]int, len(vv))
+	for i, v := range vv {
+		dd[i] = o.added(x, v)
+	}
+	sort.Stable(byAdded{vv, dd})
+	return vv, dd
+}
+
+type byAdded struct{ vv, dd []int }
+
+func (b byAdded) Len() int           { return len(b.vv) }
+func (b byAdded) Less(i, j int) bool { return b.dd[i] < b.dd[j] }
+func (b byAdded) Swap(i, j int) {
+	b.vv[i], b.vv[j] = b.vv[j], b.vv[i]
+	b.dd[i], b.dd[j] = b.dd[j], b.dd[i]
+}
+
+// prune returns false if the lower bound on the penalty below the
+// current assignment reaches the bound. Otherwise it returns doms
+// without the values that would take the lower bound there.
+func (o *Optimizer) prune(doms [][]int, cost int) ([][]int, bool) {
+	s := o.s
+	added := make([][]int, len(doms))
+	least := make([]int, len(doms))
+	lower := cost
+	for i, dom := range doms {
+		x := Var(i)
+		if s.assigned[x] || len(o.soft[x]) == 0 {
+			continue
+		}
+		added[i] = make([]int, len(dom))
+		for j, v := range dom {
+			added[i][j] = o.added(x, v)
+			if j == 0 || added[i][j] < least[i] {
+				least[i] = added[i][j]
+			}
+		}
+		lower += least[i]
+	}
+	if lower >= o.bound {
+		return doms, false
+	}
+	out, copied := doms, false
+	for i, dd := range added {
+		if dd == nil {
+			continue
+		}
+		kept := []int{}
+		for j, v := range doms[i] {
+			if lower-least[i]+dd[j] < o.bound {
+				kept = append(kept, v)
+			}
+		}
+		if len(kept) == len(doms[i]) {
+			continue
+		}
+		if !copied {
+			out, copied = append([][]int{}, doms...), true
+		}
+		out[i] = kept
+	}
+	return out, true
+}
@@ -0,0 +1,119 @@
+package csp
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func ExampleOptimizer() {
+	// four exams in three slots, with shared students keeping some apart.
+	p := NewProblem()
+	exams := []string{"maths", "physics", "chemistry", "biology"}
+	vv := []Var{}
+	for _, e := range exams {
+		vv = append(vv, p.AddVar(e, 0, 1, 2))
+	}
+	p.AllDifferent(vv[0], vv[1])
+	p.AllDifferent(vv[1], vv[2])
+	p.AllDifferent(vv[2], vv[3])
+	prefer := func(name string, x Var, slot, weight int) {
+		p.AddSoft(&Constraint{Name: name, Vars: []Var{x},
+			Check: func(v []int) bool { return v[0] == slot }}, weight)
+	}
+	prefer("maths first", vv[0], 0, 5)
+	prefer("physics first", vv[1], 0, 3)
+	prefer("biology last", vv[3], 2, 1)
+	p.AddSoft(&Constraint{Name: "maths and chemistry apart", Vars: []Var{vv[0], vv[2]},
+		Check: func(v []int) bool { return v[0] != v[1] }}, 2)
+
+	o := &Optimizer{}
+	sol, err := o.Optimize(p)
+	fmt.Println(err, sol, o.Penalty, o.Optimal)
+	for _, c := range p.Violated(sol) {
+		fmt.Println(c.Weight, c.Name)
+	}
+	// Output:
+	// <nil> [0 2 1 2] 3 true
+	// 3 physics first
+}
+
+// weighted returns a random problem with some hard and some soft
+// binary constraints.
+func weighted(n, values, hard, soft int, seed int64) *Problem {
+	r := rand.New(rand.NewSource(seed))
+	p := NewProblem()
+	dom := []int{}
+	for v := 0; v < values; v++ {
+		dom = append(dom, v)
+	}
+	for i := 0; i < n; i++ {
+		p.AddVar(fmt.Sprint("v", i), dom...)
+	}
+	for i := 0; i < hard; {
+		if a, b := Var(r.Intn(n)), Var(r.Intn(n)); a != b {
+			p.AllDifferent(a, b)
+			i++
+		}
+	}
+	for i := 0; i < soft; i++ {
+		a, b, d := Var(r.Intn(n)), Var(r.Intn(n)), r.Intn(values)
+		p.AddSoft(&Constraint{Vars: []Var{a, b},
+			Check: func(v []int) bool { return (v[0]+v[1])%values != d }}, 1+r.Intn(5))
+	}
+	return p
+}
+
+func TestOptimizerFindsLeastPenalty(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		p := weighted(7, 3, 5, 15, seed)
+		least := -1
+		(&Solver{}).Search(p, func(assign []int) bool {
+			if n := p.Penalty(assign); least < 0 || n < least {
+				least = n
+			}
+			return true
+		})
+		for _, inf := range []Inference{MAC, ForwardChecking, NoInference} {
+			o := &Optimizer{Inference: inf}
+			sol, err := o.Optimize(p)
+			if least < 0 {
+				if err == nil {
+					t.Errorf("seed %d: found %v for a problem with no solution", seed, sol)
+				}
+				continue
+			}
+			if err != nil || !p.Consistent(sol) || p.Penalty(sol) != least || o.Penalty != least || !o.Optimal {
+				t.Errorf("seed %d, inference %d: penalty %d, want %d: %v", seed, inf, o.Penalty, least, err)
+			}
+		}
+	}
+}
+
+func TestOptimizerImprovesAndStops(t *testing.T) {
+	p := weighted(30, 4, 30, 120, 1)
+	last := -1
+	o := &Optimizer{MaxNodes: 200, Improved: func(assign []int, penalty int) {
+		if last >= 0 && penalty >= last {
+			t.Errorf("penalty %d does not improve on %d", penalty, last)
+		}
+		if p.Penalty(assign) != penalty {
+			t.Errorf("reported penalty %d, actual %d", penalty, p.Penalty(assign))
+		}
+		last = penalty
+	}}
+	sol, err := o.Optimize(p)
+	if err != nil || o.Optimal || o.Nodes != 200 || o.Penalty != last || p.Penalty(sol) != last {
+		t.Errorf("after %d nodes, penalty %d, last reported %d, optimal %v: %v",
+			o.Nodes, o.Penalty, last, o.Optimal, err)
+	}
+}
+
+func TestOptimizerNoSolution(t *testing.T) {
+	p := NewProblem()
+	x, y, z := p.AddVar("x", 0, 1), p.AddVar("y", 0, 1), p.AddVar("z", 0, 1)
+	p.AllDifferent(x, y, z)
+	if _, err := (&Optimizer{}).Optimize(p); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/siuyin/ai/game"
+	"github.com/siuyin/ai/search"
+)
+
+var (
+	depth   = flag.Int("depth", 0, "deepest search in moves, 0 for no limit; lower is weaker")
+	think   = flag.Duration("time", 2*time.Second, "thinking time per move")
+	tableMB = flag.Int("table", 64, "transposition table size in MB")
+	second  = flag.Bool("second", false, "let the computer move first")
+	verbose = flag.Bool("v", false, "print search statistics")
+)
+
+func main() {
+	flag.Parse()
+	g := game.ConnectFour{}
+	ab := &game.AlphaBeta{MaxDepth: *depth, Eval: g.Eval, Table: game.NewTranspositionTable(*tableMB << 20)}
+	human := 0
+	if *second {
+		human = 1
+	}
+	fmt.Printf("You play %c. Enter a column number to drop a stone, q to quit.\n", "XO"[human])
+
+	in := bufio.NewScanner(os.Stdin)
+	s := g.Start()
+	for !g.Terminal(s) {
+		fmt.Printf("\n%v\n", s)
+		var a search.Action
+		if g.Player(s) == human {
+			var ok bool
+			if a, ok = readMove(in, g, s); !ok {
+				return
+			}
+		} else {
+			start := time.Now()
+			var v float64
+			var reached int
+			a, v, reached = ab.IterativeDeepening(g, s, *think)
+			if *verbose {
+				fmt.Printf("depth %d, value %.3f, %d nodes in %v\n", reached, v, ab.Nodes, time.Since(start).Round(time.Millisecond))
+			}
+			fmt.Printf("I play %s.\n", a.Name)
+		}
+		s = g.Result(s, a)
+	}
+
+	fmt.Printf("\n%v\n", s)
+	switch u := g.Utility(s, human); {
+	case u > 0:
+		fmt.Println("You win!")
+	case u < 0:
+		fmt.Println("I win.")
+	default:
+		fmt.Println("Draw.")
+	}
+}
+
+// readMove prompts until the player enters a legal column.
+// It returns false at end of input or if the player quits.
+func readMove(in *bufio.Scanner, g game.ConnectFour, s game.State) (search.Action, bool) {
+	for {
+		fmt.Print("Your move: ")
+		if !in.Scan() {
+			if err := in.Err(); err != nil {
+				log.Fatal(err)
+			}
+			return search.Action{}, false
+		}
+		t := strings.TrimSpace(in.Text())
+		if t == "q" {
+			return search.Action{}, false
+		}
+		c, err := strconv.Atoi(t)
+		if err == nil {
+			for _, a := range g.Actions(s) {
+				if a.ID == c-1 {
+					return a, true
+				}
+			}
+		}
+		fmt.Println("Please enter the number of a column that is not full.")
+	}
+}
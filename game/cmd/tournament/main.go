@@ -0,0 +1,125 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/siuyin/ai/game"
+)
+
+var (
+	gameName = flag.String("game", "tictactoe", "game to play: tictactoe or connect4")
+	rounds   = flag.Int("rounds", 10, "games per pairing and seat")
+	seed     = flag.Int64("seed", 1, "random seed")
+	maxMoves = flag.Int("maxmoves", 0, "draw games longer than this, if positive")
+	csvFile  = flag.String("csv", "", "write the standings as CSV to this file")
+	gamesCSV = flag.String("games", "", "write every game as CSV to this file")
+	verbose  = flag.Bool("v", false, "print every game")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), `usage: tournament [flags] agent agent...
+
+agents:
+  random       random moves
+  minimax      full minimax, only practical for tictactoe
+  ab:N         alpha-beta searching N moves deep
+  ab:DURATION  alpha-beta with iterative deepening, e.g. ab:100ms
+  mcts:N       Monte Carlo tree search with N simulations per move
+  mcts:DURATION
+  human        you, at the terminal
+
+flags:
+`)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() < 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	t := &game.Tournament{Rounds: *rounds, Seed: *seed, MaxMoves: *maxMoves}
+	var eval game.Evaluation
+	switch *gameName {
+	case "tictactoe":
+		g := game.TicTacToe{}
+		t.Game, t.Start, eval = g, g.Start(), g.Eval
+	case "connect4":
+		g := game.ConnectFour{}
+		t.Game, t.Start, eval = g, g.Start(), g.Eval
+	default:
+		log.Fatalf("unknown game %q", *gameName)
+	}
+	for _, spec := range flag.Args() {
+		a, err := agent(spec, eval)
+		if err != nil {
+			log.Fatal(err)
+		}
+		t.Entrants = append(t.Entrants, game.Entrant{Name: spec, Agent: a})
+	}
+	if *verbose {
+		t.Log = os.Stdout
+	}
+
+	res := t.Run()
+	fmt.Println()
+	if err := res.Print(os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+	if err := writeFile(*csvFile, res.WriteCSV); err != nil {
+		log.Fatal(err)
+	}
+	if err := writeFile(*gamesCSV, res.WriteGamesCSV); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// agent builds the agent described by spec.
+func agent(spec string, eval game.Evaluation) (game.Agent, error) {
+	kind, arg := spec, ""
+	if i := strings.Index(spec, ":"); i >= 0 {
+		kind, arg = spec[:i], spec[i+1:]
+	}
+	n, nErr := strconv.Atoi(arg)
+	d, dErr := time.ParseDuration(arg)
+	switch {
+	case kind == "random":
+		return &game.RandomAgent{}, nil
+	case kind == "minimax":
+		return game.MinimaxAgent{}, nil
+	case kind == "human":
+		return &game.HumanAgent{In: os.Stdin, Out: os.Stdout}, nil
+	case kind == "ab" && nErr == nil:
+		return &game.AlphaBetaAgent{AlphaBeta: &game.AlphaBeta{MaxDepth: n, Eval: eval, Table: game.NewTranspositionTable(16 << 20)}}, nil
+	case kind == "ab" && dErr == nil:
+		return &game.AlphaBetaAgent{AlphaBeta: &game.AlphaBeta{Eval: eval, Table: game.NewTranspositionTable(16 << 20)}, Budget: d}, nil
+	case kind == "mcts" && nErr == nil:
+		return &game.MCTSAgent{MCTS: &game.MCTS{Iterations: n}}, nil
+	case kind == "mcts" && dErr == nil:
+		return &game.MCTSAgent{MCTS: &game.MCTS{Budget: d}}, nil
+	}
+	return nil, fmt.Errorf("unknown agent %q", spec)
+}
+
+func writeFile(name string, write func(w io.Writer) error) error {
+	if name == "" {
+		return nil
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
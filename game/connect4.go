@@ -0,0 +1,205 @@
+package game
+
+import (
+	"math/bits"
+	"strings"
+
+	"github.com/siuyin/ai/search"
+)
+
+// ConnectFour is played on an upright board 7 columns wide and 6 rows high.
+// Players take turns dropping a stone into a column, where it falls to the
+// lowest empty row; the first to line up four stones in a row, column or
+// diagonal wins. Player 0 plays X and moves first. The action for a
+// column has its index, 0 to 6, as ID and its number, "1" to "7", as Name.
+type ConnectFour struct{}
+
+// ConnectFourBoard is a ConnectFour State held as two bitboards.
+// Bit c*7+r is set in Stones[p] if player p has a stone in column c,
+// row r, counting rows from the bottom. The seventh bit of each column
+// is always clear, which stops lines wrapping from one column to the next.
+type ConnectFourBoard struct {
+	Stones [2]uint64
+	Moves  int
+}
+
+const (
+	c4Width  = 7
+	c4Height = 6
+	c4Column = c4Height + 1 // bits per column
+)
+
+// c4Windows holds a mask for every line of four cells on the board.
+var c4Windows = func() []uint64 {
+	ww := []uint64{}
+	for c := 0; c < c4Width; c++ {
+		for r := 0; r < c4Height; r++ {
+			for _, d := range [][2]int{{1, 0}, {0, 1}, {1, 1}, {1, -1}} {
+				w := uint64(0)
+				for i := 0; i < 4; i++ {
+					cc, rr := c+i*d[0], r+i*d[1]
+					if cc >= c4Width || rr < 0 || rr >= c4Height {
+						w = 0
+						break
+					}
+					w |= 1 << uint(cc*c4Column+rr)
+				}
+				if w != 0 {
+					ww = append(ww, w)
+				}
+			}
+		}
+	}
+	return ww
+}()
+
+// Start returns the empty board.
+func (ConnectFour) Start() State {
+	return ConnectFourBoard{}
+}
+
+// Player returns 0 if X is to move and 1 if O is.
+func (ConnectFour) Player(s State) int {
+	return s.(ConnectFourBoard).Moves % 2
+}
+
+// ConnectFourAction returns the action that drops a stone in column c.
+func ConnectFourAction(c int) search.Action {
+	return search.Action{ID: c, Name: string([]byte{byte('1' + c)})}
+}
+
+// Actions returns the columns that are not full.
+func (ConnectFour) Actions(s State) []search.Action {
+	b := s.(ConnectFourBoard)
+	mask := b.Stones[0] | b.Stones[1]
+	aa := []search.Action{}
+	for c := 0; c < c4Width; c++ {
+		if mask&c4Top(c) == 0 {
+			aa = append(aa, ConnectFourAction(c))
+		}
+	}
+	return aa
+}
+
+func c4Top(c int) uint64 {
+	return 1 << uint(c*c4Column+c4Height-1)
+}
+
+func c4Bottom(c int) uint64 {
+	return 1 << uint(c*c4Column)
+}
+
+func c4ColumnMask(c int) uint64 {
+	return (1<<c4Height - 1) << uint(c*c4Column)
+}
+
+// Result drops a stone for the player to move in the column of action a.
+func (ConnectFour) Result(s State, a search.Action) State {
+	b := s.(ConnectFourBoard)
+	mask := b.Stones[0] | b.Stones[1]
+	b.Stones[b.Moves%2] |= (mask + c4Bottom(a.ID)) & c4ColumnMask(a.ID)
+	b.Moves++
+	return b
+}
+
+// c4Won reports whether the stones in bitboard b include four in a line.
+func c4Won(b uint64) bool {
+	for _, d := range []uint{1, c4Column - 1, c4Column, c4Column + 1} {
+		m := b & (b >> d)
+		if m&(m>>(2*d)) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Terminal reports whether a player has four in a line or the board is full.
+func (ConnectFour) Terminal(s State) bool {
+	b := s.(ConnectFourBoard)
+	return b.Moves == c4Width*c4Height || c4Won(b.Stones[0]) || c4Won(b.Stones[1])
+}
+
+// Utility is positive if player has won, negative if player has lost and
+// 0 for a draw. Quicker wins score more: a win is worth 1 less a hundredth
+// for every stone on the board, so the engine does not put off a win it
+// can already see.
+func (ConnectFour) Utility(s State, player int) float64 {
+	b := s.(ConnectFourBoard)
+	win := 1 - float64(b.Moves)/100
+	switch {
+	case c4Won(b.Stones[player]):
+		return win
+	case c4Won(b.Stones[1-player]):
+		return -win
+	}
+	return 0
+}
+
+// c4Zobrist has a key for each player's stone on each bit of the board.
+var c4Zobrist = NewZobrist(c4Width*c4Column, 2, 1)
+
+// Hash returns the Zobrist hash of the board.
+func (ConnectFour) Hash(s State) uint64 {
+	b := s.(ConnectFourBoard)
+	h := uint64(0)
+	for p, stones := range b.Stones {
+		for ; stones != 0; stones &= stones - 1 {
+			h ^= c4Zobrist.Key(bits.TrailingZeros64(stones), p)
+		}
+	}
+	return h
+}
+
+// OrderActions tries the centre column first and the edges last.
+func (ConnectFour) OrderActions(s State, aa []search.Action) []search.Action {
+	out := []search.Action{}
+	for _, c := range []int{3, 2, 4, 1, 5, 0, 6} {
+		for _, a := range aa {
+			if a.ID == c {
+				out = append(out, a)
+			}
+		}
+	}
+	return out
+}
+
+// Eval scores a board for player by the lines of four still open to
+// player minus those open to the opponent, each counted by the square of
+// the stones already in it. The result lies below the value of any win.
+func (ConnectFour) Eval(s State, player int) float64 {
+	b := s.(ConnectFourBoard)
+	me, them := b.Stones[player], b.Stones[1-player]
+	score := 0
+	for _, w := range c4Windows {
+		mine, theirs := bits.OnesCount64(me&w), bits.OnesCount64(them&w)
+		if theirs == 0 {
+			score += mine * mine
+		}
+		if mine == 0 {
+			score -= theirs * theirs
+		}
+	}
+	return float64(score) / 1100
+}
+
+// String draws the board, top row first, with '.' for empty cells
+// and the column numbers underneath.
+func (b ConnectFourBoard) String() string {
+	var sb strings.Builder
+	for r := c4Height - 1; r >= 0; r-- {
+		for c := 0; c < c4Width; c++ {
+			bit := uint64(1) << uint(c*c4Column+r)
+			switch {
+			case b.Stones[0]&bit != 0:
+				sb.WriteByte('X')
+			case b.Stones[1]&bit != 0:
+				sb.WriteByte('O')
+			default:
+				sb.WriteByte('.')
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	sb.WriteString("1234567")
+	return sb.String()
+}
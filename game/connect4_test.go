@@ -0,0 +1,175 @@
+package game
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// play drops stones in the given columns, numbered from 1, in turn.
+func play(cols string) State {
+	g := ConnectFour{}
+	s := g.Start()
+	for _, c := range cols {
+		s = g.Result(s, ConnectFourAction(int(c-'1')))
+	}
+	return s
+}
+
+func ExampleConnectFour() {
+	g := ConnectFour{}
+	s := play("445566")
+	ab := &AlphaBeta{MaxDepth: 4, Eval: g.Eval, Table: NewTranspositionTable(1 << 20)}
+	a, _ := ab.Search(g, s)
+	fmt.Println(g.Result(s, a))
+	// Output:
+	// .......
+	// .......
+	// .......
+	// .......
+	// ...OOO.
+	// ..XXXX.
+	// 1234567
+}
+
+// c4Board reads a board drawn as by ConnectFourBoard.String, without
+// the column numbers. Moves is set from the number of stones.
+func c4Board(rows string) ConnectFourBoard {
+	b := ConnectFourBoard{}
+	r, c := c4Height-1, 0
+	for _, ch := range rows {
+		switch ch {
+		case 'X', 'O', '.':
+			if ch != '.' {
+				p := 0
+				if ch == 'O' {
+					p = 1
+				}
+				b.Stones[p] |= 1 << uint(c*c4Column+r)
+				b.Moves++
+			}
+			c++
+			if c == c4Width {
+				r, c = r-1, 0
+			}
+		}
+	}
+	return b
+}
+
+func TestConnectFourLines(t *testing.T) {
+	g := ConnectFour{}
+	for _, tc := range []struct {
+		name   string
+		rows   string
+		winner int
+	}{
+		{"column", `
+			.......
+			.......
+			X......
+			XO.....
+			XO.....
+			XO.....`, 0},
+		{"row", `
+			.......
+			.......
+			.......
+			.......
+			.......
+			OOOXXXX`, 0},
+		{"rising diagonal", `
+			.......
+			.......
+			...X...
+			..XO...
+			.XOO...
+			XOOX...`, 0},
+		{"falling diagonal", `
+			.......
+			.......
+			O......
+			XO.....
+			XXOX...
+			XXXOO..`, 1},
+		{"no wrap between columns", `
+			.......
+			.......
+			.......
+			.......
+			X......
+			XX.....`, -1},
+		{"no wrap from top to bottom", `
+			X......
+			X......
+			O......
+			O......
+			.......
+			.......`, -1},
+	} {
+		s := c4Board(tc.rows)
+		got := -1
+		if g.Terminal(s) {
+			switch {
+			case g.Utility(s, 0) > 0:
+				got = 0
+			case g.Utility(s, 1) > 0:
+				got = 1
+			}
+		}
+		if got != tc.winner {
+			t.Errorf("%s: winner %d, want %d\n%v", tc.name, got, tc.winner, s)
+		}
+	}
+}
+
+func TestConnectFourFullColumn(t *testing.T) {
+	g := ConnectFour{}
+	aa := g.Actions(play("111111"))
+	if len(aa) != 6 || aa[0].Name != "2" {
+		t.Errorf("unexpected actions: %v", aa)
+	}
+}
+
+func TestConnectFourHash(t *testing.T) {
+	g := ConnectFour{}
+	if g.Hash(play("1234")) != g.Hash(play("3214")) {
+		t.Error("transposed positions hash differently")
+	}
+	seen := map[uint64]string{}
+	for _, cols := range []string{"", "1", "2", "11", "12", "21", "22", "112", "121", "211"} {
+		h := g.Hash(play(cols))
+		if prev, ok := seen[h]; ok {
+			t.Errorf("%q and %q hash alike", prev, cols)
+		}
+		seen[h] = cols
+	}
+}
+
+func TestConnectFourBlocks(t *testing.T) {
+	g := ConnectFour{}
+	// X threatens to complete the bottom row in column 4.
+	s := play("11223")
+	ab := &AlphaBeta{MaxDepth: 6, Eval: g.Eval, Table: NewTranspositionTable(1 << 20)}
+	if a, _ := ab.Search(g, s); a.Name != "4" {
+		t.Errorf("unexpected move %v\n%v", a.Name, s)
+	}
+}
+
+func TestConnectFourPrefersQuickWin(t *testing.T) {
+	g := ConnectFour{}
+	s := play("445566")
+	ab := &AlphaBeta{MaxDepth: 6, Eval: g.Eval}
+	a, v := ab.Search(g, s)
+	if v <= 0 || !g.Terminal(g.Result(s, a)) {
+		t.Errorf("did not win at once with %v, value %v", a.Name, v)
+	}
+}
+
+func BenchmarkConnectFour(b *testing.B) {
+	g := ConnectFour{}
+	for i := 0; i < b.N; i++ {
+		ab := &AlphaBeta{MaxDepth: 10, Eval: g.Eval, Table: NewTranspositionTable(16 << 20)}
+		ab.IterativeDeepening(g, g.Start(), time.Minute)
+	}
+}
@@ -0,0 +1,284 @@
+package game
+
+import (
+	"math"
+
+	"github.com/siuyin/ai/search"
+)
+
+// ChanceGame is a Game with chance nodes, such as dice rolls, where
+// nature rather than a player decides what happens next.
+// Result applies an outcome's Action to a chance node just as it
+// applies a player's move to any other node.
+type ChanceGame interface {
+	Game
+	// IsChance reports whether s is a chance node.
+	IsChance(s State) bool
+	// Outcomes returns the possible outcomes of chance node s.
+	// Their probabilities sum to 1.
+	Outcomes(s State) []Outcome
+}
+
+// Outcome is one result of a chance node and its probability.
+type Outcome struct {
+	Action      search.Action
+	Probability float64
+}
+
+// Expectimax searches two-player games with chance nodes,
+// taking the probability-weighted average at chance nodes.
+// The zero value searches to the end of the game.
+type Expectimax struct {
+	// MaxDepth, if positive, cuts the search off after that many moves,
+	// chance outcomes included, and scores the cutoff states with Eval.
+	MaxDepth int
+	Eval     Evaluation
+
+	// Nodes is the number of states visited by the last search.
+	Nodes int
+}
+
+// Search returns the best action for the player to move in s
+// and its expected value.
+func (e *Expectimax) Search(g ChanceGame, s State) (search.Action, float64) {
+	e.Nodes = 0
+	me := g.Player(s)
+	best, bestV := search.Action{}, math.Inf(-1)
+	for _, a := range g.Actions(s) {
+		if v := e.value(g, g.Result(s, a), me, e.MaxDepth-1); v > bestV {
+			best, bestV = a, v
+		}
+	}
+	return best, bestV
+}
+
+func (e *Expectimax) value(g ChanceGame, s State, me, depth int) float64 {
+	e.Nodes++
+	if g.Terminal(s) {
+		return g.Utility(s, me)
+	}
+	if e.MaxDepth > 0 && depth <= 0 {
+		if e.Eval == nil {
+			return 0
+		}
+		return e.Eval(s, me)
+	}
+	if g.IsChance(s) {
+		v := 0.0
+		for _, o := range g.Outcomes(s) {
+			v += o.Probability * e.value(g, g.Result(s, o.Action), me, depth-1)
+		}
+		return v
+	}
+	maximizing := g.Player(s) == me
+	v := math.Inf(1)
+	if maximizing {
+		v = math.Inf(-1)
+	}
+	for _, a := range g.Actions(s) {
+		c := e.value(g, g.Result(s, a), me, depth-1)
+		if maximizing {
+			v = math.Max(v, c)
+		} else {
+			v = math.Min(v, c)
+		}
+	}
+	return v
+}
+
+// StarMinimax is expectimax with Ballard's Star1 pruning: alpha-beta
+// windows are carried through chance nodes using known bounds on the
+// utility, so some outcomes need not be searched. It returns the same
+// values as Expectimax.
+type StarMinimax struct {
+	// Lower and Upper bound every Utility and Eval value.
+	Lower, Upper float64
+
+	MaxDepth int
+	Eval     Evaluation
+
+	// Nodes is the number of states visited by the last search.
+	Nodes int
+}
+
+// Search returns the best action for the player to move in s
+// and its expected value.
+func (st *StarMinimax) Search(g ChanceGame, s State) (search.Action, float64) {
+	st.Nodes = 0
+	me := g.Player(s)
+	alpha, beta := st.Lower, st.Upper
+	best, found := search.Action{}, false
+	for _, a := range g.Actions(s) {
+		v := st.value(g, g.Result(s, a), me, st.MaxDepth-1, alpha, beta)
+		if v > alpha || !found {
+			best, alpha, found = a, math.Max(alpha, v), true
+		}
+	}
+	return best, alpha
+}
+
+func (st *StarMinimax) value(g ChanceGame, s State, me, depth int, alpha, beta float64) float64 {
+	st.Nodes++
+	if g.Terminal(s) {
+		return g.Utility(s, me)
+	}
+	if st.MaxDepth > 0 && depth <= 0 {
+		if st.Eval == nil {
+			return 0
+		}
+		return st.Eval(s, me)
+	}
+	if g.IsChance(s) {
+		return st.chance(g, s, me, depth, alpha, beta)
+	}
+
+	if g.Player(s) == me {
+		v := math.Inf(-1)
+		for _, a := range g.Actions(s) {
+			v = math.Max(v, st.value(g, g.Result(s, a), me, depth-1, alpha, beta))
+			if v >= beta {
+				return v
+			}
+			alpha = math.Max(alpha, v)
+		}
+		return v
+	}
+	v := math.Inf(1)
+	for _, a := range g.Actions(s) {
+		v = math.Min(v, st.value(g, g.Result(s, a), me, depth-1, alpha, beta))
+		if v <= alpha {
+			return v
+		}
+		beta = math.Min(beta, v)
+	}
+	return v
+}
+
+// chance implements Star1. Outcomes not yet searched are assumed to be
+// worth Lower (for lo) or Upper (for hi); after each outcome, if even the
+// best case cannot exceed alpha, or the worst case already reaches beta,
+// the remaining outcomes are skipped, as are outcomes that cannot happen.
+func (st *StarMinimax) chance(g ChanceGame, s State, me, depth int, alpha, beta float64) float64 {
+	lo, hi := st.Lower, st.Upper
+	for _, o := range g.Outcomes(s) {
+		p := o.Probability
+		if p <= 0 {
+			continue
+		}
+		lo -= p * st.Lower
+		hi -= p * st.Upper
+		a := (alpha - hi) / p
+		b := (beta - lo) / p
+		v := st.value(g, g.Result(s, o.Action), me, depth-1, math.Max(a, st.Lower), math.Min(b, st.Upper))
+		if v <= a {
+			return alpha
+		}
+		if v >= b {
+			return beta
+		}
+		lo += p * v
+		hi += p * v
+	}
+	return lo
+}
+
+// DiceRace is a two-player race to land exactly on Target.
+// On their turn a player chooses to roll one or two dice with Sides
+// faces and moves forward by the total, bouncing back from Target by
+// any excess. The first to land on Target wins; the game is drawn
+// after MaxTurns turns.
+type DiceRace struct {
+	Sides, Target, MaxTurns int
+}
+
+// DiceRaceState is a DiceRace State.
+type DiceRaceState struct {
+	Pos    [2]int
+	ToMove int
+	Turns  int
+	Dice   int // dice chosen and waiting to be rolled; 0 at a decision node
+}
+
+// Player returns the player to move, or about to roll.
+func (DiceRace) Player(s State) int {
+	return s.(DiceRaceState).ToMove
+}
+
+// Actions returns the choice of rolling one or two dice.
+func (DiceRace) Actions(s State) []search.Action {
+	return []search.Action{{ID: 1, Name: "one"}, {ID: 2, Name: "two"}}
+}
+
+// IsChance reports whether dice are waiting to be rolled.
+func (DiceRace) IsChance(s State) bool {
+	return s.(DiceRaceState).Dice > 0
+}
+
+// Outcomes returns the distribution of the dice total.
+// The outcome action's ID is the total.
+func (d DiceRace) Outcomes(s State) []Outcome {
+	counts := map[int]int{0: 1}
+	for i := 0; i < s.(DiceRaceState).Dice; i++ {
+		next := map[int]int{}
+		for total, n := range counts {
+			for f := 1; f <= d.Sides; f++ {
+				next[total+f] += n
+			}
+		}
+		counts = next
+	}
+	ways := 0
+	for _, n := range counts {
+		ways += n
+	}
+	oo := []Outcome{}
+	for total := 1; total <= 2*d.Sides; total++ {
+		if n, ok := counts[total]; ok {
+			oo = append(oo, Outcome{
+				Action:      search.Action{ID: total, Name: "rolled"},
+				Probability: float64(n) / float64(ways),
+			})
+		}
+	}
+	return oo
+}
+
+// Result chooses the number of dice at a decision node
+// and moves the player at a chance node.
+func (d DiceRace) Result(s State, a search.Action) State {
+	r := s.(DiceRaceState)
+	if r.Dice == 0 {
+		r.Dice = a.ID
+		return r
+	}
+	p := r.Pos[r.ToMove] + a.ID
+	if p > d.Target {
+		p = 2*d.Target - p
+	}
+	if p < 0 {
+		p = 0
+	}
+	r.Pos[r.ToMove] = p
+	r.Dice = 0
+	r.Turns++
+	r.ToMove = 1 - r.ToMove
+	return r
+}
+
+// Terminal reports whether a player is on Target or the turns have run out.
+func (d DiceRace) Terminal(s State) bool {
+	r := s.(DiceRaceState)
+	return r.Pos[0] == d.Target || r.Pos[1] == d.Target || r.Turns >= d.MaxTurns
+}
+
+// Utility is 1 if player has won, -1 if player has lost and 0 for a draw.
+func (d DiceRace) Utility(s State, player int) float64 {
+	r := s.(DiceRaceState)
+	switch {
+	case r.Pos[player] == d.Target:
+		return 1
+	case r.Pos[1-player] == d.Target:
+		return -1
+	}
+	return 0
+}
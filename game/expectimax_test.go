@@ -0,0 +1,119 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/siuyin/ai/search"
+)
+
+func ExampleExpectimax() {
+	// with coin-like two-sided dice, a target of 2 and one turn each,
+	// rolling one die wins half the time outright and is worth 1/4;
+	// rolling two is worth -1/8.
+	g := DiceRace{Sides: 2, Target: 2, MaxTurns: 2}
+	e := &Expectimax{}
+	a, v := e.Search(g, DiceRaceState{})
+	fmt.Println(a.Name, v)
+	// Output:
+	// one 0.25
+}
+
+func TestDiceRaceOutcomes(t *testing.T) {
+	g := DiceRace{Sides: 6}
+	sum, mean := 0.0, 0.0
+	oo := g.Outcomes(DiceRaceState{Dice: 2})
+	for _, o := range oo {
+		sum += o.Probability
+		mean += o.Probability * float64(o.Action.ID)
+	}
+	if len(oo) != 11 || math.Abs(sum-1) > 1e-12 || math.Abs(mean-7) > 1e-12 {
+		t.Errorf("unexpected distribution: %d outcomes, total %v, mean %v", len(oo), sum, mean)
+	}
+}
+
+func TestStarMinimaxMatchesExpectimax(t *testing.T) {
+	for _, g := range []DiceRace{
+		{Sides: 2, Target: 2, MaxTurns: 2},
+		{Sides: 3, Target: 5, MaxTurns: 4},
+		{Sides: 3, Target: 6, MaxTurns: 6},
+	} {
+		for _, s := range []DiceRaceState{{}, {Pos: [2]int{3, 1}, ToMove: 1}} {
+			e := &Expectimax{}
+			ea, ev := e.Search(g, s)
+			st := &StarMinimax{Lower: -1, Upper: 1}
+			sa, sv := st.Search(g, s)
+			if math.Abs(ev-sv) > 1e-9 || ea != sa {
+				t.Errorf("%+v from %+v: expectimax %v %v, star %v %v", g, s, ea.Name, ev, sa.Name, sv)
+			}
+			if st.Nodes > e.Nodes {
+				t.Errorf("%+v: star visited %d nodes, expectimax %d", g, st.Nodes, e.Nodes)
+			}
+		}
+	}
+}
+
+func TestStarMinimaxPrunes(t *testing.T) {
+	g := DiceRace{Sides: 3, Target: 6, MaxTurns: 6}
+	e := &Expectimax{}
+	e.Search(g, DiceRaceState{})
+	st := &StarMinimax{Lower: -1, Upper: 1}
+	st.Search(g, DiceRaceState{})
+	if st.Nodes >= e.Nodes {
+		t.Errorf("star visited %d nodes, expectimax %d", st.Nodes, e.Nodes)
+	}
+}
+
+// loadedCoin is a one-player game of a single flip of a coin that never
+// lands heads. State 0 is the start, 1 the flip and 2 and 3 heads and tails.
+type loadedCoin struct {
+	heads *int // times heads was visited
+}
+
+func (loadedCoin) Player(s State) int { return 0 }
+func (loadedCoin) Actions(s State) []search.Action {
+	return []search.Action{{ID: 1, Name: "flip"}}
+}
+func (loadedCoin) IsChance(s State) bool { return s.(int) == 1 }
+func (loadedCoin) Outcomes(s State) []Outcome {
+	return []Outcome{
+		{Action: search.Action{ID: 2, Name: "heads"}, Probability: 0},
+		{Action: search.Action{ID: 3, Name: "tails"}, Probability: 1},
+	}
+}
+func (c loadedCoin) Result(s State, a search.Action) State {
+	if a.ID == 2 {
+		*c.heads++
+	}
+	return a.ID
+}
+func (loadedCoin) Terminal(s State) bool { return s.(int) >= 2 }
+func (loadedCoin) Utility(s State, p int) float64 {
+	if s.(int) == 2 {
+		return -1
+	}
+	return 1
+}
+
+func TestStarMinimaxZeroProbability(t *testing.T) {
+	g := loadedCoin{heads: new(int)}
+	st := &StarMinimax{Lower: -1, Upper: 1}
+	if _, v := st.Search(g, 0); v != 1 {
+		t.Errorf("unexpected value: %v", v)
+	}
+	if *g.heads != 0 {
+		t.Errorf("impossible outcome searched %d times", *g.heads)
+	}
+}
+
+func TestExpectimaxDepthLimited(t *testing.T) {
+	g := DiceRace{Sides: 3, Target: 6, MaxTurns: 20}
+	e := &Expectimax{MaxDepth: 4, Eval: func(s State, p int) float64 {
+		r := s.(DiceRaceState)
+		return float64(r.Pos[p]-r.Pos[1-p]) / float64(g.Target)
+	}}
+	if _, v := e.Search(g, DiceRaceState{}); v < -1 || v > 1 {
+		t.Errorf("value out of range: %v", v)
+	}
+}
@@ -0,0 +1,326 @@
+// Package game -- given a game between players taking turns.
+// Find the best action for the player to move,
+// assuming every other player also plays well.
+package game
+
+import (
+	"math"
+	"time"
+
+	"github.com/siuyin/ai/search"
+)
+
+// State is a position in a game. Its concrete type is chosen by the game
+// and is opaque to the search.
+type State interface{}
+
+// Game is modelled on search.NextStateter and search.Actionsner,
+// extended for several players.
+type Game interface {
+	// Player returns the index, starting at 0, of the player to move in s.
+	Player(s State) int
+	// Actions returns the legal moves in s.
+	Actions(s State) []search.Action
+	// Result returns the position after taking action a in s.
+	Result(s State, a search.Action) State
+	// Terminal reports whether the game is over in s.
+	Terminal(s State) bool
+	// Utility returns the final score of a terminal state s for player.
+	Utility(s State, player int) float64
+}
+
+// Orderer may be implemented by a Game to sort the actions of s so that
+// the most promising come first. Alpha-beta prunes more the better the ordering.
+type Orderer interface {
+	OrderActions(s State, aa []search.Action) []search.Action
+}
+
+// Evaluation estimates the value of a non-terminal state s for player.
+// It should lie within the range of the game's Utility.
+type Evaluation func(s State, player int) float64
+
+// Minimax returns the best action for the player to move in s and its
+// value, searching every line of play to the end of the game.
+// It is practical only for small games and serves as the reference
+// the faster searches are checked against.
+func Minimax(g Game, s State) (search.Action, float64) {
+	me := g.Player(s)
+	best, bestV := search.Action{}, math.Inf(-1)
+	for _, a := range g.Actions(s) {
+		if v := minimaxValue(g, g.Result(s, a), me); v > bestV {
+			best, bestV = a, v
+		}
+	}
+	return best, bestV
+}
+
+func minimaxValue(g Game, s State, me int) float64 {
+	if g.Terminal(s) {
+		return g.Utility(s, me)
+	}
+	maximizing := g.Player(s) == me
+	v := math.Inf(1)
+	if maximizing {
+		v = math.Inf(-1)
+	}
+	for _, a := range g.Actions(s) {
+		c := minimaxValue(g, g.Result(s, a), me)
+		if maximizing {
+			v = math.Max(v, c)
+		} else {
+			v = math.Min(v, c)
+		}
+	}
+	return v
+}
+
+// AlphaBeta searches two-player game trees with alpha-beta pruning.
+// The zero value searches to the end of the game.
+type AlphaBeta struct {
+	// MaxDepth, if positive, cuts the search off after that many moves
+	// and scores the cutoff states with Eval.
+	MaxDepth int
+	Eval     Evaluation
+
+	// Table, if not nil, stores search results of games that implement
+	// Hasher, so positions reached by more than one line of play are
+	// searched once. It may be kept between searches.
+	Table *TranspositionTable
+
+	// Nodes is the number of states visited by the last search.
+	Nodes int
+
+	deadline time.Time
+	timedOut bool
+	cutoff   bool
+	first    search.Action // tried first at the root, if legal
+}
+
+// Search returns the best action for the player to move in s and its value.
+func (ab *AlphaBeta) Search(g Game, s State) (search.Action, float64) {
+	ab.Nodes = 0
+	ab.timedOut = false
+	ab.cutoff = false
+	if ab.Table != nil {
+		ab.Table.NewSearch()
+	}
+	return ab.root(g, s, ab.MaxDepth)
+}
+
+func (ab *AlphaBeta) root(g Game, s State, depth int) (search.Action, float64) {
+	me := g.Player(s)
+	alpha, beta := math.Inf(-1), math.Inf(1)
+	best, found := search.Action{}, false
+	for _, a := range ab.rootOrder(g, s) {
+		v := ab.value(g, g.Result(s, a), me, depth-1, alpha, beta)
+		if ab.timedOut {
+			break
+		}
+		if v > alpha || !found {
+			best, alpha, found = a, math.Max(alpha, v), true
+		}
+	}
+	return best, alpha
+}
+
+func (ab *AlphaBeta) rootOrder(g Game, s State) []search.Action {
+	aa := order(g, s, g.Actions(s))
+	for i, a := range aa {
+		if a == ab.first {
+			return promote(aa, i)
+		}
+	}
+	return aa
+}
+
+// promote returns aa with aa[i] moved to the front.
+func promote(aa []search.Action, i int) []search.Action {
+	if i == 0 {
+		return aa
+	}
+	return append([]search.Action{aa[i]}, append(append([]search.Action{}, aa[:i]...), aa[i+1:]...)...)
+}
+
+func order(g Game, s State, aa []search.Action) []search.Action {
+	if o, ok := g.(Orderer); ok {
+		return o.OrderActions(s, aa)
+	}
+	return aa
+}
+
+// unlimited is the remaining depth of results that reached the end
+// of the game on every line, which are good for a search of any depth.
+const unlimited = math.MaxInt32
+
+// value looks s up in the transposition table, if there is one,
+// before searching it with alphaBeta.
+// The cutoff flag is saved and restored around the search of s so that
+// results which never hit the depth limit can be stored as unlimited.
+func (ab *AlphaBeta) value(g Game, s State, me, depth int, alpha, beta float64) float64 {
+	ab.Nodes++
+	if ab.Nodes%1024 == 0 && !ab.deadline.IsZero() && time.Now().After(ab.deadline) {
+		ab.timedOut = true
+	}
+	if ab.timedOut {
+		return 0
+	}
+	if g.Terminal(s) {
+		return g.Utility(s, me)
+	}
+	if ab.MaxDepth > 0 && depth <= 0 {
+		ab.cutoff = true
+		if ab.Eval == nil {
+			return 0
+		}
+		return ab.Eval(s, me)
+	}
+
+	aa := order(g, s, g.Actions(s))
+	h, hashed := g.(Hasher)
+	if ab.Table == nil || !hashed {
+		v, _ := ab.alphaBeta(g, s, aa, me, depth, alpha, beta)
+		return v
+	}
+	need := unlimited
+	if ab.MaxDepth > 0 {
+		need = depth
+	}
+	key := h.Hash(s) ^ playerKey(me)
+	if e, ok := ab.Table.Probe(key); ok {
+		if e.Depth >= need {
+			if e.Depth != unlimited {
+				ab.cutoff = true
+			}
+			switch e.Bound {
+			case Exact:
+				return e.Value
+			case Lower:
+				alpha = math.Max(alpha, e.Value)
+			case Upper:
+				beta = math.Min(beta, e.Value)
+			}
+			if alpha >= beta {
+				return e.Value
+			}
+		}
+		for i, a := range aa {
+			if a.ID == e.Move {
+				aa = promote(aa, i)
+				break
+			}
+		}
+	}
+
+	outer := ab.cutoff
+	ab.cutoff = false
+	v, move := ab.alphaBeta(g, s, aa, me, depth, alpha, beta)
+	e := Entry{Key: key, Value: v, Move: move, Depth: unlimited, Bound: Exact}
+	if ab.cutoff {
+		e.Depth = depth
+	}
+	ab.cutoff = ab.cutoff || outer
+	if ab.timedOut {
+		return v
+	}
+	switch {
+	case v <= alpha:
+		e.Bound = Upper
+	case v >= beta:
+		e.Bound = Lower
+	}
+	ab.Table.Store(e)
+	return v
+}
+
+// playerKey distinguishes the values of a position searched for
+// different players.
+func playerKey(me int) uint64 {
+	return uint64(me+1) * 0x9e3779b97f4a7c15
+}
+
+// Pseudocode from Russell and Norvig, AIMA 3rd ed. figure 5.7:
+//
+//	function MAX-VALUE(state, α, β) returns a utility value
+//	    if TERMINAL-TEST(state) then return UTILITY(state)
+//	    v := -∞
+//	    for each a in ACTIONS(state) do
+//	        v := MAX(v, MIN-VALUE(RESULT(state, a), α, β))
+//	        if v >= β then return v
+//	        α := MAX(α, v)
+//	    return v
+//
+// MIN-VALUE is the mirror image. alphaBeta plays both roles, depending on
+// whose move it is in s, and also returns the ID of the best action, or -1.
+func (ab *AlphaBeta) alphaBeta(g Game, s State, aa []search.Action, me, depth int, alpha, beta float64) (float64, int) {
+	move := -1
+	if g.Player(s) == me {
+		v := math.Inf(-1)
+		for _, a := range aa {
+			if c := ab.value(g, g.Result(s, a), me, depth-1, alpha, beta); c > v || move < 0 {
+				v, move = c, a.ID
+			}
+			if v >= beta {
+				return v, move
+			}
+			alpha = math.Max(alpha, v)
+		}
+		return v, move
+	}
+	v := math.Inf(1)
+	for _, a := range aa {
+		if c := ab.value(g, g.Result(s, a), me, depth-1, alpha, beta); c < v || move < 0 {
+			v, move = c, a.ID
+		}
+		if v <= alpha {
+			return v, move
+		}
+		beta = math.Min(beta, v)
+	}
+	return v, move
+}
+
+// IterativeDeepening runs depth-limited alpha-beta searches of depth
+// 1, 2, 3 ... until budget runs out or a search reaches the end of the
+// game everywhere. It returns the action and value from the deepest
+// completed search, and that depth. The best action of each depth is
+// tried first at the next, which improves pruning; with a Table the
+// same is done for every position stored from the shallower searches.
+func (ab *AlphaBeta) IterativeDeepening(g Game, s State, budget time.Duration) (search.Action, float64, int) {
+	maxDepth := ab.MaxDepth
+	defer func() {
+		ab.MaxDepth = maxDepth
+		ab.deadline = time.Time{}
+		ab.first = search.Action{}
+	}()
+
+	ab.deadline = time.Now().Add(budget)
+	ab.timedOut = false
+	if ab.Table != nil {
+		ab.Table.NewSearch()
+	}
+	nodes := 0
+	best, bestV, reached := search.Action{}, 0.0, 0
+	for depth := 1; maxDepth <= 0 || depth <= maxDepth; depth++ {
+		ab.MaxDepth = depth
+		ab.cutoff = false
+		ab.Nodes = 0
+		a, v := ab.root(g, s, depth)
+		nodes += ab.Nodes
+		if ab.timedOut {
+			break
+		}
+		best, bestV, reached = a, v, depth
+		ab.first = a
+		if !ab.cutoff {
+			break
+		}
+	}
+	ab.Nodes = nodes
+	if reached == 0 {
+		// not even a depth 1 search finished: any legal move beats none.
+		if aa := order(g, s, g.Actions(s)); len(aa) > 0 {
+			best = aa[0]
+		}
+	}
+	return best, bestV, reached
+}
@@ -0,0 +1,126 @@
+package game
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/siuyin/ai/search"
+)
+
+// unordered is TicTacToe without its Orderer, so the effect of ordering can be measured.
+type unordered struct {
+	t TicTacToe
+}
+
+func (u unordered) Player(s State) int                    { return u.t.Player(s) }
+func (u unordered) Actions(s State) []search.Action       { return u.t.Actions(s) }
+func (u unordered) Result(s State, a search.Action) State { return u.t.Result(s, a) }
+func (u unordered) Terminal(s State) bool                 { return u.t.Terminal(s) }
+func (u unordered) Utility(s State, p int) float64        { return u.t.Utility(s, p) }
+
+func board(rows string) TicTacToeBoard {
+	b := TicTacToeBoard{}
+	i := 0
+	for _, c := range rows {
+		switch c {
+		case 'X', 'O':
+			b[i] = byte(c)
+			i++
+		case '.':
+			i++
+		}
+	}
+	return b
+}
+
+func ExampleAlphaBeta() {
+	t := TicTacToe{}
+	s := board(`
+		XO.
+		.X.
+		O..`)
+	ab := &AlphaBeta{}
+	a, v := ab.Search(t, s)
+	fmt.Println(a.Name, v)
+	fmt.Println(t.Result(s, a))
+	// Output:
+	// c3 1
+	// XO.
+	// .X.
+	// O.X
+}
+
+func TestMinimaxEmptyBoardIsDraw(t *testing.T) {
+	g := TicTacToe{}
+	_, v := Minimax(g, g.Start())
+	if v != 0 {
+		t.Errorf("unexpected value: %v", v)
+	}
+}
+
+func TestAlphaBetaAgreesWithMinimax(t *testing.T) {
+	g := TicTacToe{}
+	positions := []TicTacToeBoard{
+		board("X........"),
+		board("X...O...."),
+		board("XO..X...."),
+		board("X.O.O...X"),
+		board("OX.XO...."),
+	}
+	for i, s := range positions {
+		_, mv := Minimax(g, s)
+		ab := &AlphaBeta{}
+		a, v := ab.Search(g, s)
+		if v != mv {
+			t.Errorf("case %d: alpha-beta value %v, minimax %v", i, v, mv)
+		}
+		if got := minimaxValue(g, g.Result(s, a), g.Player(s)); got != mv {
+			t.Errorf("case %d: alpha-beta move %v is worth %v, not %v", i, a.Name, got, mv)
+		}
+	}
+}
+
+func TestMoveOrderingPrunesMore(t *testing.T) {
+	ordered := &AlphaBeta{}
+	ordered.Search(TicTacToe{}, TicTacToeBoard{})
+	plain := &AlphaBeta{}
+	plain.Search(unordered{}, TicTacToeBoard{})
+	if ordered.Nodes >= plain.Nodes {
+		t.Errorf("ordered search visited %d nodes, unordered %d", ordered.Nodes, plain.Nodes)
+	}
+}
+
+func TestDepthLimited(t *testing.T) {
+	g := TicTacToe{}
+	// O must block X's column.
+	s := board(`
+		XO.
+		X..
+		...`)
+	ab := &AlphaBeta{MaxDepth: 2, Eval: g.Eval}
+	a, _ := ab.Search(g, s)
+	if a.Name != "a3" {
+		t.Errorf("unexpected move: %v", a.Name)
+	}
+}
+
+func TestIterativeDeepening(t *testing.T) {
+	g := TicTacToe{}
+	ab := &AlphaBeta{Eval: g.Eval}
+	a, v, depth := ab.IterativeDeepening(g, TicTacToeBoard{}, time.Second)
+	if v != 0 || depth != 9 {
+		t.Errorf("unexpected value %v at depth %d", v, depth)
+	}
+	if a == (search.Action{}) {
+		t.Error("no move returned")
+	}
+	if ab.MaxDepth != 0 {
+		t.Errorf("MaxDepth not restored: %d", ab.MaxDepth)
+	}
+
+	a, _, _ = ab.IterativeDeepening(g, TicTacToeBoard{}, 0)
+	if a == (search.Action{}) {
+		t.Error("no move returned under an exhausted budget")
+	}
+}
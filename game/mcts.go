@@ -0,0 +1,221 @@
+package game
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/siuyin/ai/search"
+)
+
+// Playout chooses the move to make in s during a simulated game.
+type Playout func(g Game, s State, r *rand.Rand) search.Action
+
+// RandomPlayout chooses uniformly among the legal moves.
+func RandomPlayout(g Game, s State, r *rand.Rand) search.Action {
+	aa := g.Actions(s)
+	return aa[r.Intn(len(aa))]
+}
+
+// MCTS is a Monte Carlo tree search engine using the UCT selection rule.
+// It works for any number of players and expects utilities in [0, 1]
+// or [-1, 1]; Exploration may need tuning for other ranges.
+//
+// The search tree is kept between moves: call Advance with every move
+// played, by any player, and the next Search continues from the
+// matching subtree. A tree whose root is not the searched position is
+// rebuilt, so Search never answers for a different position, but Reset
+// should still be called between games to start afresh.
+type MCTS struct {
+	Iterations  int           // simulations per worker per move, if positive
+	Budget      time.Duration // time per move, if positive; with neither, 1000 iterations
+	Exploration float64       // UCT constant; 0 means √2
+	Workers     int           // independent trees searched in parallel, at least 1
+	Playout     Playout       // nil means RandomPlayout
+	Seed        int64         // runs with the same seed and an Iterations budget give the same result
+
+	// Simulations is the number of games simulated by the last Search
+	// across all workers.
+	Simulations int
+
+	roots []*mctsNode
+	rnds  []*rand.Rand
+}
+
+type mctsNode struct {
+	action   search.Action // move that led here
+	player   int           // player who made that move
+	state    State
+	parent   *mctsNode
+	children []*mctsNode
+	untried  []search.Action
+	visits   float64
+	reward   float64 // total reward for player
+}
+
+func newMCTSNode(g Game, s State, parent *mctsNode, a search.Action, player int) *mctsNode {
+	n := &mctsNode{action: a, player: player, state: s, parent: parent}
+	if !g.Terminal(s) {
+		n.untried = append([]search.Action{}, g.Actions(s)...)
+	}
+	return n
+}
+
+// Search returns the move in s that was simulated most often
+// across all workers. Trees kept from earlier searches are only
+// reused if their root is s.
+func (m *MCTS) Search(g Game, s State) search.Action {
+	workers := m.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	if len(m.roots) != workers {
+		m.roots = make([]*mctsNode, workers)
+		m.rnds = make([]*rand.Rand, workers)
+		for i := range m.rnds {
+			m.rnds[i] = rand.New(rand.NewSource(m.Seed + int64(i)))
+		}
+	}
+
+	iterations := m.Iterations
+	var deadline time.Time
+	if m.Budget > 0 {
+		deadline = time.Now().Add(m.Budget)
+	} else if iterations <= 0 {
+		iterations = 1000
+	}
+	sims := make([]int, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		if m.roots[w] == nil || !sameState(g, m.roots[w].state, s) {
+			m.roots[w] = newMCTSNode(g, s, nil, search.Action{}, -1)
+		}
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for iterations <= 0 || sims[w] < iterations {
+				if !deadline.IsZero() && time.Now().After(deadline) {
+					break
+				}
+				m.simulate(g, m.roots[w], m.rnds[w])
+				sims[w]++
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	m.Simulations = 0
+	visits := map[search.Action]float64{}
+	order := []search.Action{}
+	for w, root := range m.roots {
+		m.Simulations += sims[w]
+		for _, c := range root.children {
+			if _, ok := visits[c.action]; !ok {
+				order = append(order, c.action)
+			}
+			visits[c.action] += c.visits
+		}
+	}
+	best, bestV := search.Action{}, -1.0
+	for _, a := range order {
+		if visits[a] > bestV {
+			best, bestV = a, visits[a]
+		}
+	}
+	if len(order) == 0 {
+		if aa := g.Actions(s); len(aa) > 0 {
+			best = aa[0]
+		}
+	}
+	return best
+}
+
+// sameState reports whether a and b are the same position, comparing
+// hashes if g is a Hasher.
+func sameState(g Game, a, b State) bool {
+	if h, ok := g.(Hasher); ok {
+		return h.Hash(a) == h.Hash(b)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// simulate runs one selection, expansion, simulation and
+// backpropagation pass from root.
+func (m *MCTS) simulate(g Game, root *mctsNode, r *rand.Rand) {
+	c := m.Exploration
+	if c == 0 {
+		c = math.Sqrt2
+	}
+	playout := m.Playout
+	if playout == nil {
+		playout = RandomPlayout
+	}
+
+	n := root
+	for len(n.untried) == 0 && len(n.children) > 0 {
+		n = n.bestChild(c)
+	}
+	if len(n.untried) > 0 {
+		i := r.Intn(len(n.untried))
+		a := n.untried[i]
+		n.untried = append(n.untried[:i], n.untried[i+1:]...)
+		child := newMCTSNode(g, g.Result(n.state, a), n, a, g.Player(n.state))
+		n.children = append(n.children, child)
+		n = child
+	}
+
+	s := n.state
+	for !g.Terminal(s) {
+		s = g.Result(s, playout(g, s, r))
+	}
+
+	for ; n != nil; n = n.parent {
+		n.visits++
+		if n.parent != nil {
+			n.reward += g.Utility(s, n.player)
+		}
+	}
+}
+
+func (n *mctsNode) bestChild(c float64) *mctsNode {
+	var best *mctsNode
+	bestV := math.Inf(-1)
+	logN := math.Log(n.visits)
+	for _, ch := range n.children {
+		v := ch.reward/ch.visits + c*math.Sqrt(logN/ch.visits)
+		if v > bestV {
+			best, bestV = ch, v
+		}
+	}
+	return best
+}
+
+// Advance moves every worker's tree down to the subtree for move a,
+// which any player may have made, so its statistics are reused by the
+// next Search. Trees that never explored a are discarded.
+func (m *MCTS) Advance(a search.Action) {
+	for w, root := range m.roots {
+		if root == nil {
+			continue
+		}
+		var next *mctsNode
+		for _, c := range root.children {
+			if c.action == a {
+				next = c
+				break
+			}
+		}
+		if next != nil {
+			next.parent = nil
+		}
+		m.roots[w] = next
+	}
+}
+
+// Reset discards the search trees. The next Search starts its random
+// number generators afresh from Seed.
+func (m *MCTS) Reset() {
+	m.roots, m.rnds = nil, nil
+}
@@ -0,0 +1,145 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/siuyin/ai/search"
+)
+
+func TestMCTSFindsWin(t *testing.T) {
+	g := TicTacToe{}
+	s := board(`
+		XO.
+		.X.
+		O..`)
+	m := &MCTS{Iterations: 2000, Seed: 1}
+	if a := m.Search(g, s); a.Name != "c3" {
+		t.Errorf("unexpected move: %v", a.Name)
+	}
+}
+
+func TestMCTSBlocks(t *testing.T) {
+	g := TicTacToe{}
+	s := board(`
+		XO.
+		X..
+		...`)
+	m := &MCTS{Iterations: 3000, Workers: 4, Seed: 2}
+	if a := m.Search(g, s); a.Name != "a3" {
+		t.Errorf("unexpected move: %v", a.Name)
+	}
+	if m.Simulations != 12000 {
+		t.Errorf("unexpected simulations: %d", m.Simulations)
+	}
+}
+
+func TestMCTSDeterministic(t *testing.T) {
+	g := TicTacToe{}
+	a := (&MCTS{Iterations: 500, Workers: 3, Seed: 7}).Search(g, g.Start())
+	b := (&MCTS{Iterations: 500, Workers: 3, Seed: 7}).Search(g, g.Start())
+	if a != b {
+		t.Errorf("same seed gave %v and %v", a, b)
+	}
+}
+
+func TestMCTSTreeReuse(t *testing.T) {
+	g := TicTacToe{}
+	m := &MCTS{Iterations: 2000, Seed: 3}
+	s := g.Start()
+	a := m.Search(g, s)
+	s = g.Result(s, a)
+	m.Advance(a)
+	reply := g.Actions(s)[0]
+	s = g.Result(s, reply)
+	m.Advance(reply)
+	if m.roots[0] == nil || m.roots[0].visits == 0 {
+		t.Fatal("tree was not kept")
+	}
+	kept := m.roots[0].visits
+	m.Search(g, s)
+	if m.roots[0].visits != kept+2000 {
+		t.Errorf("root visits %v, want %v", m.roots[0].visits, kept+2000)
+	}
+
+	m.Reset()
+	m.Search(g, s)
+	if m.roots[0].visits != 2000 {
+		t.Errorf("reset tree has %v visits", m.roots[0].visits)
+	}
+}
+
+func TestMCTSRebuildsForOtherPosition(t *testing.T) {
+	g := TicTacToe{}
+	m := &MCTS{Iterations: 500, Seed: 4}
+	m.Search(g, g.Start())
+
+	// search a different position without Advance.
+	s := board(`
+		XO.
+		.X.
+		O..`)
+	a := m.Search(g, s)
+	if m.roots[0].visits != 500 {
+		t.Errorf("stale tree kept: %v visits", m.roots[0].visits)
+	}
+	if a.Name != "c3" {
+		t.Errorf("unexpected move: %v", a.Name)
+	}
+}
+
+func TestMCTSTimeBudget(t *testing.T) {
+	g := TicTacToe{}
+	m := &MCTS{Budget: 20 * time.Millisecond, Workers: 2}
+	if a := m.Search(g, g.Start()); a == (search.Action{}) {
+		t.Error("no move returned")
+	}
+	if m.Simulations == 0 {
+		t.Error("no simulations run")
+	}
+}
+
+// race is a three player game. Players in turn add 1 or 2 to a counter
+// and whoever brings it to exactly 10 wins.
+type race struct{}
+
+type raceState struct {
+	count, toMove, winner int
+}
+
+func (race) Player(s State) int { return s.(raceState).toMove }
+func (race) Actions(s State) []search.Action {
+	return []search.Action{{ID: 1, Name: "+1"}, {ID: 2, Name: "+2"}}
+}
+func (race) Result(s State, a search.Action) State {
+	r := s.(raceState)
+	r.count += a.ID
+	r.winner = -1
+	if r.count >= 10 {
+		r.winner = r.toMove
+		if r.count > 10 {
+			r.winner = (r.toMove + 1) % 3 // overshooting hands the win to the next player.
+		}
+	}
+	r.toMove = (r.toMove + 1) % 3
+	return r
+}
+func (race) Terminal(s State) bool { return s.(raceState).count >= 10 }
+func (race) Utility(s State, p int) float64 {
+	if s.(raceState).winner == p {
+		return 1
+	}
+	return 0
+}
+
+func TestMCTSThreePlayers(t *testing.T) {
+	g := race{}
+	m := &MCTS{Iterations: 1000, Seed: 1}
+	if a := m.Search(g, raceState{count: 8, toMove: 2, winner: -1}); a.Name != "+2" {
+		t.Errorf("unexpected move: %v", a.Name)
+	}
+	m.Reset()
+	if a := m.Search(g, raceState{count: 9, toMove: 1, winner: -1}); a.Name != "+1" {
+		t.Errorf("unexpected move: %v", a.Name)
+	}
+}
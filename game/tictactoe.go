@@ -0,0 +1,165 @@
+package game
+
+import (
+	"strings"
+
+	"github.com/siuyin/ai/search"
+)
+
+// TicTacToe is noughts and crosses on a 3x3 board.
+// Player 0 plays X and moves first. Cells are numbered 0 to 8 row by row
+// and the action for a cell has that ID and a name such as "b2".
+type TicTacToe struct{}
+
+// TicTacToeBoard is a TicTacToe State.
+// Each cell holds 'X', 'O' or 0 when empty.
+type TicTacToeBoard [9]byte
+
+var tttLines = [8][3]int{
+	{0, 1, 2}, {3, 4, 5}, {6, 7, 8},
+	{0, 3, 6}, {1, 4, 7}, {2, 5, 8},
+	{0, 4, 8}, {2, 4, 6},
+}
+
+// tttZobrist has a key for X (value 1) and O (value 2) in each cell.
+var tttZobrist = NewZobrist(9, 3, 1)
+
+// Start returns the empty board.
+func (TicTacToe) Start() State {
+	return TicTacToeBoard{}
+}
+
+// Player returns 0 if X is to move and 1 if O is.
+func (TicTacToe) Player(s State) int {
+	b := s.(TicTacToeBoard)
+	n := 0
+	for _, c := range b {
+		if c != 0 {
+			n++
+		}
+	}
+	return n % 2
+}
+
+// Actions returns the empty cells.
+func (TicTacToe) Actions(s State) []search.Action {
+	b := s.(TicTacToeBoard)
+	aa := []search.Action{}
+	for i, c := range b {
+		if c == 0 {
+			aa = append(aa, TicTacToeAction(i))
+		}
+	}
+	return aa
+}
+
+// TicTacToeAction returns the action that marks cell i.
+func TicTacToeAction(i int) search.Action {
+	return search.Action{ID: i, Name: string([]byte{byte('a' + i%3), byte('1' + i/3)})}
+}
+
+// Result marks the cell of action a for the player to move.
+func (t TicTacToe) Result(s State, a search.Action) State {
+	b := s.(TicTacToeBoard)
+	b[a.ID] = "XO"[t.Player(s)]
+	return b
+}
+
+// Terminal reports whether a player has three in a row or the board is full.
+func (t TicTacToe) Terminal(s State) bool {
+	return t.winner(s.(TicTacToeBoard)) != 0 || len(t.Actions(s)) == 0
+}
+
+func (TicTacToe) winner(b TicTacToeBoard) byte {
+	for _, l := range tttLines {
+		if b[l[0]] != 0 && b[l[0]] == b[l[1]] && b[l[1]] == b[l[2]] {
+			return b[l[0]]
+		}
+	}
+	return 0
+}
+
+// Utility is 1 if player has won, -1 if player has lost and 0 otherwise.
+func (t TicTacToe) Utility(s State, player int) float64 {
+	w := t.winner(s.(TicTacToeBoard))
+	switch {
+	case w == 0:
+		return 0
+	case w == "XO"[player]:
+		return 1
+	}
+	return -1
+}
+
+// Hash returns the Zobrist hash of the board. The player to move
+// follows from the number of marks, so needs no key of its own.
+func (TicTacToe) Hash(s State) uint64 {
+	h := uint64(0)
+	for i, c := range s.(TicTacToeBoard) {
+		switch c {
+		case 'X':
+			h ^= tttZobrist.Key(i, 1)
+		case 'O':
+			h ^= tttZobrist.Key(i, 2)
+		}
+	}
+	return h
+}
+
+// OrderActions tries the centre first, then the corners, then the edges.
+func (TicTacToe) OrderActions(s State, aa []search.Action) []search.Action {
+	rank := [9]int{1, 2, 1, 2, 0, 2, 1, 2, 1}
+	out := []search.Action{}
+	for r := 0; r <= 2; r++ {
+		for _, a := range aa {
+			if rank[a.ID] == r {
+				out = append(out, a)
+			}
+		}
+	}
+	return out
+}
+
+// Eval scores a board for player by the number of lines still open to
+// player minus those open to the opponent, scaled to lie within (-1, 1).
+func (TicTacToe) Eval(s State, player int) float64 {
+	b := s.(TicTacToeBoard)
+	me, them := "XO"[player], "XO"[1-player]
+	score := 0
+	for _, l := range tttLines {
+		mine, theirs := 0, 0
+		for _, c := range l {
+			switch b[c] {
+			case me:
+				mine++
+			case them:
+				theirs++
+			}
+		}
+		if theirs == 0 && mine > 0 {
+			score++
+		}
+		if mine == 0 && theirs > 0 {
+			score--
+		}
+	}
+	return float64(score) / 9
+}
+
+// String draws the board with '.' for empty cells.
+func (b TicTacToeBoard) String() string {
+	var sb strings.Builder
+	for r := 0; r < 3; r++ {
+		for c := 0; c < 3; c++ {
+			ch := b[r*3+c]
+			if ch == 0 {
+				ch = '.'
+			}
+			sb.WriteByte(ch)
+		}
+		if r < 2 {
+			sb.WriteByte('\n')
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,390 @@
+package game
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/siuyin/ai/search"
+)
+
+// Agent plays a game by choosing moves.
+type Agent interface {
+	// NewGame is called before each game with a seed for any
+	// randomness the agent uses.
+	NewGame(seed int64)
+	// Move returns the agent's move in s.
+	Move(g Game, s State) search.Action
+	// Played is told of every move made, by any player, in the current game.
+	Played(a search.Action)
+}
+
+// RandomAgent moves uniformly at random.
+type RandomAgent struct {
+	r *rand.Rand
+}
+
+// NewGame reseeds the agent.
+func (ra *RandomAgent) NewGame(seed int64) {
+	ra.r = rand.New(rand.NewSource(seed))
+}
+
+// Move returns a random legal move.
+func (ra *RandomAgent) Move(g Game, s State) search.Action {
+	if ra.r == nil {
+		ra.NewGame(0)
+	}
+	return RandomPlayout(g, s, ra.r)
+}
+
+// Played does nothing.
+func (ra *RandomAgent) Played(a search.Action) {}
+
+// MinimaxAgent plays perfectly using Minimax. It is practical only for
+// small games.
+type MinimaxAgent struct{}
+
+// NewGame does nothing.
+func (MinimaxAgent) NewGame(seed int64) {}
+
+// Move returns the Minimax move.
+func (MinimaxAgent) Move(g Game, s State) search.Action {
+	a, _ := Minimax(g, s)
+	return a
+}
+
+// Played does nothing.
+func (MinimaxAgent) Played(a search.Action) {}
+
+// AlphaBetaAgent moves using AlphaBeta, with IterativeDeepening if
+// Budget is positive.
+type AlphaBetaAgent struct {
+	AlphaBeta *AlphaBeta
+	Budget    time.Duration
+}
+
+// NewGame clears the transposition table, if any.
+func (aa *AlphaBetaAgent) NewGame(seed int64) {
+	if aa.AlphaBeta.Table != nil {
+		aa.AlphaBeta.Table.Clear()
+	}
+}
+
+// Move returns the best move found by the search.
+func (aa *AlphaBetaAgent) Move(g Game, s State) search.Action {
+	if aa.Budget > 0 {
+		a, _, _ := aa.AlphaBeta.IterativeDeepening(g, s, aa.Budget)
+		return a
+	}
+	a, _ := aa.AlphaBeta.Search(g, s)
+	return a
+}
+
+// Played does nothing.
+func (aa *AlphaBetaAgent) Played(a search.Action) {}
+
+// MCTSAgent moves using MCTS, keeping its search tree between moves.
+type MCTSAgent struct {
+	MCTS *MCTS
+}
+
+// NewGame discards the search trees and reseeds the search.
+func (ma *MCTSAgent) NewGame(seed int64) {
+	ma.MCTS.Seed = seed
+	ma.MCTS.Reset()
+}
+
+// Move returns the most simulated move.
+func (ma *MCTSAgent) Move(g Game, s State) search.Action {
+	return ma.MCTS.Search(g, s)
+}
+
+// Played advances the search trees.
+func (ma *MCTSAgent) Played(a search.Action) {
+	ma.MCTS.Advance(a)
+}
+
+// HumanAgent shows the position on Out and reads the name of a move
+// from In, asking again until it is legal. At the end of input it plays
+// the first legal move.
+type HumanAgent struct {
+	In  io.Reader
+	Out io.Writer
+
+	r *bufio.Reader
+}
+
+// NewGame does nothing.
+func (h *HumanAgent) NewGame(seed int64) {}
+
+// Move asks for a move.
+func (h *HumanAgent) Move(g Game, s State) search.Action {
+	if h.r == nil {
+		h.r = bufio.NewReader(h.In)
+	}
+	aa := g.Actions(s)
+	names := []string{}
+	for _, a := range aa {
+		names = append(names, a.Name)
+	}
+	fmt.Fprintf(h.Out, "\n%v\n", s)
+	for {
+		fmt.Fprintf(h.Out, "Your move (%s): ", strings.Join(names, " "))
+		line, err := h.r.ReadString('\n')
+		for _, a := range aa {
+			if a.Name == strings.TrimSpace(line) {
+				return a
+			}
+		}
+		if err != nil {
+			return aa[0]
+		}
+	}
+}
+
+// Played does nothing.
+func (h *HumanAgent) Played(a search.Action) {}
+
+// Entrant is an Agent taking part in a Tournament.
+type Entrant struct {
+	Name  string
+	Agent Agent
+}
+
+// Tournament plays every pair of entrants against each other in a
+// two-player game, each taking each seat Rounds times. Each entrant needs
+// an Agent of its own. Runs with the same Seed give the same results, as
+// long as no agent depends on the clock.
+type Tournament struct {
+	Game     Game
+	Start    State
+	Entrants []Entrant
+	Rounds   int     // games per pairing and seat; at least 1
+	Seed     int64   // seeds the agents for each game
+	K        float64 // Elo K-factor; 0 means 32
+	MaxMoves int     // if positive, games still going after this many moves are drawn
+
+	// Log, if not nil, is sent a line for every game played.
+	Log io.Writer
+}
+
+// Score counts an entrant's wins, draws and losses.
+type Score struct {
+	Wins, Draws, Losses int
+}
+
+// Played returns the number of games counted in sc.
+func (sc Score) Played() int {
+	return sc.Wins + sc.Draws + sc.Losses
+}
+
+func (sc Score) String() string {
+	return fmt.Sprintf("%d/%d/%d", sc.Wins, sc.Draws, sc.Losses)
+}
+
+// GameRecord describes one game of a Tournament.
+type GameRecord struct {
+	Players [2]int // entrant index in each seat
+	Winner  int    // seat of the winner, or -1 for a draw
+	Moves   int
+	Seed    int64
+}
+
+// Results are the outcome of a Tournament. Entrants are indexed as in
+// Tournament.Entrants.
+type Results struct {
+	Names    []string
+	Scores   []Score         // totals for each entrant
+	Cross    [][]Score       // Cross[i][j] is i's score against j
+	Elo      []float64       // ratings from 1500, updated after each game
+	MoveTime []time.Duration // mean time per move
+	Games    []GameRecord
+}
+
+// Run plays the tournament.
+func (t *Tournament) Run() *Results {
+	n := len(t.Entrants)
+	res := &Results{
+		Names:    make([]string, n),
+		Scores:   make([]Score, n),
+		Cross:    make([][]Score, n),
+		Elo:      make([]float64, n),
+		MoveTime: make([]time.Duration, n),
+	}
+	for i, e := range t.Entrants {
+		res.Names[i] = e.Name
+		res.Cross[i] = make([]Score, n)
+		res.Elo[i] = 1500
+	}
+	k := t.K
+	if k == 0 {
+		k = 32
+	}
+	rounds := t.Rounds
+	if rounds < 1 {
+		rounds = 1
+	}
+
+	seeds := rand.New(rand.NewSource(t.Seed))
+	thinking := make([]time.Duration, n)
+	moves := make([]int, n)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			for r := 0; r < rounds; r++ {
+				for _, seats := range [][2]int{{i, j}, {j, i}} {
+					rec := t.play(seats, seeds.Int63(), thinking, moves)
+					res.record(rec, k)
+					if t.Log != nil {
+						fmt.Fprintf(t.Log, "%s v %s: %s in %d moves\n",
+							res.Names[seats[0]], res.Names[seats[1]], res.outcome(rec), rec.Moves)
+					}
+				}
+			}
+		}
+	}
+	for i := range res.MoveTime {
+		if moves[i] > 0 {
+			res.MoveTime[i] = thinking[i] / time.Duration(moves[i])
+		}
+	}
+	return res
+}
+
+// play plays one game between the entrants in seats, adding each
+// entrant's thinking time and number of moves to thinking and moves.
+func (t *Tournament) play(seats [2]int, seed int64, thinking []time.Duration, moves []int) GameRecord {
+	rec := GameRecord{Players: seats, Winner: -1, Seed: seed}
+	for p, e := range seats {
+		t.Entrants[e].Agent.NewGame(seed + int64(p))
+	}
+	s := t.Start
+	for !t.Game.Terminal(s) {
+		if t.MaxMoves > 0 && rec.Moves >= t.MaxMoves {
+			return rec
+		}
+		e := seats[t.Game.Player(s)]
+		start := time.Now()
+		a := t.Entrants[e].Agent.Move(t.Game, s)
+		thinking[e] += time.Since(start)
+		moves[e]++
+		for _, o := range seats {
+			t.Entrants[o].Agent.Played(a)
+		}
+		s = t.Game.Result(s, a)
+		rec.Moves++
+	}
+	u0, u1 := t.Game.Utility(s, 0), t.Game.Utility(s, 1)
+	switch {
+	case u0 > u1:
+		rec.Winner = 0
+	case u1 > u0:
+		rec.Winner = 1
+	}
+	return rec
+}
+
+// record adds a game to the scores and ratings.
+func (res *Results) record(rec GameRecord, k float64) {
+	res.Games = append(res.Games, rec)
+	a, b := rec.Players[0], rec.Players[1]
+	score := 0.5 // for seat 0
+	switch rec.Winner {
+	case 0:
+		score = 1
+		res.Scores[a].Wins++
+		res.Scores[b].Losses++
+		res.Cross[a][b].Wins++
+		res.Cross[b][a].Losses++
+	case 1:
+		score = 0
+		res.Scores[a].Losses++
+		res.Scores[b].Wins++
+		res.Cross[a][b].Losses++
+		res.Cross[b][a].Wins++
+	default:
+		res.Scores[a].Draws++
+		res.Scores[b].Draws++
+		res.Cross[a][b].Draws++
+		res.Cross[b][a].Draws++
+	}
+	expected := 1 / (1 + math.Pow(10, (res.Elo[b]-res.Elo[a])/400))
+	res.Elo[a] += k * (score - expected)
+	res.Elo[b] -= k * (score - expected)
+}
+
+func (res *Results) outcome(rec GameRecord) string {
+	if rec.Winner < 0 {
+		return "draw"
+	}
+	return res.Names[rec.Players[rec.Winner]] + " wins"
+}
+
+// Print writes a table of each entrant's wins/draws/losses, Elo rating
+// and mean move time, followed by a cross table of wins/draws/losses
+// against each opponent, read along the rows.
+func (res *Results) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "entrant\tW/D/L\tElo\tmove time\t")
+	for i, name := range res.Names {
+		fmt.Fprintf(tw, "%s\t%v\t%.0f\t%v\t\n", name, res.Scores[i], res.Elo[i], res.MoveTime[i])
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintf(tw, "\t%s\t\n", strings.Join(res.Names, "\t"))
+	for i, name := range res.Names {
+		fmt.Fprintf(tw, "%s\t", name)
+		for j := range res.Names {
+			if i == j {
+				fmt.Fprint(tw, "-\t")
+				continue
+			}
+			fmt.Fprintf(tw, "%v\t", res.Cross[i][j])
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+// WriteCSV writes one row per entrant with the columns entrant, wins,
+// draws, losses, elo and move_ms.
+func (res *Results) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"entrant", "wins", "draws", "losses", "elo", "move_ms"})
+	for i, name := range res.Names {
+		sc := res.Scores[i]
+		cw.Write([]string{
+			name,
+			strconv.Itoa(sc.Wins), strconv.Itoa(sc.Draws), strconv.Itoa(sc.Losses),
+			strconv.FormatFloat(res.Elo[i], 'f', 1, 64),
+			strconv.FormatFloat(res.MoveTime[i].Seconds()*1000, 'f', 3, 64),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteGamesCSV writes one row per game with the columns first, second,
+// winner, moves and seed. winner is the name of the winner or empty for
+// a draw.
+func (res *Results) WriteGamesCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"first", "second", "winner", "moves", "seed"})
+	for _, rec := range res.Games {
+		winner := ""
+		if rec.Winner >= 0 {
+			winner = res.Names[rec.Players[rec.Winner]]
+		}
+		cw.Write([]string{
+			res.Names[rec.Players[0]], res.Names[rec.Players[1]], winner,
+			strconv.Itoa(rec.Moves), strconv.FormatInt(rec.Seed, 10),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,135 @@
+package game
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func ExampleTournament() {
+	t := &Tournament{
+		Game:  TicTacToe{},
+		Start: TicTacToeBoard{},
+		Entrants: []Entrant{
+			{"random", &RandomAgent{}},
+			{"alphabeta", &AlphaBetaAgent{AlphaBeta: &AlphaBeta{}}},
+		},
+		Rounds: 5,
+		Seed:   1,
+	}
+	res := t.Run()
+	for i, name := range res.Names {
+		fmt.Printf("%s %v %.0f\n", name, res.Scores[i], res.Elo[i])
+	}
+	// Output:
+	// random 0/0/10 1390
+	// alphabeta 10/0/0 1610
+}
+
+func TestTournamentPerfectPlay(t *testing.T) {
+	tr := &Tournament{
+		Game:  TicTacToe{},
+		Start: TicTacToeBoard{},
+		Entrants: []Entrant{
+			{"random", &RandomAgent{}},
+			{"alphabeta", &AlphaBetaAgent{AlphaBeta: &AlphaBeta{Table: NewTranspositionTable(1 << 16)}}},
+			{"minimax", MinimaxAgent{}},
+		},
+		Rounds: 2,
+		Seed:   1,
+	}
+	res := tr.Run()
+	if len(res.Games) != 12 {
+		t.Fatalf("played %d games", len(res.Games))
+	}
+	if sc := res.Cross[1][2]; sc.Draws != 4 {
+		t.Errorf("perfect players did not always draw: %v", sc)
+	}
+	for _, p := range []int{1, 2} {
+		if sc := res.Cross[p][0]; sc.Losses != 0 || sc.Wins == 0 {
+			t.Errorf("%s against random: %v", res.Names[p], sc)
+		}
+	}
+	if res.Elo[0] >= 1500 || res.Elo[1] <= 1500 || res.Elo[2] <= 1500 {
+		t.Errorf("unexpected ratings: %v", res.Elo)
+	}
+	sum := 0.0
+	for _, e := range res.Elo {
+		sum += e
+	}
+	if sum < 4499.999 || sum > 4500.001 {
+		t.Errorf("ratings do not sum to 4500: %v", sum)
+	}
+}
+
+func TestTournamentIsSeeded(t *testing.T) {
+	run := func() *Results {
+		tr := &Tournament{
+			Game:  ConnectFour{},
+			Start: ConnectFourBoard{},
+			Entrants: []Entrant{
+				{"random", &RandomAgent{}},
+				{"mcts", &MCTSAgent{MCTS: &MCTS{Iterations: 50}}},
+			},
+			Rounds: 2,
+			Seed:   7,
+		}
+		return tr.Run()
+	}
+	a, b := run(), run()
+	if !reflect.DeepEqual(a.Games, b.Games) || !reflect.DeepEqual(a.Elo, b.Elo) {
+		t.Error("runs with the same seed differ")
+	}
+}
+
+func TestTournamentMaxMoves(t *testing.T) {
+	tr := &Tournament{
+		Game:     ConnectFour{},
+		Start:    ConnectFourBoard{},
+		Entrants: []Entrant{{"a", &RandomAgent{}}, {"b", &RandomAgent{}}},
+		MaxMoves: 4,
+	}
+	for _, rec := range tr.Run().Games {
+		if rec.Winner != -1 || rec.Moves != 4 {
+			t.Errorf("game not cut short: %+v", rec)
+		}
+	}
+}
+
+func TestHumanAgent(t *testing.T) {
+	out := &bytes.Buffer{}
+	h := &HumanAgent{In: strings.NewReader("z9\nb2\n"), Out: out}
+	if a := h.Move(TicTacToe{}, board("X........")); a.Name != "b2" {
+		t.Errorf("unexpected move %v", a.Name)
+	}
+	if strings.Count(out.String(), "Your move") != 2 {
+		t.Errorf("illegal move not asked again:\n%s", out)
+	}
+	if a := h.Move(TicTacToe{}, board("X........")); a.Name != "b1" {
+		t.Errorf("unexpected move at end of input: %v", a.Name)
+	}
+}
+
+func TestResultsOutput(t *testing.T) {
+	tr := &Tournament{
+		Game:     TicTacToe{},
+		Start:    TicTacToeBoard{},
+		Entrants: []Entrant{{"a", &RandomAgent{}}, {"b", MinimaxAgent{}}},
+	}
+	res := tr.Run()
+	var table, games bytes.Buffer
+	if err := res.Print(&table); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(table.String(), "W/D/L") || !strings.Contains(table.String(), res.Cross[0][1].String()) {
+		t.Errorf("unexpected table:\n%s", &table)
+	}
+	if err := res.WriteGamesCSV(&games); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(games.String()), "\n"); len(lines) != 3 || lines[0] != "first,second,winner,moves,seed" {
+		t.Errorf("unexpected games CSV:\n%s", &games)
+	}
+}
@@ -0,0 +1,125 @@
+package game
+
+import (
+	"math/rand"
+	"unsafe"
+)
+
+// Hasher may be implemented by a Game to give each state a hash key,
+// typically built with Zobrist. AlphaBeta only uses its Table for games
+// that implement Hasher.
+type Hasher interface {
+	Hash(s State) uint64
+}
+
+// Zobrist holds random keys for Zobrist hashing. A position's hash is the
+// XOR of the keys of its features, e.g. (square, piece) pairs, so making
+// a move updates the hash by XORing out old features and XORing in new ones.
+type Zobrist struct {
+	keys [][]uint64
+}
+
+// NewZobrist returns keys for features numbered 0 to features-1,
+// each taking values numbered 0 to values-1.
+func NewZobrist(features, values int, seed int64) *Zobrist {
+	r := rand.New(rand.NewSource(seed))
+	z := &Zobrist{keys: make([][]uint64, features)}
+	for f := range z.keys {
+		z.keys[f] = make([]uint64, values)
+		for v := range z.keys[f] {
+			z.keys[f][v] = r.Uint64()
+		}
+	}
+	return z
+}
+
+// Key returns the key for feature f having value v.
+func (z *Zobrist) Key(f, v int) uint64 {
+	return z.keys[f][v]
+}
+
+// Bound says how a stored value relates to the true value of a state.
+type Bound uint8
+
+// Bounds of a transposition table Entry.
+const (
+	Exact Bound = iota + 1 // the value is exact
+	Lower                  // the true value is at least the value (a beta cutoff)
+	Upper                  // the true value is at most the value (an alpha cutoff)
+)
+
+// Entry is a search result stored in a TranspositionTable.
+type Entry struct {
+	Key   uint64
+	Value float64
+	Move  int // ID of the best action found, or -1
+	Depth int // remaining search depth the value is good for
+	Bound Bound
+}
+
+type ttSlot struct {
+	key   uint64
+	value float64
+	move  int32
+	depth int32
+	bound Bound
+	gen   uint8
+}
+
+// TranspositionTable is a fixed-size hash table of search results.
+// When two states map to the same slot the entry searched deeper is
+// kept, unless it is left over from an earlier search.
+type TranspositionTable struct {
+	slots []ttSlot
+	mask  uint64
+	gen   uint8
+}
+
+// NewTranspositionTable returns a table that uses at most bytes of memory.
+// The number of slots is rounded down to a power of two.
+func NewTranspositionTable(bytes int) *TranspositionTable {
+	n := uint64(1)
+	for (n*2)*uint64(unsafe.Sizeof(ttSlot{})) <= uint64(bytes) {
+		n *= 2
+	}
+	return &TranspositionTable{slots: make([]ttSlot, n), mask: n - 1, gen: 1}
+}
+
+// Len returns the number of slots in the table.
+func (t *TranspositionTable) Len() int {
+	return len(t.slots)
+}
+
+// NewSearch marks existing entries as old, so they give way to entries
+// from the coming search even if they were searched deeper.
+func (t *TranspositionTable) NewSearch() {
+	t.gen++
+	if t.gen == 0 {
+		t.gen = 1
+	}
+}
+
+// Clear empties the table.
+func (t *TranspositionTable) Clear() {
+	for i := range t.slots {
+		t.slots[i] = ttSlot{}
+	}
+}
+
+// Probe returns the entry stored for key, if any.
+func (t *TranspositionTable) Probe(key uint64) (Entry, bool) {
+	s := &t.slots[key&t.mask]
+	if s.bound == 0 || s.key != key {
+		return Entry{}, false
+	}
+	return Entry{Key: s.key, Value: s.value, Move: int(s.move), Depth: int(s.depth), Bound: s.bound}, true
+}
+
+// Store saves e using depth-preferred replacement.
+func (t *TranspositionTable) Store(e Entry) {
+	s := &t.slots[e.Key&t.mask]
+	if s.bound != 0 && s.key != e.Key && s.gen == t.gen && int(s.depth) > e.Depth {
+		return
+	}
+	*s = ttSlot{key: e.Key, value: e.Value, move: int32(e.Move), depth: int32(e.Depth), bound: e.Bound, gen: t.gen}
+}
@@ -0,0 +1,110 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTranspositionTableSize(t *testing.T) {
+	tt := NewTranspositionTable(1 << 20)
+	if n := tt.Len(); n&(n-1) != 0 || n < 1<<14 || n > 1<<15 {
+		t.Errorf("unexpected number of slots: %d", n)
+	}
+	if NewTranspositionTable(0).Len() != 1 {
+		t.Error("a table must have at least one slot")
+	}
+}
+
+func TestTranspositionTableReplacement(t *testing.T) {
+	tt := NewTranspositionTable(0)
+	tt.Store(Entry{Key: 1, Value: 0.5, Move: 3, Depth: 4, Bound: Exact})
+	if e, ok := tt.Probe(1); !ok || e.Value != 0.5 || e.Move != 3 || e.Bound != Exact {
+		t.Fatalf("stored entry not found: %+v %v", e, ok)
+	}
+	if _, ok := tt.Probe(2); ok {
+		t.Error("found an entry for a key never stored")
+	}
+
+	tt.Store(Entry{Key: 2, Depth: 2, Bound: Lower})
+	if _, ok := tt.Probe(1); !ok {
+		t.Error("deeper entry replaced by a shallower one")
+	}
+	tt.Store(Entry{Key: 1, Value: -1, Depth: 1, Bound: Upper})
+	if e, _ := tt.Probe(1); e.Value != -1 {
+		t.Error("entry for the same key not replaced")
+	}
+
+	tt.NewSearch()
+	tt.Store(Entry{Key: 2, Depth: 0, Bound: Lower})
+	if _, ok := tt.Probe(2); !ok {
+		t.Error("entry from an earlier search not replaced")
+	}
+	tt.Clear()
+	if _, ok := tt.Probe(2); ok {
+		t.Error("table not cleared")
+	}
+}
+
+func TestZobristHashIsOrderIndependent(t *testing.T) {
+	g := TicTacToe{}
+	a := g.Result(g.Result(g.Result(TicTacToeBoard{}, TicTacToeAction(0)), TicTacToeAction(4)), TicTacToeAction(8))
+	b := g.Result(g.Result(g.Result(TicTacToeBoard{}, TicTacToeAction(8)), TicTacToeAction(4)), TicTacToeAction(0))
+	if g.Hash(a) != g.Hash(b) {
+		t.Error("transposed positions hash differently")
+	}
+	if g.Hash(a) == g.Hash(g.Result(a, TicTacToeAction(1))) {
+		t.Error("different positions hash alike")
+	}
+}
+
+func TestAlphaBetaWithTable(t *testing.T) {
+	g := TicTacToe{}
+	positions := []TicTacToeBoard{
+		{},
+		board("X........"),
+		board("X...O...."),
+		board("XO..X...."),
+		board("OX.XO...."),
+	}
+	tt := NewTranspositionTable(1 << 16)
+	for i, s := range positions {
+		plain := &AlphaBeta{}
+		_, pv := plain.Search(g, s)
+		ab := &AlphaBeta{Table: tt}
+		a, v := ab.Search(g, s)
+		if v != pv {
+			t.Errorf("case %d: value %v with table, %v without", i, v, pv)
+		}
+		if got := minimaxValue(g, g.Result(s, a), g.Player(s)); got != pv {
+			t.Errorf("case %d: move %v is worth %v, not %v", i, a.Name, got, pv)
+		}
+		if i == 0 && ab.Nodes >= plain.Nodes {
+			t.Errorf("table search visited %d nodes, plain %d", ab.Nodes, plain.Nodes)
+		}
+	}
+}
+
+func TestIterativeDeepeningWithTable(t *testing.T) {
+	g := TicTacToe{}
+	plain := &AlphaBeta{Eval: g.Eval}
+	_, _, pd := plain.IterativeDeepening(g, TicTacToeBoard{}, time.Minute)
+	ab := &AlphaBeta{Eval: g.Eval, Table: NewTranspositionTable(1 << 16)}
+	_, v, depth := ab.IterativeDeepening(g, TicTacToeBoard{}, time.Minute)
+	if v != 0 || depth != pd {
+		t.Errorf("unexpected value %v at depth %d, want 0 at %d", v, depth, pd)
+	}
+	if ab.Nodes >= plain.Nodes {
+		t.Errorf("table search visited %d nodes, plain %d", ab.Nodes, plain.Nodes)
+	}
+
+	// entries left by earlier searches must not change later results.
+	s := board("X...O....")
+	for _, d := range []int{2, 4, 0} {
+		ab.MaxDepth = d
+		plain.MaxDepth = d
+		_, v := ab.Search(g, s)
+		if _, pv := plain.Search(g, s); v != pv {
+			t.Errorf("depth %d: value %v with table, %v without", d, v, pv)
+		}
+	}
+}
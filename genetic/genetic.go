@@ -0,0 +1,349 @@
+// Package genetic -- given a population of candidate solutions.
+// Breed fitter candidates by selection, crossover and mutation
+// over many generations.
+package genetic
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// Genome is the genetic material of one candidate solution.
+type Genome interface {
+	// Crossover combines the receiver with other to make a child.
+	Crossover(other Genome, r *rand.Rand) Genome
+	// Mutate returns a copy where each gene has changed with probability rate.
+	Mutate(rate float64, r *rand.Rand) Genome
+}
+
+// Lener may be implemented by a Genome whose crossover needs both parents
+// to have the same length, as that of Bits, Perm and Reals does. Run
+// checks that such genomes all have the same Len.
+type Lener interface {
+	Len() int
+}
+
+// Fitnesser is an interface for a fitness function that calls Fitness.
+// Higher is fitter. Fitness is called from several goroutines at once
+// so it must be safe for concurrent use.
+type Fitnesser interface {
+	Fitness(g Genome) float64
+}
+
+// Individual is a Genome together with its fitness.
+type Individual struct {
+	Genome  Genome
+	Fitness float64
+}
+
+// Selection picks a parent from pop, which is sorted fittest first.
+type Selection func(pop []Individual, r *rand.Rand) Individual
+
+// Tournament returns a Selection that picks the fittest of k
+// individuals drawn at random.
+func Tournament(k int) Selection {
+	return func(pop []Individual, r *rand.Rand) Individual {
+		best := pop[r.Intn(len(pop))]
+		for i := 1; i < k; i++ {
+			if c := pop[r.Intn(len(pop))]; c.Fitness > best.Fitness {
+				best = c
+			}
+		}
+		return best
+	}
+}
+
+// Roulette returns a Selection that picks an individual with probability
+// proportional to its fitness, after shifting fitnesses so the least fit
+// scores 0.
+func Roulette() Selection {
+	return roulette
+}
+
+func roulette(pop []Individual, r *rand.Rand) Individual {
+	min := pop[len(pop)-1].Fitness
+	total := 0.0
+	for _, ind := range pop {
+		total += ind.Fitness - min
+	}
+	if total == 0 {
+		return pop[r.Intn(len(pop))]
+	}
+	spin := r.Float64() * total
+	for _, ind := range pop {
+		spin -= ind.Fitness - min
+		if spin < 0 {
+			return ind
+		}
+	}
+	return pop[len(pop)-1]
+}
+
+// Stats summarises one generation.
+type Stats struct {
+	Generation        int
+	Best, Mean, Worst float64
+	BestGenome        Genome
+}
+
+// Config controls a run of the genetic algorithm.
+type Config struct {
+	PopulationSize int // 0 means 100
+	Generations    int
+	CrossoverRate  float64   // probability a child is bred rather than cloned
+	MutationRate   float64   // per-gene mutation probability
+	Elitism        int       // number of fittest individuals copied unchanged
+	Selection      Selection // nil means Tournament(2)
+	Workers        int       // goroutines used to evaluate fitness, at least 1
+	Seed           int64     // runs with the same seed give the same result
+
+	// OnGeneration, if set, is called with the statistics of every
+	// generation, including the initial population as generation 0.
+	OnGeneration func(Stats)
+}
+
+// Run evolves a population created by newGenome and returns the fittest
+// individual ever seen. It returns an error if newGenome makes Leners of
+// different lengths, which could not be crossed.
+func Run(newGenome func(r *rand.Rand) Genome, f Fitnesser, c Config) (Individual, error) {
+	if c.Selection == nil {
+		c.Selection = Tournament(2)
+	}
+	if c.Workers < 1 {
+		c.Workers = 1
+	}
+	if c.PopulationSize < 1 {
+		c.PopulationSize = 100
+	}
+	rnd := rand.New(rand.NewSource(c.Seed))
+
+	genomes := make([]Genome, c.PopulationSize)
+	for i := range genomes {
+		genomes[i] = newGenome(rnd)
+		if err := sameLen(genomes[0], genomes[i]); err != nil {
+			return Individual{}, err
+		}
+	}
+	pop := evaluate(genomes, f, c.Workers)
+	best := pop[0]
+	report(c, 0, pop)
+
+	for gen := 1; gen <= c.Generations; gen++ {
+		next := []Genome{}
+		for i := 0; i < c.Elitism && i < len(pop); i++ {
+			next = append(next, pop[i].Genome)
+		}
+		for len(next) < c.PopulationSize {
+			a := c.Selection(pop, rnd)
+			child := a.Genome
+			if rnd.Float64() < c.CrossoverRate {
+				b := c.Selection(pop, rnd)
+				child = a.Genome.Crossover(b.Genome, rnd)
+			}
+			next = append(next, child.Mutate(c.MutationRate, rnd))
+		}
+		pop = evaluate(next, f, c.Workers)
+		if pop[0].Fitness > best.Fitness {
+			best = pop[0]
+		}
+		report(c, gen, pop)
+	}
+	return best, nil
+}
+
+// sameLen returns an error if a and b are Leners of different lengths.
+func sameLen(a, b Genome) error {
+	la, ok := a.(Lener)
+	lb, ok2 := b.(Lener)
+	if ok && ok2 && la.Len() != lb.Len() {
+		return fmt.Errorf("genomes of lengths %d and %d cannot be crossed", la.Len(), lb.Len())
+	}
+	return nil
+}
+
+// evaluate scores genomes on workers goroutines and returns them
+// sorted fittest first.
+func evaluate(genomes []Genome, f Fitnesser, workers int) []Individual {
+	pop := make([]Individual, len(genomes))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				pop[i] = Individual{Genome: genomes[i], Fitness: f.Fitness(genomes[i])}
+			}
+		}()
+	}
+	for i := range genomes {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	sort.SliceStable(pop, func(i, j int) bool { return pop[i].Fitness > pop[j].Fitness })
+	return pop
+}
+
+func report(c Config, gen int, pop []Individual) {
+	if c.OnGeneration == nil || len(pop) == 0 {
+		return
+	}
+	sum := 0.0
+	for _, ind := range pop {
+		sum += ind.Fitness
+	}
+	c.OnGeneration(Stats{
+		Generation: gen,
+		Best:       pop[0].Fitness,
+		Mean:       sum / float64(len(pop)),
+		Worst:      pop[len(pop)-1].Fitness,
+		BestGenome: pop[0].Genome,
+	})
+}
+
+// Bits is a bitstring genome. Crossover is single-point and
+// mutation flips bits. Parents must have the same length.
+type Bits []bool
+
+// Len returns the number of bits.
+func (b Bits) Len() int {
+	return len(b)
+}
+
+// RandomBits returns n random bits.
+func RandomBits(n int, r *rand.Rand) Bits {
+	b := make(Bits, n)
+	for i := range b {
+		b[i] = r.Intn(2) == 1
+	}
+	return b
+}
+
+// Crossover performs single-point crossover.
+func (b Bits) Crossover(other Genome, r *rand.Rand) Genome {
+	o := other.(Bits)
+	cut := r.Intn(len(b) + 1)
+	child := make(Bits, len(b))
+	copy(child, b[:cut])
+	copy(child[cut:], o[cut:])
+	return child
+}
+
+// Mutate flips each bit with probability rate.
+func (b Bits) Mutate(rate float64, r *rand.Rand) Genome {
+	m := append(Bits{}, b...)
+	for i := range m {
+		if r.Float64() < rate {
+			m[i] = !m[i]
+		}
+	}
+	return m
+}
+
+// Perm is a permutation genome, e.g. a tour of cities.
+// Crossover is order crossover (OX1) and mutation swaps positions,
+// so children are always valid permutations. Parents must have the
+// same length.
+type Perm []int
+
+// Len returns the number of elements permuted.
+func (p Perm) Len() int {
+	return len(p)
+}
+
+// RandomPerm returns a random permutation of 0..n-1.
+func RandomPerm(n int, r *rand.Rand) Perm {
+	return Perm(r.Perm(n))
+}
+
+// Crossover performs order crossover: a slice of the receiver is kept in
+// place and the remaining positions are filled in the order they appear
+// in other.
+func (p Perm) Crossover(other Genome, r *rand.Rand) Genome {
+	o := other.(Perm)
+	n := len(p)
+	if n == 0 {
+		return Perm{}
+	}
+	i, j := r.Intn(n+1), r.Intn(n+1)
+	if i > j {
+		i, j = j, i
+	}
+	child := make(Perm, n)
+	used := map[int]bool{}
+	for k := i; k < j; k++ {
+		child[k] = p[k]
+		used[p[k]] = true
+	}
+	pos := j % n
+	for k := 0; k < n && len(used) < n; k++ {
+		g := o[(j+k)%n]
+		if used[g] {
+			continue
+		}
+		child[pos] = g
+		used[g] = true
+		pos = (pos + 1) % n
+	}
+	return child
+}
+
+// Mutate swaps each position with a random other position with probability rate.
+func (p Perm) Mutate(rate float64, r *rand.Rand) Genome {
+	m := append(Perm{}, p...)
+	for i := range m {
+		if r.Float64() < rate {
+			j := r.Intn(len(m))
+			m[i], m[j] = m[j], m[i]
+		}
+	}
+	return m
+}
+
+// Reals is a real-valued vector genome. Crossover blends the parents
+// and mutation adds Gaussian noise with standard deviation Sigma.
+// Parents must have the same number of genes.
+type Reals struct {
+	Genes []float64
+	Sigma float64
+}
+
+// Len returns the number of genes.
+func (v Reals) Len() int {
+	return len(v.Genes)
+}
+
+// RandomReals returns n reals drawn uniformly from [min, max).
+func RandomReals(n int, min, max, sigma float64, r *rand.Rand) Reals {
+	g := make([]float64, n)
+	for i := range g {
+		g[i] = min + r.Float64()*(max-min)
+	}
+	return Reals{Genes: g, Sigma: sigma}
+}
+
+// Crossover performs arithmetic crossover with a random blend weight.
+func (v Reals) Crossover(other Genome, r *rand.Rand) Genome {
+	o := other.(Reals)
+	w := r.Float64()
+	g := make([]float64, len(v.Genes))
+	for i := range g {
+		g[i] = w*v.Genes[i] + (1-w)*o.Genes[i]
+	}
+	return Reals{Genes: g, Sigma: v.Sigma}
+}
+
+// Mutate adds Gaussian noise to each gene with probability rate.
+func (v Reals) Mutate(rate float64, r *rand.Rand) Genome {
+	g := append([]float64{}, v.Genes...)
+	for i := range g {
+		if r.Float64() < rate {
+			g[i] += r.NormFloat64() * v.Sigma
+		}
+	}
+	return Reals{Genes: g, Sigma: v.Sigma}
+}
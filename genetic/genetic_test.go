@@ -0,0 +1,154 @@
+package genetic
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+type oneMax struct{}
+
+func (oneMax) Fitness(g Genome) float64 {
+	n := 0.0
+	for _, b := range g.(Bits) {
+		if b {
+			n++
+		}
+	}
+	return n
+}
+
+func ExampleRun() {
+	c := Config{
+		PopulationSize: 50,
+		Generations:    60,
+		CrossoverRate:  0.9,
+		MutationRate:   0.02,
+		Elitism:        2,
+		Selection:      Tournament(3),
+		Workers:        4,
+		Seed:           1,
+	}
+	best, _ := Run(func(r *rand.Rand) Genome { return RandomBits(32, r) }, oneMax{}, c)
+	fmt.Println(best.Fitness)
+	// Output:
+	// 32
+}
+
+func TestRunDeterministicAcrossWorkers(t *testing.T) {
+	run := func(workers int) []Stats {
+		ss := []Stats{}
+		c := Config{
+			PopulationSize: 30,
+			Generations:    20,
+			CrossoverRate:  0.8,
+			MutationRate:   0.05,
+			Elitism:        1,
+			Selection:      Roulette(),
+			Workers:        workers,
+			Seed:           9,
+			OnGeneration:   func(s Stats) { ss = append(ss, s) },
+		}
+		if _, err := Run(func(r *rand.Rand) Genome { return RandomBits(20, r) }, oneMax{}, c); err != nil {
+			t.Fatal(err)
+		}
+		return ss
+	}
+	a, b := run(1), run(8)
+	if len(a) != 21 {
+		t.Errorf("unexpected number of generations reported: %d", len(a))
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Error("results depend on number of workers")
+	}
+	for i := 1; i < len(a); i++ {
+		if a[i].Best < a[i-1].Best {
+			t.Errorf("generation %d: elitism lost best individual", i)
+		}
+		if a[i].Worst > a[i].Mean || a[i].Mean > a[i].Best {
+			t.Errorf("generation %d: inconsistent stats %v", i, a[i])
+		}
+	}
+}
+
+// sortedness rewards permutations that are close to 0, 1, 2, ...
+type sortedness struct{}
+
+func (sortedness) Fitness(g Genome) float64 {
+	n := 0.0
+	for i, v := range g.(Perm) {
+		if i == v {
+			n++
+		}
+	}
+	return n
+}
+
+func TestPermCrossoverIsPermutation(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		a, b := RandomPerm(10, r), RandomPerm(10, r)
+		c := a.Crossover(b, r).(Perm).Mutate(0.3, r).(Perm)
+		s := append([]int{}, c...)
+		sort.Ints(s)
+		for j, v := range s {
+			if j != v {
+				t.Fatalf("not a permutation: %v", c)
+			}
+		}
+	}
+
+	c := Config{PopulationSize: 60, Generations: 200, CrossoverRate: 0.7, MutationRate: 0.05, Elitism: 2, Seed: 3}
+	best, _ := Run(func(r *rand.Rand) Genome { return RandomPerm(8, r) }, sortedness{}, c)
+	if best.Fitness != 8 {
+		t.Errorf("unexpected fitness: %v", best)
+	}
+}
+
+// sphere is highest at the origin.
+type sphere struct{}
+
+func (sphere) Fitness(g Genome) float64 {
+	sum := 0.0
+	for _, x := range g.(Reals).Genes {
+		sum += x * x
+	}
+	return -sum
+}
+
+func TestRealsConverge(t *testing.T) {
+	c := Config{PopulationSize: 40, Generations: 100, CrossoverRate: 0.9, MutationRate: 0.2, Elitism: 2, Seed: 5, Workers: 2}
+	best, _ := Run(func(r *rand.Rand) Genome { return RandomReals(3, -5, 5, 0.1, r) }, sphere{}, c)
+	if best.Fitness < -0.01 {
+		t.Errorf("unexpected fitness: %v", best.Fitness)
+	}
+}
+
+func TestPermCrossoverEmpty(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	if c := (Perm{}).Crossover(Perm{}, r).(Perm); len(c) != 0 {
+		t.Errorf("unexpected child: %v", c)
+	}
+}
+
+func TestRunDefaultPopulation(t *testing.T) {
+	n := 0
+	c := Config{Generations: 1, OnGeneration: func(s Stats) { n++ }}
+	best, _ := Run(func(r *rand.Rand) Genome { return RandomBits(8, r) }, oneMax{}, c)
+	if best.Genome == nil || n != 2 {
+		t.Errorf("unexpected result: %v after %d generations", best, n)
+	}
+}
+
+func TestRunUnequalLengths(t *testing.T) {
+	n := 0
+	newGenome := func(r *rand.Rand) Genome {
+		n++
+		return RandomBits(8+n%2, r)
+	}
+	if _, err := Run(newGenome, oneMax{}, Config{PopulationSize: 4, Generations: 1}); err == nil {
+		t.Error("expected error for genomes of different lengths")
+	}
+}
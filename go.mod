@@ -2,4 +2,7 @@ module github.com/siuyin/ai
 
 go 1.14
 
-require golang.org/x/tools v0.0.0-20200417140056-c07e33ef3290 // indirect
+require (
+	golang.org/x/tools v0.0.0-20200417140056-c07e33ef3290 // indirect
+	gopkg.in/yaml.v2 v2.4.0
+)
@@ -0,0 +1,291 @@
+package know
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Relation is a set of Allen's thirteen basic relations between two
+// intervals, read as their disjunction: a {Before, Meets} b means a
+// ends before or just as b starts. The empty Relation is a contradiction.
+type Relation uint16
+
+// Basic relations of interval a to interval b.
+const (
+	Before       Relation = 1 << iota // a ends before b starts
+	Meets                             // a ends as b starts
+	Overlaps                          // a starts first, and ends during b
+	Starts                            // a starts with b, and ends first
+	During                            // a starts after b, and ends before it
+	Finishes                          // a ends with b, and starts after it
+	Equals                            // a and b start and end together
+	FinishedBy                        // the inverse of Finishes
+	Contains                          // the inverse of During
+	StartedBy                         // the inverse of Starts
+	OverlappedBy                      // the inverse of Overlaps
+	MetBy                             // the inverse of Meets
+	After                             // the inverse of Before
+
+	// Any is every basic relation: nothing is known.
+	Any Relation = 1<<13 - 1
+)
+
+var relationNames = []string{"before", "meets", "overlaps", "starts", "during", "finishes", "equals",
+	"finished-by", "contains", "started-by", "overlapped-by", "met-by", "after"}
+
+// String lists the basic relations in r, e.g. "{before meets}".
+func (r Relation) String() string {
+	nn := []string{}
+	for i, n := range relationNames {
+		if r&(1<<uint(i)) != 0 {
+			nn = append(nn, n)
+		}
+	}
+	return "{" + strings.Join(nn, " ") + "}"
+}
+
+// Inverse returns the relation of b to a when a r b.
+func (r Relation) Inverse() Relation {
+	inv := Relation(0)
+	for i := uint(0); i < 13; i++ {
+		if r&(1<<i) != 0 {
+			inv |= 1 << (12 - i)
+		}
+	}
+	return inv
+}
+
+// Compose returns the relation of a to c known from a r b and b s c.
+func (r Relation) Compose(s Relation) Relation {
+	c := Relation(0)
+	for i := uint(0); i < 13; i++ {
+		if r&(1<<i) == 0 {
+			continue
+		}
+		for j := uint(0); j < 13; j++ {
+			if s&(1<<j) != 0 {
+				c |= composition[i][j]
+			}
+		}
+	}
+	return c
+}
+
+// Relate returns the basic relation of interval a, from aStart to aEnd,
+// to interval b. Intervals must start before they end.
+func Relate(aStart, aEnd, bStart, bEnd float64) Relation {
+	switch {
+	case aEnd < bStart:
+		return Before
+	case aEnd == bStart:
+		return Meets
+	case bEnd < aStart:
+		return After
+	case bEnd == aStart:
+		return MetBy
+	}
+	switch start, end := cmp(aStart, bStart), cmp(aEnd, bEnd); {
+	case start == 0 && end == 0:
+		return Equals
+	case start == 0:
+		if end < 0 {
+			return Starts
+		}
+		return StartedBy
+	case end == 0:
+		if start > 0 {
+			return Finishes
+		}
+		return FinishedBy
+	case start < 0 && end < 0:
+		return Overlaps
+	case start > 0 && end > 0:
+		return OverlappedBy
+	case start > 0:
+		return During
+	}
+	return Contains
+}
+
+func cmp(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// composition[i][j] is the composition of basic relations 1<<i and 1<<j.
+// Rather than copy Allen's table, it is found by placing three intervals
+// with endpoints among six points in every way.
+var composition = func() [13][13]Relation {
+	var t [13][13]Relation
+	index := func(r Relation) int {
+		for i := 0; i < 13; i++ {
+			if r == 1<<uint(i) {
+				return i
+			}
+		}
+		panic(fmt.Sprintf("not a basic relation: %d", r))
+	}
+	type span struct{ s, e float64 }
+	spans := []span{}
+	for s := 0; s < 6; s++ {
+		for e := s + 1; e < 6; e++ {
+			spans = append(spans, span{float64(s), float64(e)})
+		}
+	}
+	for _, a := range spans {
+		for _, b := range spans {
+			ab := index(Relate(a.s, a.e, b.s, b.e))
+			for _, c := range spans {
+				bc := index(Relate(b.s, b.e, c.s, c.e))
+				t[ab][bc] |= Relate(a.s, a.e, c.s, c.e)
+			}
+		}
+	}
+	return t
+}()
+
+// Interval identifies an interval of an IntervalNetwork. Intervals are
+// numbered from 0 in the order they are added.
+type Interval int
+
+// IntervalNetwork is a network of qualitative constraints between
+// intervals, each a Relation, as in "breakfast is before or meets work".
+type IntervalNetwork struct {
+	Names []string
+	rel   [][]Relation // rel[a][b] is the relation of a to b
+}
+
+// NewIntervalNetwork returns a network with no intervals.
+func NewIntervalNetwork() *IntervalNetwork {
+	return &IntervalNetwork{}
+}
+
+// AddInterval adds an interval, unconstrained, and returns it.
+func (n *IntervalNetwork) AddInterval(name string) Interval {
+	for a := range n.rel {
+		n.rel[a] = append(n.rel[a], Any)
+	}
+	row := make([]Relation, len(n.rel)+1)
+	for b := range row {
+		row[b] = Any
+	}
+	row[len(n.rel)] = Equals
+	n.rel = append(n.rel, row)
+	n.Names = append(n.Names, name)
+	return Interval(len(n.rel) - 1)
+}
+
+// Constrain requires a r b, in addition to any earlier constraint on a and b.
+func (n *IntervalNetwork) Constrain(a, b Interval, r Relation) {
+	n.rel[a][b] &= r
+	n.rel[b][a] &= r.Inverse()
+}
+
+// Relation returns the relation known of a to b.
+func (n *IntervalNetwork) Relation(a, b Interval) Relation {
+	return n.rel[a][b]
+}
+
+// PathConsistency tightens the relation of every pair of intervals by
+// composing it with their relations to each third interval, until
+// nothing changes. It returns false if a relation becomes empty, in
+// which case the network is inconsistent. Path consistency does not
+// detect every inconsistency; Scenario does.
+//
+// Allen's algorithm, with a queue of the pairs whose relation changed:
+//
+//	while queue is not empty
+//	    (i, j) := REMOVE-FIRST(queue)
+//	    for each k other than i and j
+//	        R(i,k) := R(i,k) ∩ R(i,j)∘R(j,k), enqueue (i,k) if changed
+//	        R(k,j) := R(k,j) ∩ R(k,i)∘R(i,j), enqueue (k,j) if changed
+func (n *IntervalNetwork) PathConsistency() bool {
+	type pair struct{ i, j Interval }
+	queue := []pair{}
+	for i := range n.rel {
+		for j := range n.rel {
+			if i != j {
+				queue = append(queue, pair{Interval(i), Interval(j)})
+			}
+		}
+	}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		i, j := p.i, p.j
+		for k := range n.rel {
+			k := Interval(k)
+			if k == i || k == j {
+				continue
+			}
+			if r := n.rel[i][k] & n.rel[i][j].Compose(n.rel[j][k]); r != n.rel[i][k] {
+				if r == 0 {
+					return false
+				}
+				n.rel[i][k], n.rel[k][i] = r, r.Inverse()
+				queue = append(queue, pair{i, k})
+			}
+			if r := n.rel[k][j] & n.rel[k][i].Compose(n.rel[i][j]); r != n.rel[k][j] {
+				if r == 0 {
+					return false
+				}
+				n.rel[k][j], n.rel[j][k] = r, r.Inverse()
+				queue = append(queue, pair{k, j})
+			}
+		}
+	}
+	return true
+}
+
+// Scenario returns a consistent refinement of the network with a single
+// basic relation between every pair of intervals, or false if there is
+// none and the network is inconsistent. It backtracks over the basic
+// relations of each pair in turn, keeping the network path consistent,
+// which is enough to decide consistency once every relation is basic.
+// n is left unchanged.
+func (n *IntervalNetwork) Scenario() (*IntervalNetwork, bool) {
+	m := n.clone()
+	if !m.PathConsistency() {
+		return nil, false
+	}
+	return m.refine()
+}
+
+func (n *IntervalNetwork) refine() (*IntervalNetwork, bool) {
+	for i := range n.rel {
+		for j := i + 1; j < len(n.rel); j++ {
+			r := n.rel[i][j]
+			if r&(r-1) == 0 {
+				continue
+			}
+			for b := Relation(1); b <= r; b <<= 1 {
+				if r&b == 0 {
+					continue
+				}
+				m := n.clone()
+				m.Constrain(Interval(i), Interval(j), b)
+				if !m.PathConsistency() {
+					continue
+				}
+				if s, ok := m.refine(); ok {
+					return s, true
+				}
+			}
+			return nil, false
+		}
+	}
+	return n, true
+}
+
+func (n *IntervalNetwork) clone() *IntervalNetwork {
+	m := &IntervalNetwork{Names: n.Names, rel: make([][]Relation, len(n.rel))}
+	for a, row := range n.rel {
+		m.rel[a] = append([]Relation{}, row...)
+	}
+	return m
+}
@@ -0,0 +1,101 @@
+package know
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func ExampleIntervalNetwork() {
+	// breakfast is before or meets work, work overlaps or is during the
+	// meeting, and lunch comes after the meeting has started.
+	n := NewIntervalNetwork()
+	breakfast, work, meeting := n.AddInterval("breakfast"), n.AddInterval("work"), n.AddInterval("meeting")
+	n.Constrain(breakfast, work, Before|Meets)
+	n.Constrain(work, meeting, Overlaps|During)
+	fmt.Println(n.PathConsistency())
+	fmt.Println(n.Relation(breakfast, meeting))
+	fmt.Println(n.Relation(meeting, breakfast))
+	// Output:
+	// true
+	// {before meets overlaps starts during}
+	// {contains started-by overlapped-by met-by after}
+}
+
+func TestCompose(t *testing.T) {
+	cases := []struct{ r, s, want Relation }{
+		{Before, Before, Before},
+		{Meets, Meets, Before},
+		{Meets, During, Overlaps | Starts | During},
+		{Overlaps, Overlaps, Before | Meets | Overlaps},
+		{During, During, During},
+		{Equals, Starts, Starts},
+		{Before, After, Any},
+		{Starts, StartedBy, Starts | StartedBy | Equals},
+	}
+	for _, c := range cases {
+		if got := c.r.Compose(c.s); got != c.want {
+			t.Errorf("%v∘%v = %v, want %v", c.r, c.s, got, c.want)
+		}
+	}
+	for i := uint(0); i < 13; i++ {
+		for j := uint(0); j < 13; j++ {
+			r, s := Relation(1)<<i, Relation(1)<<j
+			if r.Compose(s).Inverse() != s.Inverse().Compose(r.Inverse()) {
+				t.Errorf("inverse of %v∘%v is not %v∘%v", r, s, s.Inverse(), r.Inverse())
+			}
+		}
+		if r := Relation(1) << i; r.Inverse().Inverse() != r || r.Compose(Equals) != r {
+			t.Errorf("%v has inverse %v", r, r.Inverse())
+		}
+	}
+}
+
+func TestPathConsistencyFindsCycle(t *testing.T) {
+	n := NewIntervalNetwork()
+	a, b, c := n.AddInterval("a"), n.AddInterval("b"), n.AddInterval("c")
+	n.Constrain(a, b, Before|Meets)
+	n.Constrain(b, c, Before|Meets)
+	n.Constrain(c, a, Before|Overlaps)
+	if n.PathConsistency() {
+		t.Error("cycle of intervals path consistent")
+	}
+	if _, ok := n.Scenario(); ok {
+		t.Error("cycle of intervals has a scenario")
+	}
+}
+
+func TestScenario(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		// hidden intervals, related loosely, must leave a scenario.
+		k := 3 + r.Intn(5)
+		n := NewIntervalNetwork()
+		starts, ends := []float64{}, []float64{}
+		for j := 0; j < k; j++ {
+			n.AddInterval(fmt.Sprint("i", j))
+			s := float64(r.Intn(10))
+			starts, ends = append(starts, s), append(ends, s+1+float64(r.Intn(5)))
+		}
+		for a := 0; a < k; a++ {
+			for b := a + 1; b < k; b++ {
+				if r.Intn(2) == 0 {
+					rel := Relate(starts[a], ends[a], starts[b], ends[b])
+					n.Constrain(Interval(a), Interval(b), rel|Relation(r.Intn(int(Any))))
+				}
+			}
+		}
+		s, ok := n.Scenario()
+		if !ok {
+			t.Fatalf("network %d has no scenario", i)
+		}
+		for a := 0; a < k; a++ {
+			for b := 0; b < k; b++ {
+				rel := s.Relation(Interval(a), Interval(b))
+				if rel == 0 || rel&(rel-1) != 0 || rel&n.Relation(Interval(a), Interval(b)) == 0 {
+					t.Errorf("network %d: %d to %d is %v in scenario, %v in network", i, a, b, rel, n.Relation(Interval(a), Interval(b)))
+				}
+			}
+		}
+	}
+}
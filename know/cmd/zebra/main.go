@@ -1,71 +1,38 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
-	"reflect"
-	"runtime"
-	"strings"
 
+	"github.com/siuyin/ai/csp"
 	k "github.com/siuyin/ai/know"
 )
 
+var solver = flag.String("solver", "prop", "solve with propositional logic (prop) or a constraint solver (csp)")
+
 func main() {
+	flag.Parse()
 	fmt.Println("Who owns the zebra and who drinks water?")
-	//syms := allSymbols()
-	//kb := addKnowledge()
-	//ents := findEntailments(kb, syms)
-	//for i, ent := range ents {
-	//	fmt.Printf("%d: %s\n", i, ent)
-	//}
-}
-
-func and(p symSet, q symSet) symSet {
-	r := symSet{}
-	for ep := range p {
-		if in(q, ep) {
-			r[ep] = struct{}{}
-		}
-	}
-	return r
-}
-func in(p symSet, e k.Prop) bool {
-	_, ok := p[e]
-	if ok {
-		return true
-	}
-	return false
-}
-func allSymbols() symSet {
-	var mAllSymbols map[k.Prop]struct{}
-	if mAllSymbols == nil {
-		mAllSymbols = map[k.Prop]struct{}{}
+
+	solve := solveProp
+	switch *solver {
+	case "prop":
+	case "csp":
+		solve = solveCSP
+	default:
+		log.Fatalf("unknown solver %q", *solver)
 	}
-	if len(mAllSymbols) != 0 {
-		return mAllSymbols
+	zebra, water, err := solve()
+	if err != nil {
+		log.Fatal(err)
 	}
-	syms := map[k.Prop]struct{}{}
-	for _, ha := range houseAddress() {
-		for _, c := range houseColours() {
-			for _, n := range nationalities() {
-				for _, cig := range cigBrands() {
-					for _, d := range drinks() {
-						for _, p := range pets() {
-							syms[k.Sym(fmt.Sprintf("%s %s %s %s %s %s", ha, c, n, cig, d, p))] = struct{}{}
-						}
-					}
-				}
-			}
-		}
-	}
-	mAllSymbols = syms
-	return syms // 5^6 = 15625 symbols!
+	fmt.Printf("The %s owns the zebra.\n", zebra)
+	fmt.Printf("The %s drinks water.\n", water)
 }
 
-type symSet map[k.Prop]struct{}
-
-func houseAddress() []string {
-	return []string{"1", "2", "3", "4", "5"}
+func houseAddress() []int {
+	return []int{1, 2, 3, 4, 5}
 }
 func houseColours() []string {
 	return []string{"Yellow", "Blue", "Red", "Green", "Ivory"}
@@ -83,124 +50,185 @@ func pets() []string {
 	return []string{"fox", "horse", "snails", "zebra", "dog"}
 }
 
-func addKnowledge() k.Prop {
-	kb := k.And()
-
-	kb = kb.Add(is("English", "Red"))
-	kb = kb.Add(is("Spanish", "dog"))
-	kb = kb.Add(is("coffee", "Green"))
-	kb = kb.Add(is("Old Gold", "snails"))
-	kb = kb.Add(is("Kools", "Yellow"))
-	kb = kb.Add(is("milk", "3"))
-	kb = kb.Add(is("Norwegian", "1"))
-	kb = kb.Add(is("Lucky Strike", "orange juice"))
-	kb = kb.Add(is("Japanese", "Parl"))
-	return kb
+// attributes are grouped so that each house has exactly one of every group.
+func attributes() [][]string {
+	return [][]string{houseColours(), nationalities(), cigBrands(), drinks(), pets()}
 }
 
-// func is("English","Red") and not non("English","Red")
-func is(s ...string) k.Prop {
-	r := k.Or()
-	//er := symbols(contains, "English", "Red")
-	er := symbols(contains, s...)
-	for sym := range er {
-		r.Add(k.Implication(sym, k.Not(allSymbolsMinus(sym))))
-	}
-	return r
-}
-func copySet(s symSet) symSet {
-	o := symSet{}
-	for e := range s {
-		o[e] = struct{}{}
-	}
-	return o
-}
+type relation int
 
-func allSymbolsMinus(e k.Prop) k.Prop {
-	cpy := copySet(allSymbols())
-	delete(cpy, e)
+const (
+	same    relation = iota // a and b belong to the same house
+	nextTo                  // a's house is next to b's
+	rightOf                 // a's house is immediately right of b's
+	inHouse                 // a belongs to house number house
+)
 
-	ret := k.Or()
-	for f := range cpy {
-		ret.Add(f)
+type clue struct {
+	rel   relation
+	a, b  string
+	house int
+}
+
+func clues() []clue {
+	return []clue{
+		{rel: same, a: "English", b: "Red"},
+		{rel: same, a: "Spanish", b: "dog"},
+		{rel: same, a: "coffee", b: "Green"},
+		{rel: same, a: "Ukranian", b: "tea"},
+		{rel: rightOf, a: "Green", b: "Ivory"},
+		{rel: same, a: "Old Gold", b: "snails"},
+		{rel: same, a: "Kools", b: "Yellow"},
+		{rel: inHouse, a: "milk", house: 3},
+		{rel: inHouse, a: "Norwegian", house: 1},
+		{rel: nextTo, a: "Chester", b: "fox"},
+		{rel: nextTo, a: "Kools", b: "horse"},
+		{rel: same, a: "Lucky Strike", b: "orange juice"},
+		{rel: same, a: "Japanese", b: "Parl"},
+		{rel: nextTo, a: "Norwegian", b: "Blue"},
 	}
-	return ret
 }
 
-func contains(s string, args ...string) bool {
-	for _, a := range args {
-		if !strings.Contains(s, a) {
-			return false
+// solveCSP makes each attribute a variable whose value is the number
+// of its house.
+func solveCSP() (zebra, water string, err error) {
+	p := csp.NewProblem()
+	vars := map[string]csp.Var{}
+	for _, group := range attributes() {
+		vv := []csp.Var{}
+		for _, a := range group {
+			vars[a] = p.AddVar(a, houseAddress()...)
+			vv = append(vv, vars[a])
 		}
+		p.AllDifferent(vv...)
 	}
-	return true
-}
-func doesNotContain(s string, args ...string) bool {
-	for _, a := range args {
-		if strings.Contains(s, a) {
-			return false
+	for _, c := range clues() {
+		c := c
+		switch c.rel {
+		case same:
+			p.Equal(vars[c.a], vars[c.b])
+		case nextTo:
+			p.Binary(vars[c.a], vars[c.b], func(a, b int) bool { return a-b == 1 || b-a == 1 })
+		case rightOf:
+			p.Binary(vars[c.a], vars[c.b], func(a, b int) bool { return a == b+1 })
+		case inHouse:
+			p.Unary(vars[c.a], func(a int) bool { return a == c.house })
 		}
 	}
-	return true
-}
-func symbols(fn symFil, args ...string) symSet {
-	var memo map[string]symSet
-	if memo == nil {
-		memo = make(map[string]symSet)
-	}
 
-	key, val, found := findMemo(memo, fn, args...)
-	if found {
-		return val
+	sol, err := (&csp.Solver{}).Solve(p)
+	if err != nil {
+		return "", "", err
 	}
-	memo, syms := filter(memo, key, fn, args...)
-	return syms
-}
-
-type symFil func(s string, args ...string) bool
-
-func findMemo(memo map[string]symSet, fn symFil, args ...string) (string, symSet, bool) {
-	key := fmt.Sprint(funcName(fn), args)
-	if v, ok := memo[key]; ok {
-		return key, v, true
+	for _, n := range nationalities() {
+		if sol[vars[n]] == sol[vars["zebra"]] {
+			zebra = n
+		}
+		if sol[vars[n]] == sol[vars["water"]] {
+			water = n
+		}
 	}
-	return "", symSet{}, false
-}
-func funcName(i interface{}) string {
-	return runtime.FuncForPC(
-		reflect.ValueOf(i).Pointer(),
-	).Name()
-}
-func filter(memo map[string]symSet, key string, fn symFil, args ...string) (map[string]symSet, symSet) {
-	syms := symSet{}
-	for sym := range allSymbols() {
-		if fn(sym.String(), args...) {
-			syms[sym] = struct{}{}
+	return zebra, water, nil
+}
+
+// in is the symbol for attribute a belonging to house h.
+func in(a string, h int) k.Prop {
+	return k.Sym(fmt.Sprintf("%s %d", a, h))
+}
+
+// solveProp encodes the puzzle with a symbol for each attribute and
+// house, 125 in all, and asks which nationality's house is entailed to
+// hold the zebra, and the water.
+func solveProp() (zebra, water string, err error) {
+	kb := addKnowledge()
+	for _, n := range nationalities() {
+		for _, q := range []struct {
+			thing string
+			who   *string
+		}{{"zebra", &zebra}, {"water", &water}} {
+			ok, err := k.DPLL(kb, sameHouse(n, q.thing))
+			if err != nil {
+				return "", "", err
+			}
+			if ok {
+				*q.who = n
+			}
 		}
 	}
-	memo[key] = syms
-	return memo, syms
+	if zebra == "" || water == "" {
+		return "", "", fmt.Errorf("knowledge base could not answer")
+	}
+	return zebra, water, nil
 }
 
-func findEntailments(kb k.Prop, symbs map[k.Prop]struct{}) []k.Prop {
-	ents := []k.Prop{}
-	for sym := range symbs {
-		val, err := k.ModelCheck(kb, sym)
-		if err != nil {
-			log.Fatalf("findEntailments: %v", err)
+func addKnowledge() k.Prop {
+	kb := k.And()
+	for _, group := range attributes() {
+		for _, a := range group {
+			pp := []k.Prop{} // a is in one house
+			for _, h := range houseAddress() {
+				pp = append(pp, in(a, h))
+			}
+			kb = kb.Add(oneOf(pp))
 		}
-		if val {
-			ents = append(ents, sym)
+		for _, h := range houseAddress() {
+			pp := []k.Prop{} // house h has one of the group
+			for _, a := range group {
+				pp = append(pp, in(a, h))
+			}
+			kb = kb.Add(oneOf(pp))
 		}
 	}
-	return ents
+	for _, c := range clues() {
+		switch c.rel {
+		case same:
+			kb = kb.Add(sameHouse(c.a, c.b))
+		case nextTo:
+			for _, h := range houseAddress() {
+				kb = kb.Add(k.Implication(in(c.a, h), k.Or(neighbours(c.b, h)...)))
+			}
+		case rightOf:
+			for _, h := range houseAddress() {
+				left := k.Prop(k.Or())
+				if h > 1 {
+					left = in(c.b, h-1)
+				}
+				kb = kb.Add(k.Implication(in(c.a, h), left))
+			}
+		case inHouse:
+			kb = kb.Add(in(c.a, c.house))
+		}
+	}
+	return kb
 }
 
-type house struct {
-	pos    int
-	colour string
+// sameHouse says a and b are in the same house.
+func sameHouse(a, b string) k.Prop {
+	r := k.Or()
+	for _, h := range houseAddress() {
+		r = r.Add(k.And(in(a, h), in(b, h)))
+	}
+	return r
 }
 
-func (h house) String() string {
-	return fmt.Sprintf("House%d:%s", h.pos, h.colour)
+// neighbours returns the symbols for a being in a house next to house h.
+func neighbours(a string, h int) []k.Prop {
+	pp := []k.Prop{}
+	for _, n := range []int{h - 1, h + 1} {
+		if n >= 1 && n <= len(houseAddress()) {
+			pp = append(pp, in(a, n))
+		}
+	}
+	return pp
+}
+
+// oneOf says exactly one of pp is true: at least one, and no two.
+func oneOf(pp []k.Prop) k.Prop {
+	r := k.And(k.Or(pp...))
+	for i, p := range pp {
+		for _, q := range pp[i+1:] {
+			r = r.Add(k.Not(k.And(p, q)))
+		}
+	}
+	return r
 }
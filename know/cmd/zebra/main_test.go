@@ -0,0 +1,18 @@
+package main
+
+import "testing"
+
+func TestSolvers(t *testing.T) {
+	for name, solve := range map[string]func() (string, string, error){
+		"prop": solveProp,
+		"csp":  solveCSP,
+	} {
+		zebra, water, err := solve()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if zebra != "Japanese" || water != "Norwegian" {
+			t.Errorf("%s: %s owns the zebra and %s drinks water", name, zebra, water)
+		}
+	}
+}
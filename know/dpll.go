@@ -0,0 +1,356 @@
+package know
+
+import (
+	"fmt"
+)
+
+// clause is a disjunction of literals. A literal is the index, from 1, of
+// a symbol, negated if the symbol appears negated.
+type clause []int
+
+// cnf is a conjunction of clauses over symbols, which are numbered by
+// the order they are first met. Symbols introduced by disjunction stand
+// for no Prop and are nil in symbols.
+type cnf struct {
+	clauses []clause
+	index   map[Prop]int
+	symbols []Prop
+}
+
+// add converts p, or its negation if neg is true, to clauses and adds them.
+func (f *cnf) add(p Prop, neg bool) error {
+	cc, err := f.convert(p, neg)
+	if err != nil {
+		return err
+	}
+	f.clauses = append(f.clauses, cc...)
+	return nil
+}
+
+// convert returns the clauses of p, or of its negation if neg is true.
+// Negations are pushed inwards to the symbols, implications and
+// biconditionals are rewritten with and, or and not, and disjunctions
+// are given new symbols as described at disjunction.
+func (f *cnf) convert(p Prop, neg bool) ([]clause, error) {
+	switch q := p.(type) {
+	case symbol:
+		i, ok := f.index[q]
+		if !ok {
+			f.symbols = append(f.symbols, q)
+			i = len(f.symbols)
+			f.index[q] = i
+		}
+		if neg {
+			return []clause{{-i}}, nil
+		}
+		return []clause{{i}}, nil
+	case not:
+		return f.convert(q.Operand, !neg)
+	case and:
+		if neg {
+			return f.disjunction(q.Conjuncts, true)
+		}
+		return f.conjunction(q.Conjuncts, false)
+	case or:
+		if neg {
+			return f.conjunction(q.Disjuncts, true)
+		}
+		return f.disjunction(q.Disjuncts, false)
+	case implication:
+		return f.convert(Or(Not(q.A), q.B), neg)
+	case biconditional:
+		return f.convert(And(Or(Not(q.A), q.B), Or(q.A, Not(q.B))), neg)
+	}
+	return nil, fmt.Errorf("cannot convert %T to clauses", p)
+}
+
+// conjunction returns the clauses of the conjunction of pp, each negated if neg.
+func (f *cnf) conjunction(pp []Prop, neg bool) ([]clause, error) {
+	cc := []clause{}
+	for _, p := range pp {
+		pc, err := f.convert(p, neg)
+		if err != nil {
+			return nil, err
+		}
+		cc = append(cc, pc...)
+	}
+	return cc, nil
+}
+
+// disjunction returns the clauses of the disjunction of pp, each negated
+// if neg. Distributing or over and would multiply the clauses of the
+// disjuncts together, which grows exponentially, so, as in the Tseitin
+// transformation, a disjunct of several clauses is replaced by a new
+// symbol x and the clauses (not x or c) for each of its clauses c. The
+// result is satisfiable exactly when the disjunction is, and its size
+// grows only linearly.
+func (f *cnf) disjunction(pp []Prop, neg bool) ([]clause, error) {
+	if len(pp) == 1 {
+		return f.convert(pp[0], neg)
+	}
+	cc := []clause{}
+	c := clause{}
+	always := false
+	for _, p := range pp {
+		pc, err := f.convert(p, neg)
+		if err != nil {
+			return nil, err
+		}
+		switch len(pc) {
+		case 0:
+			always = true
+		case 1:
+			c = append(c, pc[0]...)
+		default:
+			f.symbols = append(f.symbols, nil)
+			x := len(f.symbols)
+			for _, d := range pc {
+				cc = append(cc, append(clause{-x}, d...))
+			}
+			c = append(c, x)
+		}
+	}
+	if always {
+		return []clause{}, nil
+	}
+	return append(cc, c), nil
+}
+
+// Satisfiable reports whether some model makes p true, and returns one
+// if so. It converts p to conjunctive normal form and searches with DPLL,
+// so it copes with far more symbols than ModelCheck.
+//
+// Pseudocode from Russell and Norvig, AIMA 3rd ed. figure 7.17:
+//
+//	function DPLL(clauses, symbols, model) returns true or false
+//	    if every clause in clauses is true in model then return true
+//	    if some clause in clauses is false in model then return false
+//	    P, value := FIND-PURE-SYMBOL(symbols, clauses, model)
+//	    if P is non-null then return DPLL(clauses, symbols - P, model ∪ {P=value})
+//	    P, value := FIND-UNIT-CLAUSE(clauses, model)
+//	    if P is non-null then return DPLL(clauses, symbols - P, model ∪ {P=value})
+//	    P := FIRST(symbols); rest := REST(symbols)
+//	    return DPLL(clauses, rest, model ∪ {P=true}) or
+//	           DPLL(clauses, rest, model ∪ {P=false})
+//
+// Pure symbols are not looked for. Unit clauses are propagated, and
+// the symbol chosen for a branch is one from the unsatisfied clause with
+// fewest symbols unassigned.
+func Satisfiable(p Prop) (symbolSet, bool, error) {
+	f := &cnf{index: map[Prop]int{}}
+	if err := f.add(p, false); err != nil {
+		return nil, false, err
+	}
+	return f.solve()
+}
+
+// DPLL reports whether knowledge entails query, as ModelCheck does, by
+// showing that knowledge and not query cannot both be true.
+func DPLL(knowledge, query Prop) (bool, error) {
+	f := &cnf{index: map[Prop]int{}}
+	if err := f.add(knowledge, false); err != nil {
+		return false, err
+	}
+	if err := f.add(query, true); err != nil {
+		return false, err
+	}
+	_, sat, err := f.solve()
+	return !sat, err
+}
+
+func (f *cnf) solve() (symbolSet, bool, error) {
+	d := newDPLL(f.clauses, len(f.symbols))
+	if !d.search() {
+		return nil, false, nil
+	}
+	model := symbolSet{}
+	for i, s := range f.symbols {
+		if s != nil {
+			model[s] = d.values[i+1] > 0
+		}
+	}
+	return model, true, nil
+}
+
+// dpllState tracks, for each clause, how many of its literals are true
+// and how many false, so that assigning a symbol only visits the clauses
+// it occurs in. Assignments are kept on a trail to be undone on backtracking.
+type dpllState struct {
+	clauses []clause
+	occurs  [][]int // clauses containing each literal, see occ
+	values  []int8  // 1 true, -1 false, 0 unassigned; indexed by symbol
+	nTrue   []int
+	nFalse  []int
+	trail   []int // literals made true, in order
+	units   []int // literals waiting to be made true
+}
+
+func newDPLL(cc []clause, symbols int) *dpllState {
+	d := &dpllState{
+		occurs: make([][]int, 2*symbols+2),
+		values: make([]int8, symbols+1),
+	}
+	for _, c := range cc {
+		if c = simplify(c); c != nil {
+			d.clauses = append(d.clauses, c)
+		}
+	}
+	d.nTrue = make([]int, len(d.clauses))
+	d.nFalse = make([]int, len(d.clauses))
+	for i, c := range d.clauses {
+		for _, l := range c {
+			d.occurs[occ(l)] = append(d.occurs[occ(l)], i)
+		}
+		if len(c) == 1 {
+			d.units = append(d.units, c[0])
+		}
+	}
+	return d
+}
+
+// simplify removes repeated literals from c, and returns nil if c is
+// always true because it holds a literal and its negation.
+func simplify(c clause) clause {
+	out := clause{}
+	for _, l := range c {
+		dup := false
+		for _, m := range out {
+			if m == -l {
+				return nil
+			}
+			dup = dup || m == l
+		}
+		if !dup {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+func occ(l int) int {
+	if l < 0 {
+		return -2 * l
+	}
+	return 2*l + 1
+}
+
+// search extends the assignment to satisfy every clause, reporting
+// whether it could. It branches on a symbol of the unsatisfied clause
+// with the fewest unassigned literals.
+func (d *dpllState) search() bool {
+	mark := len(d.trail)
+	if !d.propagate() {
+		d.undo(mark)
+		return false
+	}
+	best, bestFree := -1, 0
+	for i, c := range d.clauses {
+		if d.nTrue[i] > 0 {
+			continue
+		}
+		if free := len(c) - d.nFalse[i]; best < 0 || free < bestFree {
+			best, bestFree = i, free
+		}
+	}
+	if best < 0 {
+		return true
+	}
+	if bestFree == 0 { // only an empty clause can be false yet unnoticed
+		d.undo(mark)
+		return false
+	}
+	lit := 0
+	for _, l := range d.clauses[best] {
+		if d.values[abs(l)] == 0 {
+			lit = l
+			break
+		}
+	}
+	for _, l := range []int{lit, -lit} {
+		d.units = append(d.units[:0], l)
+		if d.search() {
+			return true
+		}
+	}
+	d.undo(mark)
+	return false
+}
+
+// propagate makes the waiting unit literals true, and any that become
+// units in turn, returning false if a clause becomes false.
+func (d *dpllState) propagate() bool {
+	for len(d.units) > 0 {
+		l := d.units[len(d.units)-1]
+		d.units = d.units[:len(d.units)-1]
+		switch d.values[abs(l)] * sign(l) {
+		case 1:
+			continue
+		case -1:
+			d.units = d.units[:0]
+			return false
+		}
+		if !d.assign(l) {
+			d.units = d.units[:0]
+			return false
+		}
+	}
+	return true
+}
+
+// assign makes literal l true, queueing the literals of clauses left with
+// one unassigned literal. It returns false if a clause becomes false,
+// leaving the counts consistent for undo.
+func (d *dpllState) assign(l int) bool {
+	d.values[abs(l)] = sign(l)
+	d.trail = append(d.trail, l)
+	for _, i := range d.occurs[occ(l)] {
+		d.nTrue[i]++
+	}
+	ok := true
+	for _, i := range d.occurs[occ(-l)] {
+		d.nFalse[i]++
+		if d.nTrue[i] > 0 || !ok {
+			continue
+		}
+		switch len(d.clauses[i]) - d.nFalse[i] {
+		case 0:
+			ok = false
+		case 1:
+			for _, m := range d.clauses[i] {
+				if d.values[abs(m)] == 0 {
+					d.units = append(d.units, m)
+				}
+			}
+		}
+	}
+	return ok
+}
+
+// undo takes back the assignments made since the trail was mark long.
+func (d *dpllState) undo(mark int) {
+	for len(d.trail) > mark {
+		l := d.trail[len(d.trail)-1]
+		d.trail = d.trail[:len(d.trail)-1]
+		for _, i := range d.occurs[occ(l)] {
+			d.nTrue[i]--
+		}
+		for _, i := range d.occurs[occ(-l)] {
+			d.nFalse[i]--
+		}
+		d.values[abs(l)] = 0
+	}
+}
+
+func abs(l int) int {
+	if l < 0 {
+		return -l
+	}
+	return l
+}
+
+func sign(l int) int8 {
+	if l < 0 {
+		return -1
+	}
+	return 1
+}
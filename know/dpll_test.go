@@ -0,0 +1,170 @@
+package know
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDPLLAgreesWithModelCheck(t *testing.T) {
+	p, q, r := Sym("P"), Sym("Q"), Sym("R")
+	cases := []struct {
+		kb, query Prop
+	}{
+		{And(p, q), q},
+		{And(p, q), r},
+		{Or(p, q), p},
+		{And(Implication(p, q), p), q},
+		{And(Implication(p, q), Not(q)), Not(p)},
+		{And(Or(p, q), Not(p)), q},
+		{Biconditional(p, q), Implication(q, p)},
+		{Biconditional(p, And(q, r)), Or(Not(p), r)},
+		{And(Or(And(p, Not(q)), And(q, Not(p))), Implication(p, And(p, q))), q},
+		{Or(), p},
+		{And(), Or(p, Not(p))},
+	}
+	for i, c := range cases {
+		want, err := ModelCheck(c.kb, c.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := DPLL(c.kb, c.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("case %d: %v entails %v is %v, model checking says %v", i, c.kb, c.query, got, want)
+		}
+	}
+}
+
+func TestSatisfiable(t *testing.T) {
+	p, q := Sym("P"), Sym("Q")
+	kb := And(Or(p, q), Not(p))
+	model, ok, err := Satisfiable(kb)
+	if err != nil || !ok {
+		t.Fatalf("unexpected result: %v %v", ok, err)
+	}
+	if v, err := kb.Evaluate(model); err != nil || !v {
+		t.Errorf("model %v does not satisfy %v", model, kb)
+	}
+	if _, ok, _ := Satisfiable(And(p, Not(p))); ok {
+		t.Error("contradiction satisfied")
+	}
+	if _, _, err := Satisfiable(sentence{}); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestSatisfiableDisjunctionOfConjunctions(t *testing.T) {
+	// distributing or over and would give 2^20 clauses.
+	dd := []Prop{}
+	for i := 0; i < 20; i++ {
+		a, b := Sym(fmt.Sprint("A", i)), Sym(fmt.Sprint("B", i))
+		dd = append(dd, And(a, b))
+	}
+	kb := And(Or(dd...), Not(Sym("A0")))
+	f := &cnf{index: map[Prop]int{}}
+	if err := f.add(kb, false); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.clauses) > 3*20+2 {
+		t.Errorf("%d clauses", len(f.clauses))
+	}
+
+	model, ok, err := Satisfiable(kb)
+	if err != nil || !ok {
+		t.Fatalf("unexpected result: %v %v", ok, err)
+	}
+	if len(model) != 40 {
+		t.Errorf("model has %d symbols", len(model))
+	}
+	if v, err := kb.Evaluate(model); err != nil || !v {
+		t.Errorf("model %v does not satisfy %v", model, kb)
+	}
+	if ok, _ := DPLL(Or(dd...), Or(dd[1:]...)); ok {
+		t.Error("disjunction entails a shorter one")
+	}
+}
+
+func TestDPLLPigeonhole(t *testing.T) {
+	// n+1 pigeons cannot sit in n holes: far beyond ModelCheck's 2^30 models.
+	n := 5
+	in := func(p, h int) Prop { return Sym(fmt.Sprintf("p%dh%d", p, h)) }
+	kb := And()
+	for p := 0; p <= n; p++ {
+		somewhere := Or()
+		for h := 0; h < n; h++ {
+			somewhere = somewhere.Add(in(p, h))
+			for q := p + 1; q <= n; q++ {
+				kb = kb.Add(Not(And(in(p, h), in(q, h))))
+			}
+		}
+		kb = kb.Add(somewhere)
+	}
+	if _, ok, err := Satisfiable(kb); ok || err != nil {
+		t.Errorf("unexpected result: %v %v", ok, err)
+	}
+}
+
+// checkCounts reports counts of true and false literals in each clause
+// that do not match the values assigned.
+func checkCounts(t *testing.T, d *dpllState) {
+	t.Helper()
+	for i, c := range d.clauses {
+		nt, nf := 0, 0
+		for _, l := range c {
+			switch d.values[abs(l)] * sign(l) {
+			case 1:
+				nt++
+			case -1:
+				nf++
+			}
+		}
+		if d.nTrue[i] != nt || d.nFalse[i] != nf {
+			t.Errorf("clause %v: counted %d true %d false, want %d %d", c, d.nTrue[i], d.nFalse[i], nt, nf)
+		}
+	}
+}
+
+func TestDPLLPropagateUndo(t *testing.T) {
+	// A chain of implications 1 → 2 → 3 → 4 from the unit clause 1.
+	d := newDPLL([]clause{{1}, {-1, 2}, {-2, 3}, {-3, 4}}, 4)
+	if !d.propagate() {
+		t.Fatal("chain should propagate without conflict")
+	}
+	if got := fmt.Sprint(d.trail, d.values); got != "[1 2 3 4] [0 1 1 1 1]" {
+		t.Errorf("got trail and values %s", got)
+	}
+	checkCounts(t, d)
+	d.undo(1)
+	if got := fmt.Sprint(d.trail, d.values); got != "[1] [0 1 0 0 0]" {
+		t.Errorf("after undo got %s", got)
+	}
+	checkCounts(t, d)
+
+	// 1 forces both 2 and not 2: a conflict, fully undone.
+	d = newDPLL([]clause{{1, 3}, {-1, 2}, {-1, -2}}, 3)
+	d.units = []int{1}
+	if d.propagate() {
+		t.Fatal("expected a conflict")
+	}
+	if len(d.units) != 0 {
+		t.Errorf("units %v left after conflict", d.units)
+	}
+	checkCounts(t, d)
+	d.undo(0)
+	checkCounts(t, d)
+	if got := fmt.Sprint(d.values); got != "[0 0 0 0]" {
+		t.Errorf("after undo got %s", got)
+	}
+
+	// Search branches on 1 first, meets the conflict and backtracks to
+	// 1 false, which forces 3.
+	if !d.search() {
+		t.Fatal("satisfiable clauses reported unsatisfiable")
+	}
+	if d.values[1] != -1 || d.values[3] != 1 {
+		t.Errorf("got values %v", d.values)
+	}
+	checkCounts(t, d)
+}
@@ -0,0 +1,205 @@
+package know
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Point is a time point of an STN. Points are numbered from 1 in the
+// order they are added; Origin, numbered 0, is time zero.
+type Point int
+
+// Origin is the time point every STN starts with, at time zero.
+const Origin Point = 0
+
+// STN is a simple temporal network: time points, such as the start and
+// end of tasks, with constraints lo ≤ b - a ≤ hi on the time between
+// pairs of them, e.g. "a finishes 10 to 20 minutes before b starts".
+//
+// It is held as a distance graph, with an edge from a to b of length hi
+// and one from b to a of length -lo. The network is consistent when
+// the graph has no negative cycle, and the shortest path from a to b is
+// the latest b can be after a.
+type STN struct {
+	Names []string
+	d     [][]float64 // d[a][b] is the most b may be after a, +Inf if unbounded
+}
+
+// NewSTN returns a network holding only the Origin.
+func NewSTN() *STN {
+	return &STN{Names: []string{"origin"}, d: [][]float64{{0}}}
+}
+
+// AddPoint adds a time point, unconstrained, and returns it.
+func (n *STN) AddPoint(name string) Point {
+	for a := range n.d {
+		n.d[a] = append(n.d[a], math.Inf(1))
+	}
+	row := make([]float64, len(n.d)+1)
+	for b := range row {
+		row[b] = math.Inf(1)
+	}
+	row[len(n.d)] = 0
+	n.d = append(n.d, row)
+	n.Names = append(n.Names, name)
+	return Point(len(n.d) - 1)
+}
+
+// Name returns the name of p.
+func (n *STN) Name(p Point) string {
+	return n.Names[p]
+}
+
+// Constrain requires lo ≤ b - a ≤ hi, in addition to any earlier
+// constraint on a and b. Use math.Inf for an unbounded side.
+func (n *STN) Constrain(a, b Point, lo, hi float64) {
+	n.d[a][b] = math.Min(n.d[a][b], hi)
+	n.d[b][a] = math.Min(n.d[b][a], -lo)
+}
+
+// Bounds returns the least and greatest b - a allowed. After Minimize
+// they are the tightest bounds implied by all the constraints.
+func (n *STN) Bounds(a, b Point) (lo, hi float64) {
+	return -n.d[b][a], n.d[a][b]
+}
+
+// Minimize tightens every pair of points to the bounds implied by the
+// whole network, by finding all shortest paths with the Floyd–Warshall
+// algorithm. It returns false if the network is inconsistent, which is
+// when some point must come before itself.
+//
+// The result is the minimal network: every time allowed for a point,
+// relative to another, is part of some schedule meeting all the
+// constraints.
+//
+//	for k := range points
+//	    for i := range points
+//	        for j := range points
+//	            d[i][j] = min(d[i][j], d[i][k] + d[k][j])
+//	consistent := d[i][i] >= 0 for every i
+func (n *STN) Minimize() bool {
+	d := n.d
+	for k := range d {
+		for i := range d {
+			if math.IsInf(d[i][k], 1) {
+				continue
+			}
+			for j := range d {
+				if v := d[i][k] + d[k][j]; v < d[i][j] {
+					d[i][j] = v
+				}
+			}
+		}
+	}
+	for i := range d {
+		if d[i][i] < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Consistent reports whether some schedule meets every constraint.
+// It minimizes n.
+func (n *STN) Consistent() bool {
+	return n.Minimize()
+}
+
+// Dispatch returns a schedule, the time of each point indexed by Point,
+// built as it would be executed: points are given times in increasing
+// order, each chosen by pick from the window left by the points before
+// it. A nil pick chooses the earliest time.
+//
+// Because the minimal network implies every constraint directly,
+// updating the windows of the points left from the time just chosen is
+// enough to keep the rest of the schedule possible, with no search.
+// A point is ready when every point that must come before it has a
+// time, and its window closes at the earliest deadline of the points
+// left, which may not be made to wait past it.
+//
+// Execution starts at the Origin, so no point is given a time before it.
+func (n *STN) Dispatch(pick func(p Point, earliest, latest float64) float64) ([]float64, error) {
+	if !n.Minimize() {
+		return nil, fmt.Errorf("temporal network is inconsistent")
+	}
+	m := n.clone()
+	for p := range m.d {
+		m.Constrain(Origin, Point(p), 0, math.Inf(1))
+	}
+	if !m.Minimize() {
+		return nil, fmt.Errorf("temporal network needs a point before the origin")
+	}
+	d := m.d
+	times := make([]float64, len(d))
+	done := make([]bool, len(d))
+	lo, hi := make([]float64, len(d)), make([]float64, len(d))
+	for p := range d {
+		lo[p], hi[p] = -d[p][Origin], d[Origin][p]
+	}
+	done[Origin] = true
+	now := 0.0
+
+	left := []Point{}
+	for p := 1; p < len(d); p++ {
+		left = append(left, Point(p))
+	}
+	for len(left) > 0 {
+		sort.SliceStable(left, func(i, j int) bool { return lo[left[i]] < lo[left[j]] })
+		next, deadline := -1, math.Inf(1)
+		for i, p := range left {
+			deadline = math.Min(deadline, hi[p])
+			if next < 0 && m.ready(p, done) {
+				next = i
+			}
+		}
+		if next < 0 {
+			return nil, fmt.Errorf("no point ready to dispatch") // cannot happen in a consistent network
+		}
+		p := left[next]
+		earliest, latest := math.Max(lo[p], now), math.Min(hi[p], deadline)
+		t := earliest
+		if pick != nil {
+			t = math.Max(earliest, math.Min(latest, pick(p, earliest, latest)))
+		}
+		times[p], done[p], now = t, true, t
+		left = append(left[:next], left[next+1:]...)
+		for _, q := range left {
+			lo[q] = math.Max(lo[q], t-d[q][p])
+			hi[q] = math.Min(hi[q], t+d[p][q])
+		}
+	}
+	return times, nil
+}
+
+func (n *STN) clone() *STN {
+	m := &STN{Names: n.Names, d: make([][]float64, len(n.d))}
+	for a, row := range n.d {
+		m.d[a] = append([]float64{}, row...)
+	}
+	return m
+}
+
+// ready reports whether every point that must come strictly before p is done.
+func (n *STN) ready(p Point, done []bool) bool {
+	for q := range n.d {
+		if !done[q] && n.d[p][q] < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Satisfied reports whether times, indexed by Point, meet every
+// constraint of n and put the Origin at zero.
+func (n *STN) Satisfied(times []float64) bool {
+	const eps = 1e-9
+	for a := range n.d {
+		for b := range n.d {
+			if times[b]-times[a] > n.d[a][b]+eps {
+				return false
+			}
+		}
+	}
+	return math.Abs(times[Origin]) <= eps
+}
@@ -0,0 +1,134 @@
+package know
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func ExampleSTN() {
+	// John drives to work, 30 to 40 minutes, or takes the bus, 40 to 50;
+	// Fred drives, 20 to 30 minutes, or takes a carpool, 40 to 50. Here
+	// John drives and Fred takes the carpool. John left between 7:10 and
+	// 7:20, Fred got to work between 7:50 and 8:10 and John got there 10
+	// to 20 minutes after Fred left (Dechter, Meiri and Pearl 1991).
+	// Times are minutes after 7:00.
+	n := NewSTN()
+	johnLeaves, johnArrives := n.AddPoint("John leaves"), n.AddPoint("John arrives")
+	fredLeaves, fredArrives := n.AddPoint("Fred leaves"), n.AddPoint("Fred arrives")
+	n.Constrain(Origin, johnLeaves, 10, 20)
+	n.Constrain(johnLeaves, johnArrives, 30, 40)
+	n.Constrain(fredLeaves, fredArrives, 40, 50)
+	n.Constrain(Origin, fredArrives, 50, 70)
+	n.Constrain(fredLeaves, johnArrives, 10, 20)
+	fmt.Println(n.Minimize())
+	for p := johnLeaves; p <= fredArrives; p++ {
+		lo, hi := n.Bounds(Origin, p)
+		fmt.Printf("%s between %v and %v\n", n.Name(p), lo, hi)
+	}
+	times, err := n.Dispatch(nil)
+	fmt.Println(times, err)
+	// Output:
+	// true
+	// John leaves between 10 and 20
+	// John arrives between 40 and 50
+	// Fred leaves between 20 and 30
+	// Fred arrives between 60 and 70
+	// [0 10 40 20 60] <nil>
+}
+
+func TestSTNInconsistent(t *testing.T) {
+	n := NewSTN()
+	a, b, c := n.AddPoint("a"), n.AddPoint("b"), n.AddPoint("c")
+	n.Constrain(a, b, 10, 20)
+	n.Constrain(b, c, 10, 20)
+	n.Constrain(a, c, 0, 15)
+	if n.Consistent() {
+		t.Error("network consistent")
+	}
+	if _, err := n.Dispatch(nil); err == nil {
+		t.Error("expected error")
+	}
+
+	n = NewSTN()
+	a = n.AddPoint("a")
+	n.Constrain(a, Origin, 5, 10)
+	if !n.Consistent() {
+		t.Error("network inconsistent")
+	}
+	if _, err := n.Dispatch(nil); err == nil {
+		t.Error("dispatched a point before the origin")
+	}
+}
+
+// randomSTN returns a network of points with constraints between random
+// pairs, loose enough around a hidden schedule that it is consistent.
+func randomSTN(r *rand.Rand, points, constraints int) *STN {
+	n := NewSTN()
+	hidden := []float64{0}
+	for i := 1; i <= points; i++ {
+		n.AddPoint(fmt.Sprint("p", i))
+		hidden = append(hidden, float64(r.Intn(100)))
+	}
+	for i := 0; i < constraints; i++ {
+		a, b := Point(r.Intn(points+1)), Point(r.Intn(points+1))
+		diff := hidden[b] - hidden[a]
+		lo, hi := diff-float64(r.Intn(10)), diff+float64(r.Intn(10))
+		if r.Intn(4) == 0 {
+			hi = math.Inf(1)
+		}
+		n.Constrain(a, b, lo, hi)
+	}
+	return n
+}
+
+func TestSTNDispatchMeetsConstraints(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	picks := map[string]func(p Point, earliest, latest float64) float64{
+		"earliest": nil,
+		"latest":   func(p Point, earliest, latest float64) float64 { return latest },
+		"random": func(p Point, earliest, latest float64) float64 {
+			if math.IsInf(latest, 1) {
+				latest = earliest + 50
+			}
+			return earliest + r.Float64()*(latest-earliest)
+		},
+	}
+	for i := 0; i < 100; i++ {
+		n := randomSTN(r, 2+r.Intn(10), 5+r.Intn(30))
+		if !n.Consistent() {
+			t.Fatalf("network %d inconsistent", i)
+		}
+		for name, pick := range picks {
+			times, err := n.Dispatch(pick)
+			if err != nil || !n.Satisfied(times) {
+				t.Errorf("network %d, %s: schedule %v breaks constraints: %v", i, name, times, err)
+			}
+		}
+	}
+}
+
+func TestSTNMinimalBoundsAreReached(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for i := 0; i < 50; i++ {
+		n := randomSTN(r, 6, 15)
+		for p := Point(1); p < 7; p++ {
+			n.Constrain(Origin, p, 0, math.Inf(1)) // as the dispatcher requires
+		}
+		n.Minimize()
+		for p := Point(1); p < 7; p++ {
+			lo, hi := n.Bounds(Origin, p)
+			if math.IsInf(hi, 1) {
+				continue
+			}
+			for _, want := range []float64{lo, hi} {
+				m := n.clone()
+				m.Constrain(Origin, p, want, want)
+				if times, err := m.Dispatch(nil); err != nil || times[p] != want {
+					t.Errorf("network %d: %s cannot be at %v within [%v, %v]: %v", i, n.Name(p), want, lo, hi, err)
+				}
+			}
+		}
+	}
+}
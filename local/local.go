@@ -0,0 +1,208 @@
+// Package local -- given an optimisation problem where only the final
+// configuration matters and not the path taken to reach it.
+// Find a, preferably optimal, configuration by repeatedly moving
+// to a neighbouring configuration.
+package local
+
+import (
+	"math"
+	"math/rand"
+)
+
+// State is a complete configuration of the problem, e.g. a placement of
+// all eight queens or a full timetable. Its concrete type is chosen by
+// the problem and is opaque to the search.
+type State interface{}
+
+// Valuer is an interface for an objective function that calls Value.
+// Higher values are better.
+type Valuer interface {
+	Value(s State) float64
+}
+
+// Neighbourser is an interface for a neighbourhood model that calls Neighbours.
+// It plays the role search.NextStateter and search.Actionsner play for
+// path-finding: it says where the search can move from s.
+type Neighbourser interface {
+	Neighbours(s State) []State
+}
+
+// RandomNeighbourer may be implemented by a Neighbourser whose
+// neighbourhoods are too large to list. The stochastic searches use it
+// instead of picking from Neighbours.
+type RandomNeighbourer interface {
+	RandomNeighbour(s State, r *rand.Rand) State
+}
+
+// RandomStater is an interface for generating random starting
+// configurations that calls RandomState.
+type RandomStater interface {
+	RandomState(r *rand.Rand) State
+}
+
+// Result is what a local search reports.
+type Result struct {
+	State State   // best configuration found
+	Value float64 // its objective value
+	Steps int     // number of search steps taken
+}
+
+// HillClimb performs steepest-ascent hill climbing from s. It moves to
+// the best neighbour until no neighbour is better or maxSteps moves
+// have been made.
+func HillClimb(s State, v Valuer, nb Neighbourser, maxSteps int) Result {
+	cur, curV := s, v.Value(s)
+	r := Result{State: cur, Value: curV}
+	for r.Steps < maxSteps {
+		best, bestV, found := State(nil), curV, false
+		for _, n := range nb.Neighbours(cur) {
+			if nv := v.Value(n); nv > bestV {
+				best, bestV, found = n, nv, true
+			}
+		}
+		if !found {
+			break
+		}
+		cur, curV = best, bestV
+		r.Steps++
+	}
+	r.State, r.Value = cur, curV
+	return r
+}
+
+// StochasticHillClimb moves to a uniformly chosen uphill neighbour until
+// none is left or maxSteps moves have been made.
+// Runs with the same seed give the same result.
+func StochasticHillClimb(s State, v Valuer, nb Neighbourser, maxSteps int, seed int64) Result {
+	rnd := rand.New(rand.NewSource(seed))
+	cur, curV := s, v.Value(s)
+	r := Result{}
+	for r.Steps < maxSteps {
+		uphill := []State{}
+		values := []float64{}
+		for _, n := range nb.Neighbours(cur) {
+			if nv := v.Value(n); nv > curV {
+				uphill = append(uphill, n)
+				values = append(values, nv)
+			}
+		}
+		if len(uphill) == 0 {
+			break
+		}
+		i := rnd.Intn(len(uphill))
+		cur, curV = uphill[i], values[i]
+		r.Steps++
+	}
+	r.State, r.Value = cur, curV
+	return r
+}
+
+// RandomRestartHillClimb runs steepest-ascent hill climbing from
+// restarts random configurations and returns the best result.
+// It stops early if a configuration reaches target.
+// Runs with the same seed give the same result.
+func RandomRestartHillClimb(rs RandomStater, v Valuer, nb Neighbourser, restarts, maxSteps int, target float64, seed int64) Result {
+	rnd := rand.New(rand.NewSource(seed))
+	best := Result{Value: math.Inf(-1)}
+	steps := 0
+	for i := 0; i < restarts; i++ {
+		r := HillClimb(rs.RandomState(rnd), v, nb, maxSteps)
+		steps += r.Steps
+		if r.Value > best.Value {
+			best = r
+		}
+		if best.Value >= target {
+			break
+		}
+	}
+	best.Steps = steps
+	return best
+}
+
+// Schedule maps a time step, starting at 1, to a temperature.
+// Simulated annealing stops when the temperature reaches 0.
+type Schedule func(t int) float64
+
+// ExpSchedule cools geometrically from t0 by factor alpha each step,
+// stopping after limit steps.
+func ExpSchedule(t0, alpha float64, limit int) Schedule {
+	return func(t int) float64 {
+		if t > limit {
+			return 0
+		}
+		return t0 * math.Pow(alpha, float64(t))
+	}
+}
+
+// LinearSchedule cools linearly from t0 to 0 over limit steps.
+func LinearSchedule(t0 float64, limit int) Schedule {
+	return func(t int) float64 {
+		if t >= limit {
+			return 0
+		}
+		return t0 * float64(limit-t) / float64(limit)
+	}
+}
+
+// LogSchedule cools as c / log(1+t), stopping after limit steps.
+func LogSchedule(c float64, limit int) Schedule {
+	return func(t int) float64 {
+		if t > limit {
+			return 0
+		}
+		return c / math.Log(1+float64(t))
+	}
+}
+
+// SimulatedAnnealing searches from s, accepting downhill moves with a
+// probability that falls as the schedule cools. It returns the best
+// configuration seen, which may be better than the one it finished on.
+// Runs with the same seed give the same result.
+//
+// Pseudocode from Russell and Norvig, AIMA 3rd ed. figure 4.5:
+//
+//	function SIMULATED-ANNEALING(problem, schedule) returns a solution state
+//	    current := MAKE-NODE(problem.INITIAL-STATE)
+//	    for t = 1 to infinity do
+//	        T := schedule(t)
+//	        if T = 0 then return current
+//	        next := a randomly selected successor of current
+//	        ΔE := next.VALUE - current.VALUE
+//	        if ΔE > 0 then current := next
+//	        else current := next only with probability e^(ΔE/T)
+func SimulatedAnnealing(s State, v Valuer, nb Neighbourser, sched Schedule, seed int64) Result {
+	rnd := rand.New(rand.NewSource(seed))
+	cur, curV := s, v.Value(s)
+	best := Result{State: cur, Value: curV}
+	for t := 1; ; t++ {
+		temp := sched(t)
+		if temp <= 0 {
+			best.Steps = t - 1
+			return best
+		}
+		next, ok := randomNeighbour(cur, nb, rnd)
+		if !ok {
+			best.Steps = t - 1
+			return best
+		}
+		nextV := v.Value(next)
+		dE := nextV - curV
+		if dE > 0 || rnd.Float64() < math.Exp(dE/temp) {
+			cur, curV = next, nextV
+		}
+		if curV > best.Value {
+			best.State, best.Value = cur, curV
+		}
+	}
+}
+
+func randomNeighbour(s State, nb Neighbourser, rnd *rand.Rand) (State, bool) {
+	if rn, ok := nb.(RandomNeighbourer); ok {
+		return rn.RandomNeighbour(s, rnd), true
+	}
+	nn := nb.Neighbours(s)
+	if len(nn) == 0 {
+		return nil, false
+	}
+	return nn[rnd.Intn(len(nn))], true
+}
@@ -0,0 +1,110 @@
+package local
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// queens is the n-queens problem with one queen per column.
+// A State is a []int giving the row of the queen in each column.
+type queens int
+
+func (q queens) Value(s State) float64 {
+	rows := s.([]int)
+	attacks := 0
+	for i := range rows {
+		for j := i + 1; j < len(rows); j++ {
+			d := rows[i] - rows[j]
+			if d == 0 || d == j-i || d == i-j {
+				attacks++
+			}
+		}
+	}
+	return float64(-attacks)
+}
+
+func (q queens) Neighbours(s State) []State {
+	rows := s.([]int)
+	nn := []State{}
+	for col := range rows {
+		for row := 0; row < int(q); row++ {
+			if row == rows[col] {
+				continue
+			}
+			n := append([]int{}, rows...)
+			n[col] = row
+			nn = append(nn, n)
+		}
+	}
+	return nn
+}
+
+func (q queens) RandomState(r *rand.Rand) State {
+	rows := make([]int, int(q))
+	for i := range rows {
+		rows[i] = r.Intn(int(q))
+	}
+	return rows
+}
+
+func ExampleRandomRestartHillClimb() {
+	q := queens(8)
+	r := RandomRestartHillClimb(q, q, q, 100, 100, 0, 1)
+	fmt.Println(r.Value)
+	// Output:
+	// 0
+}
+
+func TestHillClimbStopsAtLocalMaximum(t *testing.T) {
+	q := queens(8)
+	r := HillClimb(q.RandomState(rand.New(rand.NewSource(3))), q, q, 100)
+	for _, n := range q.Neighbours(r.State) {
+		if q.Value(n) > r.Value {
+			t.Errorf("neighbour %v is better than %v", n, r.State)
+		}
+	}
+}
+
+func TestStochasticHillClimbDeterministic(t *testing.T) {
+	q := queens(8)
+	s := q.RandomState(rand.New(rand.NewSource(7)))
+	a := StochasticHillClimb(s, q, q, 100, 42)
+	b := StochasticHillClimb(s, q, q, 100, 42)
+	if fmt.Sprint(a) != fmt.Sprint(b) {
+		t.Errorf("same seed gave different results: %v and %v", a, b)
+	}
+	if a.Value < q.Value(s) {
+		t.Errorf("climbed downhill: %v < %v", a.Value, q.Value(s))
+	}
+}
+
+func TestSimulatedAnnealing(t *testing.T) {
+	q := queens(8)
+	s := q.RandomState(rand.New(rand.NewSource(7)))
+	for i, sched := range []Schedule{
+		ExpSchedule(2, 0.999, 20000),
+		LinearSchedule(2, 20000),
+		LogSchedule(1, 20000),
+	} {
+		a := SimulatedAnnealing(s, q, q, sched, 5)
+		b := SimulatedAnnealing(s, q, q, sched, 5)
+		if fmt.Sprint(a) != fmt.Sprint(b) {
+			t.Errorf("case %d: same seed gave different results: %v and %v", i, a, b)
+		}
+		if a.Value < -1 {
+			t.Errorf("case %d: unexpected value: %v", i, a.Value)
+		}
+	}
+}
+
+func TestSchedulesStop(t *testing.T) {
+	for i, sched := range []Schedule{ExpSchedule(1, 0.9, 10), LinearSchedule(1, 10), LogSchedule(1, 10)} {
+		if sched(1) <= 0 {
+			t.Errorf("case %d: schedule starts cold", i)
+		}
+		if sched(11) != 0 {
+			t.Errorf("case %d: schedule did not stop", i)
+		}
+	}
+}
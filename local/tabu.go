@@ -0,0 +1,89 @@
+package local
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Move is a step to a neighbouring configuration. Attribute identifies
+// what the move changed, e.g. "exam 3 left slot 2", and must be comparable.
+// Tabu search forbids moves with a recently used Attribute.
+type Move struct {
+	State     State
+	Attribute interface{}
+}
+
+// Moveser is an interface for a neighbourhood model that calls Moves.
+// It is Neighbourser with each neighbour labelled by its move attribute.
+type Moveser interface {
+	Moves(s State) []Move
+}
+
+// TabuSearch moves to the best neighbour that is not tabu, even when that
+// is downhill, for maxSteps steps. The attribute of every move taken is
+// tabu for the next tenure steps, unless taking it would beat the best
+// value seen so far (the aspiration criterion).
+// It stops early when no admissible move is left or the value reaches target.
+func TabuSearch(s State, v Valuer, m Moveser, tenure, maxSteps int, target float64) Result {
+	cur := s
+	best := Result{State: s, Value: v.Value(s)}
+	tabuUntil := map[interface{}]int{}
+	for step := 1; step <= maxSteps && best.Value < target; step++ {
+		var next Move
+		nextV, found := math.Inf(-1), false
+		for _, mv := range m.Moves(cur) {
+			nv := v.Value(mv.State)
+			tabu := tabuUntil[mv.Attribute] >= step
+			if tabu && nv <= best.Value {
+				continue
+			}
+			if nv > nextV {
+				next, nextV, found = mv, nv, true
+			}
+		}
+		if !found {
+			break
+		}
+		cur = next.State
+		tabuUntil[next.Attribute] = step + tenure
+		if nextV > best.Value {
+			best.State, best.Value = cur, nextV
+		}
+		best.Steps = step
+	}
+	return best
+}
+
+// Destroyer is an interface for the destroy half of large neighbourhood
+// search that calls Destroy. Destroy returns a copy of s with part of
+// the configuration removed.
+type Destroyer interface {
+	Destroy(s State, r *rand.Rand) State
+}
+
+// Repairer is an interface for the repair half of large neighbourhood
+// search that calls Repair. Repair completes a partial configuration.
+type Repairer interface {
+	Repair(s State, r *rand.Rand) State
+}
+
+// LargeNeighbourhoodSearch repeatedly destroys part of the current
+// configuration and repairs it, keeping the result unless it is worse.
+// It stops after maxSteps rounds or when the value reaches target.
+// Runs with the same seed give the same result.
+func LargeNeighbourhoodSearch(s State, v Valuer, d Destroyer, rp Repairer, maxSteps int, target float64, seed int64) Result {
+	rnd := rand.New(rand.NewSource(seed))
+	cur, curV := s, v.Value(s)
+	best := Result{State: cur, Value: curV}
+	for step := 1; step <= maxSteps && best.Value < target; step++ {
+		next := rp.Repair(d.Destroy(cur, rnd), rnd)
+		if nv := v.Value(next); nv >= curV {
+			cur, curV = next, nv
+		}
+		if curV > best.Value {
+			best.State, best.Value = cur, curV
+		}
+		best.Steps = step
+	}
+	return best
+}
@@ -0,0 +1,167 @@
+package local
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// timetable assigns exams to slots. Exams sharing a student conflict
+// and must not share a slot. A State is a []int of slots, -1 if unassigned.
+type timetable struct {
+	slots     int
+	conflicts [][2]int
+}
+
+// newTimetable builds a random timetable that is known to be solvable
+// by planting a slot for every exam and only adding conflicts between
+// exams in different planted slots.
+func newTimetable(exams, slots, conflicts int, seed int64) timetable {
+	r := rand.New(rand.NewSource(seed))
+	planted := make([]int, exams)
+	for i := range planted {
+		planted[i] = r.Intn(slots)
+	}
+	tt := timetable{slots: slots}
+	for len(tt.conflicts) < conflicts {
+		a, b := r.Intn(exams), r.Intn(exams)
+		if planted[a] != planted[b] {
+			tt.conflicts = append(tt.conflicts, [2]int{a, b})
+		}
+	}
+	return tt
+}
+
+func (tt timetable) Value(s State) float64 {
+	ss := s.([]int)
+	clashes := 0
+	for _, c := range tt.conflicts {
+		if ss[c[0]] == ss[c[1]] || ss[c[0]] < 0 || ss[c[1]] < 0 {
+			clashes++
+		}
+	}
+	return float64(-clashes)
+}
+
+func (tt timetable) Moves(s State) []Move {
+	ss := s.([]int)
+	mm := []Move{}
+	for e, slot := range ss {
+		for t := 0; t < tt.slots; t++ {
+			if t == slot {
+				continue
+			}
+			n := append([]int{}, ss...)
+			n[e] = t
+			mm = append(mm, Move{State: n, Attribute: [2]int{e, slot}})
+		}
+	}
+	return mm
+}
+
+func (tt timetable) Neighbours(s State) []State {
+	nn := []State{}
+	for _, m := range tt.Moves(s) {
+		nn = append(nn, m.State)
+	}
+	return nn
+}
+
+func (tt timetable) Destroy(s State, r *rand.Rand) State {
+	n := append([]int{}, s.([]int)...)
+	for i := 0; i < 4; i++ {
+		n[r.Intn(len(n))] = -1
+	}
+	return n
+}
+
+// Repair greedily puts each unassigned exam in its least conflicting slot.
+func (tt timetable) Repair(s State, r *rand.Rand) State {
+	n := append([]int{}, s.([]int)...)
+	for e := range n {
+		if n[e] >= 0 {
+			continue
+		}
+		bestSlot, bestClash := 0, len(tt.conflicts)+1
+		for _, t := range r.Perm(tt.slots) {
+			clash := 0
+			for _, c := range tt.conflicts {
+				if (c[0] == e && n[c[1]] == t) || (c[1] == e && n[c[0]] == t) {
+					clash++
+				}
+			}
+			if clash < bestClash {
+				bestSlot, bestClash = t, clash
+			}
+		}
+		n[e] = bestSlot
+	}
+	return n
+}
+
+func TestTabuSearchEscapesLocalMaxima(t *testing.T) {
+	tt := newTimetable(30, 4, 120, 1)
+	start := make([]int, 30)
+
+	hc := HillClimb(start, tt, tt, 1000)
+	ts := TabuSearch(start, tt, tt, 7, 1000, 0)
+	if ts.Value != 0 {
+		t.Errorf("tabu search left %v clashes", -ts.Value)
+	}
+	if ts.Value < hc.Value {
+		t.Errorf("tabu search %v worse than hill climbing %v", ts.Value, hc.Value)
+	}
+}
+
+// ridge is a line of states 0..3 whose moves are labelled by direction only,
+// so one step right makes every rightward step tabu.
+type ridge []float64
+
+func (r ridge) Value(s State) float64 {
+	return r[s.(int)]
+}
+
+func (r ridge) Moves(s State) []Move {
+	i := s.(int)
+	mm := []Move{}
+	if i > 0 {
+		mm = append(mm, Move{State: i - 1, Attribute: "left"})
+	}
+	if i < len(r)-1 {
+		mm = append(mm, Move{State: i + 1, Attribute: "right"})
+	}
+	return mm
+}
+
+func TestTabuSearchAspiration(t *testing.T) {
+	r := ridge{0, 1, 3, 10}
+	res := TabuSearch(1, r, r, 100, 2, 100)
+	if res.State != 3 || res.Value != 10 {
+		t.Errorf("aspiration did not override tabu move: %v", res)
+	}
+}
+
+func TestTabuSearchSteps(t *testing.T) {
+	r := ridge{0, 1, 3, 10}
+	if res := TabuSearch(3, r, r, 1, 5, 10); res.Steps != 0 {
+		t.Errorf("moved from a state at the target: %v", res)
+	}
+	if res := TabuSearch(1, r, r, 1, 5, 10); res.State != 3 || res.Steps != 2 {
+		t.Errorf("unexpected result: %v", res)
+	}
+	if res := TabuSearch(0, ridge{5}, ridge{5}, 1, 5, 10); res.Steps != 0 {
+		t.Errorf("counted a step with no move: %v", res)
+	}
+}
+
+func TestLargeNeighbourhoodSearch(t *testing.T) {
+	tt := newTimetable(30, 4, 120, 1)
+	start := make([]int, 30)
+	a := LargeNeighbourhoodSearch(start, tt, tt, tt, 2000, 0, 3)
+	b := LargeNeighbourhoodSearch(start, tt, tt, tt, 2000, 0, 3)
+	if a.Value != b.Value || a.Steps != b.Steps {
+		t.Errorf("same seed gave different results: %v and %v", a, b)
+	}
+	if a.Value != 0 {
+		t.Errorf("lns left %v clashes", -a.Value)
+	}
+}
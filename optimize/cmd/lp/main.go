@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/siuyin/ai/optimize"
+)
+
+var sparse = flag.Bool("sparse", false, "use the revised simplex method on sparse columns")
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), `usage: lp [flags] problem.lp|problem.mps
+
+Solves a linear program written in the CPLEX LP or free MPS format,
+chosen by the file extension, and prints the value of each variable and
+the shadow price of each row. See the testdata of package optimize
+for examples.
+
+flags:
+`)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	f, err := os.Open(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	parse := optimize.ParseLP
+	if strings.EqualFold(filepath.Ext(f.Name()), ".mps") {
+		parse = optimize.ParseMPS
+	}
+	p, err := parse(f)
+	f.Close()
+	if err != nil {
+		log.Fatalf("%s: %v", f.Name(), err)
+	}
+
+	s := &optimize.Simplex{Sparse: *sparse}
+	sol, err := s.Solve(p)
+	if err != nil {
+		log.Fatal(err)
+	}
+	report(os.Stdout, p, sol)
+	fmt.Printf("%d pivots\n", s.Iterations)
+}
+
+// report prints the objective, the variables and the rows, with the
+// slack of each row and its shadow price.
+func report(w io.Writer, p *optimize.Problem, sol *optimize.Solution) {
+	sense := "minimum"
+	if p.Maximize {
+		sense = "maximum"
+	}
+	fmt.Fprintf(w, "%s %.6g\n\n", sense, sol.Objective)
+	for j, v := range p.Vars {
+		fmt.Fprintf(w, "%-12s %12.6g\n", v.Name, sol.X[j])
+	}
+	fmt.Fprintf(w, "\n%-12s %12s %12s %12s\n", "row", "activity", "rhs", "dual")
+	for i, r := range p.Rows {
+		lhs := 0.0
+		for _, t := range r.Terms {
+			lhs += t.Coef * sol.X[t.Var]
+		}
+		fmt.Fprintf(w, "%-12s %12.6g %2s %9.6g %12.6g\n", r.Name, lhs, r.Sense, r.RHS, sol.Duals[i])
+	}
+}
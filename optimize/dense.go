@@ -0,0 +1,75 @@
+package optimize
+
+// tableau is a dense simplex tableau: B⁻¹A, with B⁻¹b as its last
+// column, updated in full at each pivot.
+type tableau struct {
+	t     [][]float64
+	basic []int
+}
+
+func newTableau(st *standard) *tableau {
+	n := st.cols()
+	tb := &tableau{t: make([][]float64, st.m), basic: append([]int{}, st.start...)}
+	for i := range tb.t {
+		tb.t[i] = make([]float64, n+1)
+		tb.t[i][n] = st.b[i]
+	}
+	for j, col := range st.a {
+		for _, e := range col {
+			tb.t[e.row][j] = e.val
+		}
+	}
+	return tb
+}
+
+func (tb *tableau) basis() []int {
+	return tb.basic
+}
+
+func (tb *tableau) values() []float64 {
+	x := make([]float64, len(tb.t))
+	for i, row := range tb.t {
+		x[i] = row[len(row)-1]
+	}
+	return x
+}
+
+func (tb *tableau) column(j int) []float64 {
+	a := make([]float64, len(tb.t))
+	for i, row := range tb.t {
+		a[i] = row[j]
+	}
+	return a
+}
+
+func (tb *tableau) reducedCosts(c []float64) []float64 {
+	d := append([]float64{}, c...)
+	for i, row := range tb.t {
+		cb := c[tb.basic[i]]
+		if cb == 0 {
+			continue
+		}
+		for j := range d {
+			d[j] -= cb * row[j]
+		}
+	}
+	return d
+}
+
+func (tb *tableau) pivot(r, q int, alpha []float64) {
+	pr := tb.t[r]
+	f := pr[q]
+	for j := range pr {
+		pr[j] /= f
+	}
+	for i, row := range tb.t {
+		if i == r || alpha[i] == 0 {
+			continue
+		}
+		g := row[q]
+		for j := range row {
+			row[j] -= g * pr[j]
+		}
+	}
+	tb.basic[r] = q
+}
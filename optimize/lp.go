@@ -0,0 +1,381 @@
+package optimize
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// lpToken is a name, number or operator of an LP file.
+type lpToken struct {
+	text  string
+	line  int
+	first bool // on its line
+}
+
+// lpTokens splits an LP file into tokens. \ starts a comment.
+func lpTokens(r io.Reader) ([]lpToken, error) {
+	toks := []lpToken{}
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := sc.Text()
+		if i := strings.IndexByte(text, '\\'); i >= 0 {
+			text = text[:i]
+		}
+		first := true
+		for i := 0; i < len(text); {
+			c := rune(text[i])
+			j := i + 1
+			switch {
+			case unicode.IsSpace(c):
+				i++
+				continue
+			case strings.ContainsRune("<>=", c):
+				for j < len(text) && strings.ContainsRune("<>=", rune(text[j])) {
+					j++
+				}
+			case c == '+' || c == '-' || c == ':':
+			case unicode.IsDigit(c) || c == '.':
+				for j < len(text) && (unicode.IsDigit(rune(text[j])) || text[j] == '.') {
+					j++
+				}
+				if j+1 < len(text) && (text[j] == 'e' || text[j] == 'E') {
+					k := j + 1
+					if text[k] == '+' || text[k] == '-' {
+						k++
+					}
+					if k < len(text) && unicode.IsDigit(rune(text[k])) {
+						for j = k; j < len(text) && unicode.IsDigit(rune(text[j])); j++ {
+						}
+					}
+				}
+			default:
+				for j < len(text) && !unicode.IsSpace(rune(text[j])) && !strings.ContainsRune("+-<>=:", rune(text[j])) {
+					j++
+				}
+			}
+			toks = append(toks, lpToken{text[i:j], line, first})
+			first = false
+			i = j
+		}
+	}
+	return toks, sc.Err()
+}
+
+func (t lpToken) errorf(format string, a ...interface{}) error {
+	return fmt.Errorf("line %d: %s", t.line, fmt.Sprintf(format, a...))
+}
+
+func (t lpToken) isNumber() bool {
+	_, err := strconv.ParseFloat(t.text, 64)
+	return err == nil && (unicode.IsDigit(rune(t.text[0])) || t.text[0] == '.')
+}
+
+func (t lpToken) isName() bool {
+	return !t.isNumber() && !strings.ContainsRune("+-<>=:", rune(t.text[0]))
+}
+
+// lpSection returns the section a line starting at toks[i] begins, and
+// the number of tokens naming it, or "" if it begins none.
+func lpSection(toks []lpToken, i int) (string, int) {
+	if !toks[i].first {
+		return "", 0
+	}
+	next := ""
+	if i+1 < len(toks) {
+		next = strings.ToLower(toks[i+1].text)
+	}
+	switch strings.ToLower(toks[i].text) {
+	case "minimize", "minimise", "minimum", "min":
+		return "min", 1
+	case "maximize", "maximise", "maximum", "max":
+		return "max", 1
+	case "subject", "such":
+		if next == "to" || next == "that" {
+			return "st", 2
+		}
+	case "st", "s.t.", "st.":
+		return "st", 1
+	case "bounds", "bound":
+		return "bounds", 1
+	case "general", "generals", "gen", "integer", "integers", "binary", "binaries", "bin":
+		return "integer", 1
+	case "end":
+		return "end", 1
+	}
+	return "", 0
+}
+
+// ParseLP reads a problem in the CPLEX LP format, as in
+//
+//	\ a comment
+//	Maximize
+//	 profit: 3 doors + 5 windows
+//	Subject To
+//	 plant1: doors <= 4
+//	 plant3: 3 doors + 2 windows <= 18
+//	Bounds
+//	 windows <= 6
+//	End
+//
+// Rows have variables on the left and a number on the right. Bounds are
+// written l <= x <= u, x >= l, x <= u, x = v or x free, with -inf and inf
+// for no bound. Integer sections are not supported.
+func ParseLP(r io.Reader) (*Problem, error) {
+	toks, err := lpTokens(r)
+	if err != nil {
+		return nil, err
+	}
+	lp := &lpParser{p: NewProblem(), vars: map[string]Var{}}
+	sections := map[string]bool{}
+	for i := 0; i < len(toks); {
+		sec, n := lpSection(toks, i)
+		if sec == "" {
+			return nil, toks[i].errorf("expected a section, not %s", toks[i].text)
+		}
+		if sections[sec] || sec == "max" && sections["min"] || sec == "min" && sections["max"] {
+			return nil, toks[i].errorf("%s repeated", toks[i].text)
+		}
+		sections[sec] = true
+		j := i + n
+		for j < len(toks) {
+			if s, _ := lpSection(toks, j); s != "" {
+				break
+			}
+			j++
+		}
+		body := toks[i+n : j]
+		switch sec {
+		case "min", "max":
+			lp.p.Maximize = sec == "max"
+			err = lp.objective(body)
+		case "st":
+			err = lp.rows(body)
+		case "bounds":
+			err = lp.bounds(body)
+		case "integer":
+			err = toks[i].errorf("integer variables not supported")
+		case "end":
+			if i+n < len(toks) {
+				err = toks[i+n].errorf("text after end")
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		i = j
+	}
+	if !sections["min"] && !sections["max"] {
+		return nil, fmt.Errorf("no objective")
+	}
+	return lp.p, nil
+}
+
+type lpParser struct {
+	p    *Problem
+	vars map[string]Var
+}
+
+func (lp *lpParser) variable(name string) Var {
+	x, ok := lp.vars[name]
+	if !ok {
+		x = lp.p.AddVar(name, 0)
+		lp.vars[name] = x
+	}
+	return x
+}
+
+// label returns the name of a row or objective, name:, starting toks, if any.
+func label(toks []lpToken) (string, []lpToken) {
+	if len(toks) >= 2 && toks[1].text == ":" && toks[0].isName() {
+		return toks[0].text, toks[2:]
+	}
+	return "", toks
+}
+
+func (lp *lpParser) objective(toks []lpToken) error {
+	_, toks = label(toks)
+	terms, rest, err := lp.expression(toks)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return rest[0].errorf("unexpected %s in objective", rest[0].text)
+	}
+	for _, t := range terms {
+		lp.p.Vars[t.Var].Cost += t.Coef
+	}
+	return nil
+}
+
+// expression parses terms such as 3 x - 2.5 y + z, stopping at an
+// operator other than + or -, and returns the tokens that follow.
+func (lp *lpParser) expression(toks []lpToken) ([]Term, []lpToken, error) {
+	terms := []Term{}
+	for i := 0; i < len(toks); {
+		start := i
+		sign := 1.0
+		for ; i < len(toks) && (toks[i].text == "+" || toks[i].text == "-"); i++ {
+			if toks[i].text == "-" {
+				sign = -sign
+			}
+		}
+		if i == len(toks) {
+			return nil, nil, toks[i-1].errorf("expected a term after %s", toks[i-1].text)
+		}
+		if i == start && len(terms) > 0 || !toks[i].isName() && !toks[i].isNumber() {
+			return terms, toks[start:], nil
+		}
+		coef := 1.0
+		if toks[i].isNumber() {
+			coef, _ = strconv.ParseFloat(toks[i].text, 64)
+			if i++; i == len(toks) || !toks[i].isName() {
+				return nil, nil, toks[i-1].errorf("constant %s not supported", toks[i-1].text)
+			}
+		}
+		if i+1 < len(toks) && toks[i+1].text == ":" {
+			return terms, toks[start:], nil
+		}
+		terms = append(terms, Term{lp.variable(toks[i].text), sign * coef})
+		i++
+	}
+	return terms, nil, nil
+}
+
+func sense(t lpToken) (Sense, bool) {
+	switch t.text {
+	case "<", "<=", "=<":
+		return LE, true
+	case ">", ">=", "=>":
+		return GE, true
+	case "=":
+		return EQ, true
+	}
+	return 0, false
+}
+
+// value parses a signed number or infinity starting toks.
+func value(toks []lpToken) (float64, []lpToken, error) {
+	sign := 1.0
+	if len(toks) > 0 && (toks[0].text == "+" || toks[0].text == "-") {
+		if toks[0].text == "-" {
+			sign = -1
+		}
+		toks = toks[1:]
+	}
+	if len(toks) == 0 {
+		return 0, nil, fmt.Errorf("expected a number at the end")
+	}
+	switch strings.ToLower(toks[0].text) {
+	case "inf", "infinity":
+		return sign * math.Inf(1), toks[1:], nil
+	}
+	if !toks[0].isNumber() {
+		return 0, nil, toks[0].errorf("expected a number, not %s", toks[0].text)
+	}
+	v, _ := strconv.ParseFloat(toks[0].text, 64)
+	return sign * v, toks[1:], nil
+}
+
+func (lp *lpParser) rows(toks []lpToken) error {
+	for len(toks) > 0 {
+		name, rest := label(toks)
+		if name == "" {
+			name = fmt.Sprintf("c%d", len(lp.p.Rows)+1)
+		}
+		terms, rest, err := lp.expression(rest)
+		if err != nil {
+			return err
+		}
+		if len(rest) == 0 {
+			return toks[len(toks)-1].errorf("row %s has no right-hand side", name)
+		}
+		s, ok := sense(rest[0])
+		if !ok {
+			return rest[0].errorf("expected <=, >= or = in row %s, not %s", name, rest[0].text)
+		}
+		rhs, rest, err := value(rest[1:])
+		if err != nil {
+			return err
+		}
+		lp.p.AddRow(name, s, rhs, terms...)
+		toks = rest
+	}
+	return nil
+}
+
+func (lp *lpParser) bounds(toks []lpToken) error {
+	for len(toks) > 0 {
+		var err error
+		if toks[0].isName() && !isInfinity(toks[0]) {
+			x := lp.variable(toks[0].text)
+			if len(toks) > 1 && strings.ToLower(toks[1].text) == "free" {
+				lp.p.SetBounds(x, math.Inf(-1), math.Inf(1))
+				toks = toks[2:]
+				continue
+			}
+			if len(toks) == 1 {
+				return toks[0].errorf("expected a bound on %s", toks[0].text)
+			}
+			s, ok := sense(toks[1])
+			if !ok {
+				return toks[1].errorf("expected a bound on %s", toks[0].text)
+			}
+			var v float64
+			if v, toks, err = value(toks[2:]); err != nil {
+				return err
+			}
+			lp.bound(x, s, v)
+			continue
+		}
+		// l <= x [<= u], or u >= x [>= l]
+		var v float64
+		if v, toks, err = value(toks); err != nil {
+			return err
+		}
+		if len(toks) < 2 || !toks[1].isName() {
+			return fmt.Errorf("expected a bound of the form l <= x <= u")
+		}
+		s, ok := sense(toks[0])
+		if !ok {
+			return toks[0].errorf("expected <=, >= or =, not %s", toks[0].text)
+		}
+		x := lp.variable(toks[1].text)
+		lp.bound(x, [...]Sense{GE, LE, EQ}[s], v)
+		toks = toks[2:]
+		if len(toks) > 0 {
+			if s2, ok := sense(toks[0]); ok {
+				if s2 != s {
+					return toks[0].errorf("bounds of %s in opposite directions", lp.p.Vars[x].Name)
+				}
+				if v, toks, err = value(toks[1:]); err != nil {
+					return err
+				}
+				lp.bound(x, s2, v)
+			}
+		}
+	}
+	return nil
+}
+
+func isInfinity(t lpToken) bool {
+	s := strings.ToLower(t.text)
+	return s == "inf" || s == "infinity"
+}
+
+// bound sets x s v.
+func (lp *lpParser) bound(x Var, s Sense, v float64) {
+	b := &lp.p.Vars[x]
+	switch s {
+	case LE:
+		b.Upper = v
+	case GE:
+		b.Lower = v
+	case EQ:
+		b.Lower, b.Upper = v, v
+	}
+}
@@ -0,0 +1,102 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"testing"
+)
+
+func ExampleParseLP() {
+	p, err := ParseLP(strings.NewReader(`
+Minimize
+ cost: 2 x + 3 y
+Subject To
+ total: x + y = 10
+ x >= 2
+Bounds
+ 3 <= y <= 8
+End`))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(p.Vars)
+	fmt.Println(p.Rows)
+	// Output:
+	// [{x 2 0 +Inf} {y 3 3 8}]
+	// [{total [{0 1} {1 1}] = 10} {c2 [{0 1}] >= 2}]
+}
+
+func TestParseLP(t *testing.T) {
+	p, err := ParseLP(strings.NewReader(`\ every form of bound
+maximise
+ obj: 3x1 - -2 x2 + x3 - 1.5e1 x4 + x5 + x6
+st
+ 3x1 + x2 <= 4 c2: x3 - x4
+   >= -2.5
+ x5 + x6 =< inf
+bounds
+ x1 <= 3
+ -inf <= x2 <= -1
+ x3 free
+ x4 = 2
+ 10 >= x5 >= -1
+ x6 >= -Infinity
+end`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	inf := math.Inf(1)
+	want := []Variable{{"x1", 3, 0, 3}, {"x2", 2, -inf, -1}, {"x3", 1, -inf, inf}, {"x4", -15, 2, 2}, {"x5", 1, -1, 10}, {"x6", 1, -inf, inf}}
+	if fmt.Sprint(p.Vars) != fmt.Sprint(want) || !p.Maximize {
+		t.Errorf("got %v, want %v", p.Vars, want)
+	}
+	if got := fmt.Sprint(p.Rows); got != "[{c1 [{0 3} {1 1}] <= 4} {c2 [{2 1} {3 -1}] >= -2.5} {c3 [{4 1} {5 1}] <= +Inf}]" {
+		t.Errorf("got rows %s", got)
+	}
+}
+
+func TestParseLPErrors(t *testing.T) {
+	for _, c := range []struct{ lp, want string }{
+		{"Subject To\n x <= 1\nEnd", "no objective"},
+		{"x + y", "line 1: expected a section, not x"},
+		{"Minimize\n x + 3\nEnd", "line 2: constant 3 not supported"},
+		{"Minimize\n x\nSubject To\n x + y\nEnd", "line 4: row c1 has no right-hand side"},
+		{"Minimize\n x\nSubject To\n x + y <= z\nEnd", "line 4: expected a number, not z"},
+		{"Minimize\n x\nSubject To\n x + <= 1\nEnd", "line 4: expected <=, >= or = in row c1, not +"},
+		{"Minimize\n x\nMaximize\n x", "line 3: Maximize repeated"},
+		{"Minimize\n x\nGeneral\n x\nEnd", "line 3: integer variables not supported"},
+		{"Minimize\n x\nBounds\n 1 <= x >= 3\nEnd", "line 4: bounds of x in opposite directions"},
+		{"Minimize\n x\nBounds\n x\nEnd", "line 4: expected a bound on x"},
+		{"Minimize\n x\nEnd\n x", "line 4: text after end"},
+	} {
+		_, err := ParseLP(strings.NewReader(c.lp))
+		if err == nil || err.Error() != c.want {
+			t.Errorf("%q: got error %v, want %s", c.lp, err, c.want)
+		}
+	}
+}
+
+func TestCapacity(t *testing.T) {
+	f, err := os.Open("testdata/capacity.lp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	p, err := ParseLP(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, s := range solvers() {
+		sol, err := s.Solve(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := fmt.Sprintf("%.6g %.6g %.6g", sol.Objective, sol.X, sol.Duals)
+		if want := "1320 [30 24 4 20] [8 3 0 0]"; got != want {
+			t.Errorf("%s: got %s, want %s", name, got, want)
+		}
+	}
+}
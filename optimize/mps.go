@@ -0,0 +1,265 @@
+package optimize
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ParseMPS reads a problem in the free MPS format, in which fields are
+// separated by spaces, so names may not contain them. Fixed MPS files
+// whose names have no spaces read the same way. Lines starting with * are
+// comments. The first N row is the objective and later ones are ignored.
+// RANGES, integer markers and integer bounds are not supported.
+//
+//	NAME          wyndor
+//	OBJSENSE
+//	    MAX
+//	ROWS
+//	 N  profit
+//	 L  plant1
+//	COLUMNS
+//	    doors     profit    3     plant1    1
+//	    windows   profit    5
+//	RHS
+//	    rhs       plant1    4
+//	BOUNDS
+//	 UP bnd       windows   6
+//	ENDATA
+func ParseMPS(r io.Reader) (*Problem, error) {
+	m := &mpsParser{p: NewProblem(), rows: map[string]int{}, vars: map[string]Var{}, free: map[string]bool{}}
+	sc := bufio.NewScanner(r)
+	section := ""
+	ended := false
+	for line := 1; sc.Scan(); line++ {
+		text := sc.Text()
+		ff := strings.Fields(text)
+		if len(ff) == 0 || strings.HasPrefix(text, "*") {
+			continue
+		}
+		if ended {
+			return nil, fmt.Errorf("line %d: text after ENDATA", line)
+		}
+		var err error
+		if text[0] != ' ' && text[0] != '\t' {
+			section = strings.ToUpper(ff[0])
+			switch section {
+			case "NAME":
+				if len(ff) > 1 {
+					m.p.Name = ff[1]
+				}
+			case "OBJSENSE":
+				if len(ff) > 1 {
+					err = m.objSense(ff[1])
+				}
+			case "ROWS", "COLUMNS", "RHS", "BOUNDS":
+			case "RANGES":
+				err = fmt.Errorf("RANGES not supported")
+			case "ENDATA":
+				ended = true
+			default:
+				err = fmt.Errorf("unknown section %s", ff[0])
+			}
+		} else {
+			switch section {
+			case "OBJSENSE":
+				err = m.objSense(ff[0])
+			case "ROWS":
+				err = m.row(ff)
+			case "COLUMNS":
+				err = m.column(ff)
+			case "RHS":
+				err = m.rhs(ff)
+			case "BOUNDS":
+				err = m.bound(ff)
+			default:
+				err = fmt.Errorf("data outside a section")
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if !ended {
+		return nil, fmt.Errorf("no ENDATA")
+	}
+	if m.objective == "" {
+		return nil, fmt.Errorf("no objective row")
+	}
+	return m.p, nil
+}
+
+type mpsParser struct {
+	p         *Problem
+	objective string
+	rows      map[string]int // number of each constraint row
+	free      map[string]bool
+	vars      map[string]Var
+}
+
+func (m *mpsParser) objSense(s string) error {
+	switch strings.ToUpper(s) {
+	case "MAX", "MAXIMIZE":
+		m.p.Maximize = true
+	case "MIN", "MINIMIZE":
+		m.p.Maximize = false
+	default:
+		return fmt.Errorf("unknown objective sense %s", s)
+	}
+	return nil
+}
+
+func (m *mpsParser) row(ff []string) error {
+	if len(ff) != 2 {
+		return fmt.Errorf("expected a row type and name")
+	}
+	name := ff[1]
+	if _, ok := m.rows[name]; ok || m.free[name] || name == m.objective {
+		return fmt.Errorf("row %s repeated", name)
+	}
+	s := LE
+	switch strings.ToUpper(ff[0]) {
+	case "N":
+		if m.objective == "" {
+			m.objective = name
+		} else {
+			m.free[name] = true
+		}
+		return nil
+	case "L":
+	case "G":
+		s = GE
+	case "E":
+		s = EQ
+	default:
+		return fmt.Errorf("unknown row type %s", ff[0])
+	}
+	m.rows[name] = m.p.AddRow(name, s, 0)
+	return nil
+}
+
+// pairs parses the row name and value pairs that end a line.
+func pairs(ff []string, do func(row string, v float64) error) error {
+	if len(ff) != 2 && len(ff) != 4 {
+		return fmt.Errorf("expected one or two row names and values")
+	}
+	for i := 0; i < len(ff); i += 2 {
+		v, err := strconv.ParseFloat(ff[i+1], 64)
+		if err != nil {
+			return fmt.Errorf("bad value %s", ff[i+1])
+		}
+		if err := do(ff[i], v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *mpsParser) column(ff []string) error {
+	if len(ff) > 1 && ff[1] == "'MARKER'" {
+		return fmt.Errorf("integer variables not supported")
+	}
+	if len(ff) < 3 {
+		return fmt.Errorf("expected a column, row and value")
+	}
+	x, ok := m.vars[ff[0]]
+	if !ok {
+		x = m.p.AddVar(ff[0], 0)
+		m.vars[ff[0]] = x
+	}
+	return pairs(ff[1:], func(row string, v float64) error {
+		if row == m.objective {
+			m.p.Vars[x].Cost += v
+			return nil
+		}
+		if m.free[row] {
+			return nil
+		}
+		i, ok := m.rows[row]
+		if !ok {
+			return fmt.Errorf("unknown row %s", row)
+		}
+		m.p.Rows[i].Terms = append(m.p.Rows[i].Terms, Term{x, v})
+		return nil
+	})
+}
+
+func (m *mpsParser) rhs(ff []string) error {
+	if len(ff)%2 == 1 {
+		ff = ff[1:] // the name of the right-hand side
+	}
+	return pairs(ff, func(row string, v float64) error {
+		if row == m.objective {
+			return fmt.Errorf("objective constant not supported")
+		}
+		if m.free[row] {
+			return nil
+		}
+		i, ok := m.rows[row]
+		if !ok {
+			return fmt.Errorf("unknown row %s", row)
+		}
+		m.p.Rows[i].RHS = v
+		return nil
+	})
+}
+
+// bound parses type [bound name] column [value]. As is usual for MPS, a
+// negative upper bound on a variable bounded below by 0 also removes its
+// lower bound.
+func (m *mpsParser) bound(ff []string) error {
+	typ := strings.ToUpper(ff[0])
+	fields := 3 // type, column, value
+	switch typ {
+	case "UP", "LO", "FX":
+	case "FR", "MI", "PL":
+		fields = 2
+	case "BV", "LI", "UI", "SC":
+		return fmt.Errorf("bound type %s not supported", typ)
+	default:
+		return fmt.Errorf("unknown bound type %s", ff[0])
+	}
+	switch len(ff) {
+	case fields:
+	case fields + 1:
+		ff = append(ff[:1], ff[2:]...) // drop the name of the bounds
+	default:
+		return fmt.Errorf("expected %d fields", fields+1)
+	}
+	x, ok := m.vars[ff[1]]
+	if !ok {
+		return fmt.Errorf("unknown column %s", ff[1])
+	}
+	v := 0.0
+	if fields == 3 {
+		var err error
+		if v, err = strconv.ParseFloat(ff[2], 64); err != nil {
+			return fmt.Errorf("bad value %s", ff[2])
+		}
+	}
+	b := &m.p.Vars[x]
+	switch typ {
+	case "UP":
+		b.Upper = v
+		if v < 0 && b.Lower == 0 {
+			b.Lower = math.Inf(-1)
+		}
+	case "LO":
+		b.Lower = v
+	case "FX":
+		b.Lower, b.Upper = v, v
+	case "FR":
+		b.Lower, b.Upper = math.Inf(-1), math.Inf(1)
+	case "MI":
+		b.Lower = math.Inf(-1)
+	case "PL":
+		b.Upper = math.Inf(1)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMPS(t *testing.T) {
+	open := func(name string) *os.File {
+		f, err := os.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+	f := open("testdata/capacity.mps")
+	defer f.Close()
+	mps, err := ParseMPS(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := open("testdata/capacity.lp")
+	defer g.Close()
+	lp, err := ParseLP(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mps.Name != "capacity" {
+		t.Errorf("got name %s", mps.Name)
+	}
+	lp.Name = mps.Name
+	if !reflect.DeepEqual(mps, lp) {
+		t.Errorf("MPS and LP differ:\n%+v\n%+v", mps, lp)
+	}
+}
+
+func TestMPSBounds(t *testing.T) {
+	p, err := ParseMPS(strings.NewReader(`NAME bounds
+ROWS
+ N obj
+ N other
+ E r1
+COLUMNS
+ a obj 1 r1 1
+ a other 7
+ b obj 1 r1 1
+ c obj 1 r1 1
+ d obj 1
+ e obj 1
+ f obj 1
+RHS
+ r1 5 other 3
+BOUNDS
+ UP bnd a -2
+ LO b -1
+ UP b 4
+ FX bnd c 3
+ FR d
+ MI bnd e
+ PL e
+ MI f
+ UP f 8
+ENDATA`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	inf := math.Inf(1)
+	want := []Variable{{"a", 1, -inf, -2}, {"b", 1, -1, 4}, {"c", 1, 3, 3}, {"d", 1, -inf, inf}, {"e", 1, -inf, inf}, {"f", 1, -inf, 8}}
+	if fmt.Sprint(p.Vars) != fmt.Sprint(want) || p.Maximize {
+		t.Errorf("got %v, want %v", p.Vars, want)
+	}
+	if got := fmt.Sprint(p.Rows); got != "[{r1 [{0 1} {1 1} {2 1}] = 5}]" {
+		t.Errorf("got rows %s", got)
+	}
+}
+
+func TestParseMPSErrors(t *testing.T) {
+	head := "NAME x\nROWS\n N obj\n L r\nCOLUMNS\n x obj 1 r 1\n"
+	for _, c := range []struct{ mps, want string }{
+		{"ROWS\n L r\nENDATA", "no objective row"},
+		{"NAME x\nROWS\n N obj", "no ENDATA"},
+		{"NAME x\nROWS\n Q r\nENDATA", "line 3: unknown row type Q"},
+		{"NAME x\nROWS\n L r\n G r\nENDATA", "line 4: row r repeated"},
+		{head + " y s 1\nENDATA", "line 7: unknown row s"},
+		{head + " y r one\nENDATA", "line 7: bad value one"},
+		{head + " y r 1 obj\nENDATA", "line 7: expected one or two row names and values"},
+		{head + " m 'MARKER' 'INTORG'\nENDATA", "line 7: integer variables not supported"},
+		{head + "RHS\n rhs obj 4\nENDATA", "line 8: objective constant not supported"},
+		{head + "RANGES\n rng r 4\nENDATA", "line 7: RANGES not supported"},
+		{head + "BOUNDS\n UP b y 4\nENDATA", "line 8: unknown column y"},
+		{head + "BOUNDS\n BV b x\nENDATA", "line 8: bound type BV not supported"},
+		{head + "BOUNDS\n UP b x 4 5\nENDATA", "line 8: expected 4 fields"},
+		{head + "OBJSENSE\n UP\nENDATA", "line 8: unknown objective sense UP"},
+		{head + "SOS\nENDATA", "line 7: unknown section SOS"},
+		{head + "ENDATA\nNAME y", "line 8: text after ENDATA"},
+		{" x obj 1\nENDATA", "line 1: data outside a section"},
+	} {
+		_, err := ParseMPS(strings.NewReader(c.mps))
+		if err == nil || err.Error() != c.want {
+			t.Errorf("%q: got error %v, want %s", c.mps, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+// Package optimize -- given a linear objective in some variables, and linear
+// constraints on them.
+// Find values for the variables that meet the constraints and make the
+// objective as small, or as large, as possible.
+package optimize
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Var identifies a variable of a Problem. Variables are numbered from 0
+// in the order they are added.
+type Var int
+
+// Variable is a variable of a problem with its cost in the objective and
+// its bounds, which may be infinite.
+type Variable struct {
+	Name         string
+	Cost         float64
+	Lower, Upper float64
+}
+
+// Sense is the relation of a row to its right-hand side.
+type Sense int
+
+// Senses of a row.
+const (
+	LE Sense = iota // less than or equal to
+	GE              // greater than or equal to
+	EQ              // equal to
+)
+
+func (s Sense) String() string {
+	return [...]string{"<=", ">=", "="}[s]
+}
+
+// Term is a coefficient times a variable.
+type Term struct {
+	Var  Var
+	Coef float64
+}
+
+// Row is a linear constraint: the sum of its terms is related by Sense to
+// RHS.
+type Row struct {
+	Name  string
+	Terms []Term
+	Sense Sense
+	RHS   float64
+}
+
+// Problem is a linear program: minimise, or maximise, the sum of the
+// costs of the variables times their values, subject to the rows and
+// the bounds of the variables.
+type Problem struct {
+	Name     string
+	Maximize bool
+	Vars     []Variable
+	Rows     []Row
+}
+
+// NewProblem returns a minimisation problem with no variables.
+func NewProblem() *Problem {
+	return &Problem{}
+}
+
+// AddVar adds a variable with the given cost, bounded below by 0 and
+// unbounded above.
+func (p *Problem) AddVar(name string, cost float64) Var {
+	p.Vars = append(p.Vars, Variable{Name: name, Cost: cost, Upper: math.Inf(1)})
+	return Var(len(p.Vars) - 1)
+}
+
+// SetBounds sets the bounds of x. Use math.Inf for no bound.
+func (p *Problem) SetBounds(x Var, lower, upper float64) {
+	p.Vars[x].Lower, p.Vars[x].Upper = lower, upper
+}
+
+// AddRow adds the constraint that the sum of terms is related by sense to
+// rhs, and returns its number.
+func (p *Problem) AddRow(name string, sense Sense, rhs float64, terms ...Term) int {
+	p.Rows = append(p.Rows, Row{Name: name, Terms: terms, Sense: sense, RHS: rhs})
+	return len(p.Rows) - 1
+}
+
+// Objective returns the value of the objective at x.
+func (p *Problem) Objective(x []float64) float64 {
+	z := 0.0
+	for j, v := range p.Vars {
+		z += v.Cost * x[j]
+	}
+	return z
+}
+
+// Violation returns by how much x most violates a row or bound; 0 if x is
+// feasible.
+func (p *Problem) Violation(x []float64) float64 {
+	worst := 0.0
+	for j, v := range p.Vars {
+		worst = math.Max(worst, math.Max(v.Lower-x[j], x[j]-v.Upper))
+	}
+	for _, r := range p.Rows {
+		lhs := 0.0
+		for _, t := range r.Terms {
+			lhs += t.Coef * x[t.Var]
+		}
+		switch r.Sense {
+		case LE:
+			worst = math.Max(worst, lhs-r.RHS)
+		case GE:
+			worst = math.Max(worst, r.RHS-lhs)
+		case EQ:
+			worst = math.Max(worst, math.Abs(lhs-r.RHS))
+		}
+	}
+	return worst
+}
+
+// Errors returned by Solve.
+var (
+	ErrInfeasible = errors.New("problem is infeasible")
+	ErrUnbounded  = errors.New("problem is unbounded")
+)
+
+// Solution is an optimal solution of a problem.
+type Solution struct {
+	Objective float64
+	X         []float64 // value of each variable
+	// Duals are the shadow prices of the rows: the rate at which the
+	// objective changes as the right-hand side of each row increases.
+	Duals []float64
+}
+
+// Simplex solves linear programs by the simplex method. The zero value
+// uses a dense tableau.
+type Simplex struct {
+	// Sparse selects the revised simplex method, which keeps the
+	// constraints as sparse columns and updates only the inverse of the
+	// basis, rather than a dense tableau of every column. It is faster
+	// when there are many more columns than rows and few are non-zero.
+	Sparse        bool
+	MaxIterations int // 0 means no limit
+
+	Iterations int // pivots made by the last Solve
+}
+
+// Solve returns an optimal solution of p, or an error wrapping
+// ErrInfeasible or ErrUnbounded if there is none.
+//
+// The problem is first put in standard form: minimise c·x subject to
+// Ax = b, x ≥ 0 and b ≥ 0, by shifting and splitting variables by their
+// bounds and adding slack variables. Phase one then finds a feasible
+// basis by minimising the sum of artificial variables, one for each row
+// without a slack to start the basis, and phase two optimises from there.
+// Bland's rule, choosing the lowest-numbered column to enter and to leave
+// the basis, prevents cycling on degenerate problems.
+//
+// Pseudocode of each phase from Chvátal, Linear Programming, chapter 7:
+//
+//	loop
+//	    y := c_B B⁻¹
+//	    choose the lowest j with reduced cost c_j - y·a_j < 0
+//	    if there is none then return x_B := B⁻¹b, optimal
+//	    d := B⁻¹a_j
+//	    if no d_i > 0 then return unbounded
+//	    t := min x_i/d_i over d_i > 0, choosing the lowest basic variable on ties
+//	    x_B := x_B - t·d, x_j := t, and j replaces the variable leaving the basis
+func (s *Simplex) Solve(p *Problem) (*Solution, error) {
+	st, err := p.standard()
+	if err != nil {
+		return nil, err
+	}
+	var e engine
+	if s.Sparse {
+		e = newRevised(st)
+	} else {
+		e = newTableau(st)
+	}
+	s.Iterations = 0
+
+	// Phase one.
+	c := make([]float64, st.cols())
+	for j := st.n; j < st.cols(); j++ {
+		c[j] = 1
+	}
+	if err := s.optimize(e, c, st.cols()); err != nil {
+		return nil, err
+	}
+	infeasibility := 0.0
+	for i, j := range e.basis() {
+		if j >= st.n {
+			infeasibility += e.values()[i]
+		}
+	}
+	if infeasibility > feasTol*(1+norm(st.b)) {
+		return nil, ErrInfeasible
+	}
+	s.driveOut(e, st)
+
+	// Phase two, with artificial columns barred from the basis.
+	if err := s.optimize(e, st.c, st.n); err != nil {
+		return nil, err
+	}
+	return st.solution(p, e), nil
+}
+
+// Tolerances for treating a value as zero and a problem as feasible.
+const (
+	eps     = 1e-9
+	feasTol = 1e-7
+)
+
+func norm(v []float64) float64 {
+	n := 0.0
+	for _, x := range v {
+		n = math.Max(n, math.Abs(x))
+	}
+	return n
+}
+
+// optimize pivots until no column below enter has a negative reduced cost
+// with costs c.
+func (s *Simplex) optimize(e engine, c []float64, enter int) error {
+	for {
+		d := e.reducedCosts(c)
+		q := -1
+		for j := 0; j < enter; j++ {
+			if d[j] < -eps {
+				q = j
+				break
+			}
+		}
+		if q < 0 {
+			return nil
+		}
+		alpha := e.column(q)
+		r := -1
+		best := math.Inf(1)
+		x, basis := e.values(), e.basis()
+		for i, a := range alpha {
+			if a <= eps {
+				continue
+			}
+			t := math.Max(x[i], 0) / a
+			if t < best-eps || (t < best+eps && basis[i] < basis[r]) {
+				r, best = i, t
+			}
+		}
+		if r < 0 {
+			return ErrUnbounded
+		}
+		if s.MaxIterations > 0 && s.Iterations >= s.MaxIterations {
+			return fmt.Errorf("iteration limit of %d reached", s.MaxIterations)
+		}
+		e.pivot(r, q, alpha)
+		s.Iterations++
+	}
+}
+
+// driveOut replaces the artificial variables left in the basis, at zero,
+// with other columns where it can. Those that remain are in rows that are
+// combinations of other rows, and stay at zero.
+func (s *Simplex) driveOut(e engine, st *standard) {
+	for r, j := range e.basis() {
+		if j < st.n {
+			continue
+		}
+		for q := 0; q < st.n; q++ {
+			if alpha := e.column(q); math.Abs(alpha[r]) > feasTol {
+				e.pivot(r, q, alpha)
+				s.Iterations++
+				break
+			}
+		}
+	}
+}
+
+// engine keeps a basis of a problem in standard form and pivots it.
+type engine interface {
+	basis() []int                       // column basic in each row
+	values() []float64                  // of the basic variables
+	column(j int) []float64             // B⁻¹a_j
+	reducedCosts(c []float64) []float64 // c_j - c_B B⁻¹a_j, for every column
+	pivot(r, q int, alpha []float64)    // column q, with B⁻¹a_q alpha, enters in row r
+}
@@ -0,0 +1,231 @@
+package optimize
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func ExampleSimplex_Solve() {
+	// Wyndor Glass: make doors and windows in three plants of limited
+	// capacity for the most profit.
+	p := NewProblem()
+	p.Maximize = true
+	doors := p.AddVar("doors", 3)
+	windows := p.AddVar("windows", 5)
+	p.AddRow("plant1", LE, 4, Term{doors, 1})
+	p.AddRow("plant2", LE, 12, Term{windows, 2})
+	p.AddRow("plant3", LE, 18, Term{doors, 3}, Term{windows, 2})
+
+	s := &Simplex{}
+	sol, err := s.Solve(p)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(sol.Objective, sol.X, sol.Duals)
+	// Output:
+	// 36 [2 6] [0 1.5 1]
+}
+
+func solvers() map[string]*Simplex {
+	return map[string]*Simplex{"dense": {}, "sparse": {Sparse: true}}
+}
+
+func TestSolve(t *testing.T) {
+	inf := math.Inf(1)
+	for _, c := range []struct {
+		name  string
+		build func(p *Problem)
+		z     float64
+		x     []float64
+		err   error
+	}{
+		{"artificials", func(p *Problem) {
+			// min 2x + 3y, x + y = 10, x >= 2, y >= 3
+			x, y := p.AddVar("x", 2), p.AddVar("y", 3)
+			p.AddRow("total", EQ, 10, Term{x, 1}, Term{y, 1})
+			p.AddRow("xmin", GE, 2, Term{x, 1})
+			p.AddRow("ymin", GE, 3, Term{y, 1})
+		}, 23, []float64{7, 3}, nil},
+		{"bounds", func(p *Problem) {
+			// min x - y + z, x in [1, 4], y <= 2, z free, z >= x - 5
+			x, y, z := p.AddVar("x", 1), p.AddVar("y", -1), p.AddVar("z", 1)
+			p.SetBounds(x, 1, 4)
+			p.SetBounds(y, -inf, 2)
+			p.SetBounds(z, -inf, inf)
+			p.AddRow("z", GE, -5, Term{z, 1}, Term{x, -1})
+		}, -5, []float64{1, 2, -4}, nil},
+		{"redundant", func(p *Problem) {
+			x, y := p.AddVar("x", 1), p.AddVar("y", 0)
+			p.AddRow("a", EQ, 2, Term{x, 1}, Term{y, 1})
+			p.AddRow("b", EQ, 4, Term{x, 2}, Term{y, 2})
+		}, 0, []float64{0, 2}, nil},
+		{"beale", func(p *Problem) {
+			// Beale's example, on which the largest coefficient rule cycles.
+			x4, x5, x6, x7 := p.AddVar("x4", -0.75), p.AddVar("x5", 20), p.AddVar("x6", -0.5), p.AddVar("x7", 6)
+			p.AddRow("r1", LE, 0, Term{x4, 0.25}, Term{x5, -8}, Term{x6, -1}, Term{x7, 9})
+			p.AddRow("r2", LE, 0, Term{x4, 0.5}, Term{x5, -12}, Term{x6, -0.5}, Term{x7, 3})
+			p.AddRow("r3", LE, 1, Term{x6, 1})
+		}, -1.25, []float64{1, 0, 1, 0}, nil},
+		{"infeasible", func(p *Problem) {
+			x := p.AddVar("x", 1)
+			p.AddRow("a", GE, 3, Term{x, 1})
+			p.AddRow("b", LE, 2, Term{x, 1})
+		}, 0, nil, ErrInfeasible},
+		{"crossed bounds", func(p *Problem) {
+			p.SetBounds(p.AddVar("x", 1), 2, 1)
+		}, 0, nil, ErrInfeasible},
+		{"unbounded", func(p *Problem) {
+			x, y := p.AddVar("x", -1), p.AddVar("y", 0)
+			p.AddRow("a", LE, 1, Term{x, 1}, Term{y, -1})
+		}, 0, nil, ErrUnbounded},
+	} {
+		for name, s := range solvers() {
+			p := NewProblem()
+			c.build(p)
+			sol, err := s.Solve(p)
+			if c.err != nil {
+				if !errors.Is(err, c.err) {
+					t.Errorf("%s %s: got error %v, want %v", c.name, name, err, c.err)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("%s %s: %v", c.name, name, err)
+				continue
+			}
+			if math.Abs(sol.Objective-c.z) > 1e-9 {
+				t.Errorf("%s %s: got objective %v, want %v", c.name, name, sol.Objective, c.z)
+			}
+			for j := range c.x {
+				if math.Abs(sol.X[j]-c.x[j]) > 1e-9 {
+					t.Errorf("%s %s: got %v, want %v", c.name, name, sol.X, c.x)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestIterationLimit(t *testing.T) {
+	p := NewProblem()
+	p.Maximize = true
+	x, y := p.AddVar("x", 3), p.AddVar("y", 5)
+	p.AddRow("a", LE, 4, Term{x, 1})
+	p.AddRow("b", LE, 18, Term{x, 3}, Term{y, 2})
+	s := &Simplex{MaxIterations: 1}
+	if _, err := s.Solve(p); err == nil || err.Error() != "iteration limit of 1 reached" {
+		t.Errorf("got %v", err)
+	}
+	s.MaxIterations = 0
+	if _, err := s.Solve(p); err != nil || s.Iterations < 2 {
+		t.Errorf("got %v after %d pivots", err, s.Iterations)
+	}
+}
+
+// randomProblem returns a feasible problem, min c·x with c ≥ 0 over x ≥ 0,
+// with rows of each sense holding at a random point.
+func randomProblem(r *rand.Rand, m, n int, density float64) *Problem {
+	p := NewProblem()
+	x0 := make([]float64, n)
+	for j := range x0 {
+		p.AddVar(fmt.Sprint("x", j), float64(r.Intn(10)))
+		x0[j] = float64(r.Intn(5))
+	}
+	for i := 0; i < m; i++ {
+		terms := []Term{}
+		lhs := 0.0
+		for j := 0; j < n; j++ {
+			if r.Float64() < density {
+				a := float64(r.Intn(19) - 9)
+				terms = append(terms, Term{Var(j), a})
+				lhs += a * x0[j]
+			}
+		}
+		sense := Sense(r.Intn(3))
+		rhs := lhs
+		switch sense {
+		case LE:
+			rhs += float64(r.Intn(4))
+		case GE:
+			rhs -= float64(r.Intn(4))
+		}
+		p.AddRow(fmt.Sprint("r", i), sense, rhs, terms...)
+	}
+	return p
+}
+
+// TestDuality checks random problems against linear programming duality:
+// the duals are feasible for the dual problem and give the same objective.
+func TestDuality(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for k := 0; k < 200; k++ {
+		p := randomProblem(r, 1+r.Intn(8), 1+r.Intn(10), 0.6)
+		var z []float64
+		for _, name := range []string{"dense", "sparse"} {
+			sol, err := solvers()[name].Solve(p)
+			if err != nil {
+				t.Fatalf("problem %d %s: %v", k, name, err)
+			}
+			z = append(z, sol.Objective)
+			if v := p.Violation(sol.X); v > 1e-7 {
+				t.Errorf("problem %d %s: violated by %v", k, name, v)
+			}
+			by := 0.0
+			reduced := make([]float64, len(p.Vars))
+			for j, v := range p.Vars {
+				reduced[j] = v.Cost
+			}
+			for i, row := range p.Rows {
+				y := sol.Duals[i]
+				if row.Sense == LE && y > 1e-9 || row.Sense == GE && y < -1e-9 {
+					t.Errorf("problem %d %s: dual %v of %s row", k, name, y, row.Sense)
+				}
+				by += y * row.RHS
+				for _, tm := range row.Terms {
+					reduced[tm.Var] -= y * tm.Coef
+				}
+			}
+			for j, d := range reduced {
+				if d < -1e-7 {
+					t.Errorf("problem %d %s: reduced cost of x%d is %v", k, name, j, d)
+				}
+			}
+			if math.Abs(by-sol.Objective) > 1e-7 {
+				t.Errorf("problem %d %s: dual objective %v, primal %v", k, name, by, sol.Objective)
+			}
+		}
+		if math.Abs(z[0]-z[1]) > 1e-7 {
+			t.Errorf("problem %d: dense %v, sparse %v", k, z[0], z[1])
+		}
+	}
+}
+
+func TestReinvert(t *testing.T) {
+	// Enough pivots that the sparse solver recomputes its inverse.
+	r := rand.New(rand.NewSource(2))
+	p := randomProblem(r, 60, 120, 0.1)
+	p.Maximize = true
+	for j := range p.Vars {
+		p.Vars[j].Cost = float64(r.Intn(10))
+		p.SetBounds(Var(j), 0, 10)
+	}
+	dense, sparse := &Simplex{}, &Simplex{Sparse: true}
+	ds, err := dense.Solve(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss, err := sparse.Solve(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sparse.Iterations < reinvert {
+		t.Errorf("only %d pivots", sparse.Iterations)
+	}
+	if math.Abs(ds.Objective-ss.Objective) > 1e-6 || p.Violation(ss.X) > 1e-6 {
+		t.Errorf("dense %v, sparse %v violated by %v", ds.Objective, ss.Objective, p.Violation(ss.X))
+	}
+}
@@ -0,0 +1,141 @@
+package optimize
+
+import "math"
+
+// revised is the revised simplex method: the columns of A are kept
+// sparse and only B⁻¹, dense, and the basic values are updated at each
+// pivot. B⁻¹ is recomputed from the basis every reinvert pivots to limit
+// the build up of rounding errors.
+type revised struct {
+	st     *standard
+	binv   [][]float64
+	x      []float64
+	basic  []int
+	pivots int
+}
+
+const reinvert = 100
+
+func newRevised(st *standard) *revised {
+	rv := &revised{st: st, basic: append([]int{}, st.start...), x: append([]float64{}, st.b...)}
+	rv.binv = identity(st.m)
+	return rv
+}
+
+func identity(m int) [][]float64 {
+	id := make([][]float64, m)
+	for i := range id {
+		id[i] = make([]float64, m)
+		id[i][i] = 1
+	}
+	return id
+}
+
+func (rv *revised) basis() []int {
+	return rv.basic
+}
+
+func (rv *revised) values() []float64 {
+	return rv.x
+}
+
+func (rv *revised) column(j int) []float64 {
+	a := make([]float64, rv.st.m)
+	for _, e := range rv.st.a[j] {
+		for i, row := range rv.binv {
+			a[i] += row[e.row] * e.val
+		}
+	}
+	return a
+}
+
+func (rv *revised) reducedCosts(c []float64) []float64 {
+	y := make([]float64, rv.st.m)
+	for i, row := range rv.binv {
+		cb := c[rv.basic[i]]
+		if cb == 0 {
+			continue
+		}
+		for k, v := range row {
+			y[k] += cb * v
+		}
+	}
+	d := append([]float64{}, c...)
+	for j, col := range rv.st.a {
+		for _, e := range col {
+			d[j] -= y[e.row] * e.val
+		}
+	}
+	return d
+}
+
+func (rv *revised) pivot(r, q int, alpha []float64) {
+	pr := rv.binv[r]
+	f := alpha[r]
+	for k := range pr {
+		pr[k] /= f
+	}
+	rv.x[r] /= f
+	for i, row := range rv.binv {
+		if i == r || alpha[i] == 0 {
+			continue
+		}
+		g := alpha[i]
+		for k := range row {
+			row[k] -= g * pr[k]
+		}
+		rv.x[i] -= g * rv.x[r]
+	}
+	rv.basic[r] = q
+	if rv.pivots++; rv.pivots%reinvert == 0 {
+		rv.invert()
+	}
+}
+
+// invert recomputes B⁻¹ and the basic values by Gauss-Jordan elimination
+// with partial pivoting.
+func (rv *revised) invert() {
+	m := rv.st.m
+	b := make([][]float64, m)
+	for i := range b {
+		b[i] = make([]float64, m)
+	}
+	for k, j := range rv.basic {
+		for _, e := range rv.st.a[j] {
+			b[e.row][k] = e.val
+		}
+	}
+	inv := identity(m)
+	for col := 0; col < m; col++ {
+		p := col
+		for i := col + 1; i < m; i++ {
+			if math.Abs(b[i][col]) > math.Abs(b[p][col]) {
+				p = i
+			}
+		}
+		b[col], b[p] = b[p], b[col]
+		inv[col], inv[p] = inv[p], inv[col]
+		f := b[col][col]
+		for k := 0; k < m; k++ {
+			b[col][k] /= f
+			inv[col][k] /= f
+		}
+		for i := 0; i < m; i++ {
+			if i == col || b[i][col] == 0 {
+				continue
+			}
+			g := b[i][col]
+			for k := 0; k < m; k++ {
+				b[i][k] -= g * b[col][k]
+				inv[i][k] -= g * inv[col][k]
+			}
+		}
+	}
+	rv.binv = inv
+	for i, row := range inv {
+		rv.x[i] = 0
+		for k, v := range row {
+			rv.x[i] += v * rv.st.b[k]
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+)
+
+// entry is a non-zero of a sparse column.
+type entry struct {
+	row int
+	val float64
+}
+
+// standard is a problem in standard form: minimise c·x subject to Ax = b,
+// x ≥ 0 and b ≥ 0. Columns from n on are artificial, one for each row
+// with no slack to start the basis, which is start.
+type standard struct {
+	m, n    int
+	a       [][]entry // by column
+	b, c    []float64 // c is 0 for artificial columns
+	start   []int     // initial basis, an identity
+	rowSign []float64 // -1 for rows negated to make b ≥ 0
+	rows    int       // the first rows are those of the problem; the rest bound variables above
+
+	use    [][]colUse // columns making up each variable of the problem
+	offset []float64  // added to each variable of the problem
+}
+
+// colUse is a column standing for sign times a variable.
+type colUse struct {
+	col  int
+	sign float64
+}
+
+func (st *standard) cols() int {
+	return len(st.a)
+}
+
+// standard returns p in standard form. A variable with a finite lower
+// bound l is l plus a column, one with only an upper bound u is u less a
+// column, and a free one is the difference of two columns. Finite upper
+// bounds of the first kind become rows.
+func (p *Problem) standard() (*standard, error) {
+	st := &standard{use: make([][]colUse, len(p.Vars)), offset: make([]float64, len(p.Vars))}
+	type bound struct {
+		col   int
+		upper float64
+	}
+	bounds := []bound{}
+	for j, v := range p.Vars {
+		switch {
+		case v.Lower > v.Upper:
+			return nil, fmt.Errorf("bounds of %s: %w", v.Name, ErrInfeasible)
+		case !math.IsInf(v.Lower, -1):
+			st.offset[j] = v.Lower
+			st.use[j] = []colUse{{st.newCol(), 1}}
+			if !math.IsInf(v.Upper, 1) {
+				bounds = append(bounds, bound{st.use[j][0].col, v.Upper - v.Lower})
+			}
+		case !math.IsInf(v.Upper, 1):
+			st.offset[j] = v.Upper
+			st.use[j] = []colUse{{st.newCol(), -1}}
+		default:
+			st.use[j] = []colUse{{st.newCol(), 1}, {st.newCol(), -1}}
+		}
+	}
+	st.c = make([]float64, st.cols())
+	for j, v := range p.Vars {
+		cost := v.Cost
+		if p.Maximize {
+			cost = -cost
+		}
+		for _, u := range st.use[j] {
+			st.c[u.col] += cost * u.sign
+		}
+	}
+
+	rows := []map[int]float64{}
+	rhs := []float64{}
+	senses := []Sense{}
+	for _, r := range p.Rows {
+		coef := map[int]float64{}
+		b := r.RHS
+		for _, t := range r.Terms {
+			if int(t.Var) < 0 || int(t.Var) >= len(p.Vars) {
+				return nil, fmt.Errorf("row %s: no variable %d", r.Name, t.Var)
+			}
+			b -= t.Coef * st.offset[t.Var]
+			for _, u := range st.use[t.Var] {
+				coef[u.col] += t.Coef * u.sign
+			}
+		}
+		rows, rhs, senses = append(rows, coef), append(rhs, b), append(senses, r.Sense)
+	}
+	for _, bd := range bounds {
+		rows = append(rows, map[int]float64{bd.col: 1})
+		rhs, senses = append(rhs, bd.upper), append(senses, LE)
+	}
+	st.m, st.rows = len(rows), len(p.Rows)
+
+	// Slacks, then sign flips, then the initial basis.
+	st.b = rhs
+	st.rowSign = make([]float64, st.m)
+	slack := make([]int, st.m)
+	for i := range rows {
+		slack[i] = -1
+		switch senses[i] {
+		case LE:
+			slack[i] = st.newCol()
+			rows[i][slack[i]] = 1
+		case GE:
+			slack[i] = st.newCol()
+			rows[i][slack[i]] = -1
+		}
+		st.rowSign[i] = 1
+		if st.b[i] < 0 {
+			st.rowSign[i] = -1
+			st.b[i] = -st.b[i]
+			for k := range rows[i] {
+				rows[i][k] = -rows[i][k]
+			}
+		}
+	}
+	st.c = append(st.c, make([]float64, st.cols()-len(st.c))...)
+	for i, row := range rows { // so each column is in row order
+		for k, v := range row {
+			if v != 0 {
+				st.a[k] = append(st.a[k], entry{i, v})
+			}
+		}
+	}
+	st.n = st.cols()
+	st.start = make([]int, st.m)
+	for i := range rows {
+		if slack[i] >= 0 && rows[i][slack[i]] == 1 {
+			st.start[i] = slack[i]
+			continue
+		}
+		st.start[i] = st.newCol()
+		st.a[st.start[i]] = []entry{{i, 1}}
+		st.c = append(st.c, 0)
+	}
+	return st, nil
+}
+
+func (st *standard) newCol() int {
+	st.a = append(st.a, nil)
+	return len(st.a) - 1
+}
+
+// solution maps the optimal basis of e back to p.
+func (st *standard) solution(p *Problem, e engine) *Solution {
+	x := make([]float64, st.cols())
+	for i, j := range e.basis() {
+		x[j] = e.values()[i]
+	}
+	sol := &Solution{X: make([]float64, len(p.Vars)), Duals: make([]float64, st.rows)}
+	for j := range p.Vars {
+		sol.X[j] = st.offset[j]
+		for _, u := range st.use[j] {
+			sol.X[j] += u.sign * x[u.col]
+		}
+	}
+	sol.Objective = p.Objective(sol.X)
+
+	y := st.duals(e.basis())
+	for i := range sol.Duals {
+		sol.Duals[i] = y[i] * st.rowSign[i]
+		if p.Maximize {
+			sol.Duals[i] = -sol.Duals[i]
+		}
+		sol.Duals[i] += 0 // not -0
+	}
+	return sol
+}
+
+// duals solves yB = c_B for the prices y of the rows, by Gaussian
+// elimination with partial pivoting on Bᵀ.
+func (st *standard) duals(basis []int) []float64 {
+	m := st.m
+	bt := make([][]float64, m) // row k of Bᵀ is column basis[k]
+	for k, j := range basis {
+		bt[k] = make([]float64, m+1)
+		for _, e := range st.a[j] {
+			bt[k][e.row] = e.val
+		}
+		bt[k][m] = st.c[j]
+	}
+	for col := 0; col < m; col++ {
+		p := col
+		for k := col + 1; k < m; k++ {
+			if math.Abs(bt[k][col]) > math.Abs(bt[p][col]) {
+				p = k
+			}
+		}
+		bt[col], bt[p] = bt[p], bt[col]
+		if math.Abs(bt[col][col]) < eps {
+			continue
+		}
+		for k := 0; k < m; k++ {
+			if k == col || bt[k][col] == 0 {
+				continue
+			}
+			f := bt[k][col] / bt[col][col]
+			for j := col; j <= m; j++ {
+				bt[k][j] -= f * bt[col][j]
+			}
+		}
+	}
+	y := make([]float64, m)
+	for i := range y {
+		if math.Abs(bt[i][i]) >= eps {
+			y[i] = bt[i][m] / bt[i][i]
+		}
+	}
+	return y
+}
@@ -0,0 +1,26 @@
+* Capacity planning: the problem of capacity.lp in MPS format.
+NAME          capacity
+OBJSENSE
+    MAX
+ROWS
+ N  profit
+ L  assembly
+ L  testing
+ L  labour
+ G  contract
+COLUMNS
+    widgets   profit    20         assembly  2
+    widgets   testing   1          labour    3
+    gadgets   profit    30         assembly  3
+    gadgets   testing   2          labour    2
+    gadgets   contract  1
+    gizmos    profit    25         assembly  2
+    gizmos    testing   3          labour    2
+    overtime  profit    -5         assembly  -1
+RHS
+    rhs       assembly  120        testing   90
+    rhs       labour    150        contract  10
+BOUNDS
+ UP bnd       widgets   30
+ UP bnd       overtime  20
+ENDATA
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/siuyin/ai/planning"
+	"github.com/siuyin/ai/search"
+)
+
+var (
+	searcher  = flag.String("search", "greedy", "bfs, dfs, astar, greedy or graphplan")
+	heuristic = flag.String("h", "ff", "heuristic for astar and greedy: max, add, ff or level")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), `usage: plan [flags] domain.pddl problem.pddl
+
+Finds a plan for a problem written in the STRIPS subset of PDDL, with
+typing and negative preconditions. See testdata for examples.
+
+flags:
+`)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	f := open(flag.Arg(0))
+	d, err := planning.ParseDomain(f)
+	if err != nil {
+		log.Fatalf("%s: %v", f.Name(), err)
+	}
+	f.Close()
+	f = open(flag.Arg(1))
+	p, err := planning.ParseProblem(f)
+	if err != nil {
+		log.Fatalf("%s: %v", f.Name(), err)
+	}
+	f.Close()
+
+	start := time.Now()
+	t, err := planning.Ground(d, p)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%d facts, %d actions, grounded in %v\n", len(t.Facts), len(t.Operators), time.Since(start).Round(time.Microsecond))
+
+	if *searcher == "graphplan" {
+		graphPlan(t)
+		return
+	}
+	hs := map[string]search.Heuristic{"max": t.HMax, "add": t.HAdd, "ff": t.HFF, "level": t.HLevel}
+	h, ok := hs[*heuristic]
+	if !ok {
+		log.Fatalf("unknown heuristic %q", *heuristic)
+	}
+	start = time.Now()
+	var g search.State
+	switch *searcher {
+	case "bfs":
+		g, err = search.Search(t.GoalState(), t.StartState(), t, t)
+	case "dfs":
+		g, err = search.SearchDFS(t.GoalState(), t.StartState(), t, t)
+	case "astar":
+		g, err = search.SearchAStar(t.GoalState(), t.StartState(), t, t, t, h)
+	case "greedy":
+		g, err = search.SearchGreedy(t.GoalState(), t.StartState(), t, t, h)
+	default:
+		log.Fatalf("unknown search %q", *searcher)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	plan := planning.Plan(g)
+	for i, a := range plan {
+		fmt.Printf("%3d %s\n", i+1, a)
+	}
+	fmt.Printf("%d steps, %d states, found in %v\n", len(plan), t.States(), time.Since(start).Round(time.Microsecond))
+}
+
+// graphPlan prints the actions of each step of a plan on a line.
+func graphPlan(t *planning.Task) {
+	start := time.Now()
+	g := planning.NewGraph(t)
+	plan, err := g.Plan()
+	if err != nil {
+		log.Fatal(err)
+	}
+	for i, step := range plan {
+		fmt.Printf("%3d %s\n", i+1, strings.Join(step, " "))
+	}
+	fmt.Printf("%d steps, %d actions, %d levels, %d nogoods, found in %v\n", len(plan), len(planning.Linearize(plan)),
+		g.Levels(), g.Nogoods, time.Since(start).Round(time.Microsecond))
+}
+
+func open(name string) *os.File {
+	f, err := os.Open(name)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return f
+}
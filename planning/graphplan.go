@@ -0,0 +1,493 @@
+package planning
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/siuyin/ai/know"
+	"github.com/siuyin/ai/search"
+)
+
+// Graph is a planning graph of a Task: alternate levels of the literals
+// that may hold after i steps and of the actions that may be taken at step
+// i, with mutual exclusions between them. A literal is a fact or its
+// negation; the world is closed, so the negation of every fact not true
+// at the start holds then. Literals are the symbols of package know:
+// know.Sym("(on a b)") and know.Not(know.Sym("(on a b)")).
+//
+// Levels only ever grow, and mutexes only ever go, so the graph
+// eventually levels off: the next level is the same as the last.
+type Graph struct {
+	Nogoods int // goal sets found to be unsolvable by the last Plan
+
+	t       *Task
+	steps   []*gpStep
+	levels  []*gpLevel
+	levelAt int // the level at which the graph levelled off, or -1
+	props   map[know.Prop]int
+	nogoods []map[string]bool // by level
+	plan    [][]int           // steps chosen from each level
+}
+
+// Literal l is fact l/2, negated if l is odd.
+func neg(l int) int { return l ^ 1 }
+
+// gpStep is an operator or, if op is -1, the no-op that keeps a literal true.
+type gpStep struct {
+	op       int
+	pre, eff []int // literals
+}
+
+type gpLevel struct {
+	lits      bitset   // literals that may hold
+	mutex     []bitset // by literal: literals that cannot hold with it
+	steps     []int    // steps from the previous level
+	stepMutex []bitset // by step: steps that cannot be taken with it
+	mutexes   int      // number of mutex pairs of literals
+}
+
+// NewGraph returns the planning graph of t with the single level of the
+// literals holding at the start.
+func NewGraph(t *Task) *Graph {
+	b := t.newBitset()
+	for _, f := range t.Init {
+		b.set(f)
+	}
+	return t.graph(b)
+}
+
+func (t *Task) graph(facts bitset) *Graph {
+	g := &Graph{t: t, levelAt: -1, props: map[know.Prop]int{}}
+	for f, name := range t.Facts {
+		g.props[know.Sym(name)] = 2 * f
+		g.props[know.Not(know.Sym(name))] = 2*f + 1
+	}
+	for i, a := range t.Operators {
+		s := &gpStep{op: i}
+		for _, f := range a.Pre {
+			s.pre = append(s.pre, 2*f)
+		}
+		for _, f := range a.NegPre {
+			s.pre = append(s.pre, 2*f+1)
+		}
+		for _, f := range a.Add {
+			s.eff = append(s.eff, 2*f)
+		}
+		for _, f := range a.Del {
+			if !contains(a.Add, f) {
+				s.eff = append(s.eff, 2*f+1)
+			}
+		}
+		g.steps = append(g.steps, s)
+	}
+	for l := 0; l < 2*len(t.Facts); l++ {
+		g.steps = append(g.steps, &gpStep{op: -1, pre: []int{l}, eff: []int{l}})
+	}
+
+	first := &gpLevel{lits: g.newLits(), mutex: make([]bitset, 2*len(t.Facts))}
+	for f := range t.Facts {
+		if facts.has(f) {
+			first.lits.set(2 * f)
+		} else {
+			first.lits.set(2*f + 1)
+		}
+	}
+	g.levels = []*gpLevel{first}
+	return g
+}
+
+func contains(ff []int, f int) bool {
+	for _, x := range ff {
+		if x == f {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Graph) newLits() bitset {
+	return make(bitset, (2*len(g.t.Facts)+63)/64)
+}
+
+// Levels returns the number of literal levels in the graph.
+func (g *Graph) Levels() int {
+	return len(g.levels)
+}
+
+// LevelledOff returns the level from which all later levels are the same,
+// or -1 if the graph has not yet levelled off.
+func (g *Graph) LevelledOff() int {
+	return g.levelAt
+}
+
+// Props returns the literals that may hold at level i.
+func (g *Graph) Props(i int) []know.Prop {
+	pp := []know.Prop{}
+	lv := g.levels[i]
+	for l := 0; l < 2*len(g.t.Facts); l++ {
+		if lv.lits.has(l) {
+			pp = append(pp, g.prop(l))
+		}
+	}
+	return pp
+}
+
+func (g *Graph) prop(l int) know.Prop {
+	s := know.Sym(g.t.Facts[l/2])
+	if l%2 == 1 {
+		return know.Not(s)
+	}
+	return s
+}
+
+// Mutex reports whether literals p and q cannot both hold at level i.
+// Literals not at level i cannot hold at all.
+func (g *Graph) Mutex(i int, p, q know.Prop) bool {
+	a, ok := g.props[p]
+	b, ok2 := g.props[q]
+	lv := g.levels[i]
+	if !ok || !ok2 || !lv.lits.has(a) || !lv.lits.has(b) {
+		return true
+	}
+	return lv.mutex[a] != nil && lv.mutex[a].has(b)
+}
+
+// Actions returns the names of the actions that may be taken after
+// level i, leading to level i+1. No-ops are not included.
+func (g *Graph) Actions(i int) []string {
+	names := []string{}
+	for _, s := range g.levels[i+1].steps {
+		if op := g.steps[s].op; op >= 0 {
+			names = append(names, g.t.Operators[op].Name)
+		}
+	}
+	return names
+}
+
+// Expand adds a level to the graph. Once the graph has levelled off, the
+// new level is the same as the last.
+//
+// Action a is at level i if its preconditions are at level i and no two
+// are mutex. Actions a and b are mutex if an effect of one negates an
+// effect or precondition of the other, or a precondition of one is mutex
+// with one of the other. Literal l is at level i+1 if it is an effect of
+// an action at level i, and literals l and m are mutex if every action
+// giving l is mutex with every action giving m.
+func (g *Graph) Expand() {
+	last := g.levels[len(g.levels)-1]
+	if g.levelAt >= 0 {
+		g.levels = append(g.levels, last)
+		return
+	}
+	next := &gpLevel{lits: g.newLits(), mutex: make([]bitset, 2*len(g.t.Facts))}
+	next.stepMutex = make([]bitset, len(g.steps))
+	achievers := make([][]int, 2*len(g.t.Facts))
+	for i, s := range g.steps {
+		if !last.holdTogether(s.pre) {
+			continue
+		}
+		next.steps = append(next.steps, i)
+		for _, l := range s.eff {
+			next.lits.set(l)
+			achievers[l] = append(achievers[l], i)
+		}
+	}
+	for x, i := range next.steps {
+		for _, j := range next.steps[x+1:] {
+			if g.stepsMutex(last, g.steps[i], g.steps[j]) {
+				next.setStepMutex(i, j, len(g.steps))
+			}
+		}
+	}
+	for l := range achievers {
+		for m := l + 1; m < len(achievers); m++ {
+			if len(achievers[l]) == 0 || len(achievers[m]) == 0 || !next.allMutex(achievers[l], achievers[m]) {
+				continue
+			}
+			next.setMutex(l, m, g.newLits)
+		}
+	}
+	g.levels = append(g.levels, next)
+	if next.mutexes == last.mutexes && next.lits.key() == last.lits.key() {
+		g.levelAt = len(g.levels) - 2
+	}
+}
+
+// holdTogether reports whether the literals are all at the level, with
+// no two mutex.
+func (lv *gpLevel) holdTogether(lits []int) bool {
+	for x, l := range lits {
+		if !lv.lits.has(l) {
+			return false
+		}
+		for _, m := range lits[x+1:] {
+			if lv.mutex[l] != nil && lv.mutex[l].has(m) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (g *Graph) stepsMutex(lv *gpLevel, a, b *gpStep) bool {
+	negates := func(a, b *gpStep) bool {
+		for _, l := range a.eff {
+			if contains(b.eff, neg(l)) || contains(b.pre, neg(l)) {
+				return true
+			}
+		}
+		return false
+	}
+	if negates(a, b) || negates(b, a) {
+		return true
+	}
+	for _, p := range a.pre {
+		if lv.mutex[p] == nil {
+			continue
+		}
+		for _, q := range b.pre {
+			if lv.mutex[p].has(q) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (lv *gpLevel) setStepMutex(i, j, n int) {
+	for _, k := range [][2]int{{i, j}, {j, i}} {
+		if lv.stepMutex[k[0]] == nil {
+			lv.stepMutex[k[0]] = make(bitset, (n+63)/64)
+		}
+		lv.stepMutex[k[0]].set(k[1])
+	}
+}
+
+func (lv *gpLevel) stepsMutex(i, j int) bool {
+	return lv.stepMutex[i] != nil && lv.stepMutex[i].has(j)
+}
+
+// allMutex reports whether every step in aa is mutex with every one in bb.
+func (lv *gpLevel) allMutex(aa, bb []int) bool {
+	for _, a := range aa {
+		for _, b := range bb {
+			if a == b || !lv.stepsMutex(a, b) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (lv *gpLevel) setMutex(l, m int, newLits func() bitset) {
+	for _, k := range [][2]int{{l, m}, {m, l}} {
+		if lv.mutex[k[0]] == nil {
+			lv.mutex[k[0]] = newLits()
+		}
+		lv.mutex[k[0]].set(k[1])
+	}
+	lv.mutexes++
+}
+
+// goals returns the literals of the goal of the task.
+func (g *Graph) goals() []int {
+	ll := []int{}
+	for _, f := range g.t.Goal {
+		ll = append(ll, 2*f)
+	}
+	for _, f := range g.t.NegGoal {
+		ll = append(ll, 2*f+1)
+	}
+	return ll
+}
+
+// Plan returns a plan for the task, found by GraphPlan, as a sequence of
+// steps, each a set of actions that may be taken in any order, or an error
+// if there is none. It expands the graph until the goals appear with no
+// two mutex, then searches backwards from them for a set of non-mutex
+// actions giving them, whose preconditions can in turn be reached. Goal
+// sets that cannot be reached at a level are recorded as nogoods. The
+// plan is the shortest in steps, though not necessarily in actions.
+//
+// Pseudocode from Russell and Norvig, AIMA 3rd ed. figure 10.9:
+//
+//	function GRAPHPLAN(problem) returns solution or failure
+//	    graph := INITIAL-PLANNING-GRAPH(problem)
+//	    goals := CONJUNCTS(problem.GOAL)
+//	    nogoods := an empty hash table
+//	    for tl = 0 to ∞ do
+//	        if goals all non-mutex in St of graph then
+//	            solution := EXTRACT-SOLUTION(graph, goals, NUMLEVELS(graph), nogoods)
+//	            if solution ≠ failure then return solution
+//	        if graph and nogoods have both leveled off then return failure
+//	        graph := EXPAND-GRAPH(graph, problem)
+func (g *Graph) Plan() ([][]string, error) {
+	goals := g.goals()
+	g.Nogoods = 0
+	last := -1 // nogoods at the level-off level after the previous stage
+	for {
+		n := len(g.levels) - 1
+		for len(g.nogoods) <= n {
+			g.nogoods = append(g.nogoods, map[string]bool{})
+		}
+		if g.levels[n].holdTogether(goals) {
+			g.plan = make([][]int, n)
+			if g.extract(goals, n) {
+				return g.names(), nil
+			}
+		}
+		if g.levelAt >= 0 {
+			if !g.levels[g.levelAt].holdTogether(goals) {
+				return nil, fmt.Errorf("goal cannot be reached")
+			}
+			if k := len(g.nogoods[g.levelAt]); k == last {
+				return nil, fmt.Errorf("goal cannot be reached")
+			} else if n > g.levelAt {
+				last = k
+			}
+		}
+		g.Expand()
+	}
+}
+
+// extract reports whether goals, which hold together at level i, can be
+// reached from the start, recording the steps taken.
+func (g *Graph) extract(goals []int, i int) bool {
+	if i == 0 {
+		return true
+	}
+	sort.Ints(goals)
+	k := fmt.Sprint(goals)
+	if g.nogoods[i][k] {
+		return false
+	}
+	if g.assign(goals, 0, nil, i) {
+		return true
+	}
+	g.nogoods[i][k] = true
+	g.Nogoods++
+	return false
+}
+
+// assign chooses non-mutex steps giving goals[k:] at level i, in addition
+// to those chosen, preferring no-ops, then extracts their preconditions
+// at the level before.
+func (g *Graph) assign(goals []int, k int, chosen []int, i int) bool {
+	lv := g.levels[i]
+	if k == len(goals) {
+		pre := []int{}
+		seen := map[int]bool{}
+		for _, s := range chosen {
+			for _, l := range g.steps[s].pre {
+				if !seen[l] {
+					seen[l] = true
+					pre = append(pre, l)
+				}
+			}
+		}
+		if !g.extract(pre, i-1) {
+			return false
+		}
+		g.plan[i-1] = append([]int{}, chosen...)
+		return true
+	}
+	l := goals[k]
+	for _, s := range chosen {
+		if contains(g.steps[s].eff, l) {
+			return g.assign(goals, k+1, chosen, i)
+		}
+	}
+	noop := len(g.t.Operators) + l
+	for _, s := range append([]int{noop}, lv.steps...) {
+		if s != noop && (g.steps[s].op < 0 || !contains(g.steps[s].eff, l)) {
+			continue
+		}
+		if s == noop && !g.levels[i-1].lits.has(l) {
+			continue
+		}
+		ok := true
+		for _, c := range chosen {
+			if lv.stepsMutex(s, c) {
+				ok = false
+				break
+			}
+		}
+		if ok && g.assign(goals, k+1, append(chosen, s), i) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Graph) names() [][]string {
+	plan := [][]string{}
+	for _, ss := range g.plan {
+		names := []string{}
+		for _, s := range ss {
+			if op := g.steps[s].op; op >= 0 {
+				names = append(names, g.t.Operators[op].Name)
+			}
+		}
+		if len(names) > 0 {
+			sort.Strings(names)
+			plan = append(plan, names)
+		}
+	}
+	return plan
+}
+
+// GraphPlan returns a plan for t as a sequence of steps, each a set of
+// actions that may be taken in any order. See Graph.Plan.
+func GraphPlan(t *Task) ([][]string, error) {
+	return NewGraph(t).Plan()
+}
+
+// Linearize returns the actions of a parallel plan in order.
+func Linearize(plan [][]string) []string {
+	aa := []string{}
+	for _, step := range plan {
+		aa = append(aa, step...)
+	}
+	return aa
+}
+
+// HLevel estimates the number of steps from s to the goal as the first
+// level of the planning graph from s at which the goals hold with no two
+// mutex. Since a step may take several actions, it never overestimates
+// the number of actions. It is +Inf if the graph levels off first.
+// The second argument is ignored, so HLevel is a search.Heuristic.
+func (t *Task) HLevel(s, _ search.State) float64 {
+	if s.ID == goalID {
+		return 0
+	}
+	g := t.graph(t.states[s.ID])
+	goals := g.goals()
+	for i := 0; ; i++ {
+		if g.levels[i].holdTogether(goals) {
+			return float64(i)
+		}
+		if g.levelAt >= 0 {
+			return math.Inf(1)
+		}
+		g.Expand()
+	}
+}
+
+// String lists the literals of each level and the actions between them,
+// for small graphs.
+func (g *Graph) String() string {
+	var sb strings.Builder
+	for i := range g.levels {
+		if i > 0 {
+			fmt.Fprintf(&sb, "A%d: %s\n", i-1, strings.Join(g.Actions(i-1), " "))
+		}
+		pp := []string{}
+		for _, p := range g.Props(i) {
+			pp = append(pp, p.String())
+		}
+		fmt.Fprintf(&sb, "S%d: %s\n", i, strings.Join(pp, " "))
+	}
+	return sb.String()
+}
@@ -0,0 +1,151 @@
+package planning
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/siuyin/ai/know"
+	"github.com/siuyin/ai/search"
+)
+
+func ExampleGraphPlan() {
+	df, _ := os.Open("testdata/logistics-domain.pddl")
+	defer df.Close()
+	d, _ := ParseDomain(df)
+	pf, _ := os.Open("testdata/logistics-2.pddl")
+	defer pf.Close()
+	p, _ := ParseProblem(pf)
+
+	t, _ := Ground(d, p)
+	plan, _ := GraphPlan(t)
+	for i, step := range plan[:3] {
+		fmt.Println(i+1, step)
+	}
+	fmt.Println(len(plan), "steps,", len(Linearize(plan)), "actions")
+	// Output:
+	// 1 [(load-truck pkg1 truck1 post1) (load-truck pkg2 truck2 post2)]
+	// 2 [(drive-truck truck1 post1 airport1 city1) (drive-truck truck2 post2 airport2 city2)]
+	// 3 [(unload-truck pkg1 truck1 airport1) (unload-truck pkg2 truck2 airport2)]
+	// 11 steps, 18 actions
+}
+
+func TestGraph(t *testing.T) {
+	task := ground(t, "testdata/blocks-domain.pddl", "testdata/blocks-sussman.pddl")
+	g := NewGraph(task)
+	if n := len(g.Props(0)); n != len(task.Facts) {
+		t.Errorf("got %d literals at level 0, want one for each of %d facts", n, len(task.Facts))
+	}
+	holdingC, handempty := know.Sym("(holding c)"), know.Sym("(handempty)")
+	if !g.Mutex(0, holdingC, handempty) {
+		t.Errorf("(holding c) is not at level 0, so should be mutex")
+	}
+	g.Expand()
+	if got := fmt.Sprint(g.Actions(0)); got != "[(pick-up b) (unstack c a)]" {
+		t.Errorf("got actions %s", got)
+	}
+	if !g.Mutex(1, holdingC, handempty) || !g.Mutex(1, holdingC, know.Not(holdingC)) {
+		t.Errorf("(holding c) should be mutex with (handempty) and its negation")
+	}
+	if g.Mutex(1, holdingC, know.Sym("(clear b)")) || g.Mutex(1, know.Sym("(clear a)"), know.Sym("(ontable b)")) {
+		t.Errorf("unexpected mutex")
+	}
+	if !g.Mutex(1, holdingC, know.Sym("(holding b)")) {
+		t.Errorf("one hand cannot hold two blocks")
+	}
+	if g.LevelledOff() != -1 {
+		t.Errorf("levelled off too soon")
+	}
+	for i := 0; i < 10; i++ {
+		g.Expand()
+	}
+	if l := g.LevelledOff(); l != 6 {
+		t.Errorf("levelled off at %d, want 6", l)
+	}
+	if len(g.Props(11)) != len(g.Props(6)) || !strings.Contains(g.String(), "A10: ") {
+		t.Errorf("levels after levelling off should be the same")
+	}
+}
+
+func TestGraphPlan(t *testing.T) {
+	for _, c := range []struct {
+		domain, problem string
+		steps           int
+	}{
+		{"blocks-domain.pddl", "blocks-sussman.pddl", 6},
+		{"blocks-domain.pddl", "blocks-6.pddl", 12},
+		{"logistics-domain.pddl", "logistics-2.pddl", 11},
+	} {
+		task := ground(t, "testdata/"+c.domain, "testdata/"+c.problem)
+		plan, err := GraphPlan(task)
+		if err != nil {
+			t.Errorf("%s: %v", c.problem, err)
+			continue
+		}
+		if len(plan) != c.steps {
+			t.Errorf("%s: got %d steps, want %d", c.problem, len(plan), c.steps)
+		}
+		if err := task.Validate(Linearize(plan)); err != nil {
+			t.Errorf("%s: %v", c.problem, err)
+		}
+	}
+}
+
+func TestGraphPlanFails(t *testing.T) {
+	for _, c := range []struct{ domain, problem string }{
+		// Mutex goals: the graph levels off without them holding together.
+		{`(define (domain switch) (:predicates (p) (q))
+			(:action a :parameters () :effect (and (p) (not (q))))
+			(:action b :parameters () :effect (and (q) (not (p)))))`,
+			`(define (problem p) (:domain switch) (:init) (:goal (and (p) (q))))`},
+		// Three pigeons, two holes: no two goals are mutex, but the nogoods
+		// level off.
+		{`(define (domain pigeons) (:requirements :typing) (:types pigeon hole)
+			(:predicates (free ?h - hole) (out ?p - pigeon) (placed ?p - pigeon))
+			(:action put :parameters (?p - pigeon ?h - hole)
+				:precondition (and (free ?h) (out ?p))
+				:effect (and (placed ?p) (not (free ?h)) (not (out ?p)))))`,
+			`(define (problem p) (:domain pigeons)
+			(:objects p1 p2 p3 - pigeon h1 h2 - hole)
+			(:init (free h1) (free h2) (out p1) (out p2) (out p3))
+			(:goal (and (placed p1) (placed p2) (placed p3))))`},
+	} {
+		d, err := ParseDomain(strings.NewReader(c.domain))
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, err := ParseProblem(strings.NewReader(c.problem))
+		if err != nil {
+			t.Fatal(err)
+		}
+		task, err := Ground(d, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if plan, err := GraphPlan(task); err == nil {
+			t.Errorf("%s: got plan %v, want none", d.Name, plan)
+		}
+		if h := task.HLevel(task.StartState(), task.GoalState()); d.Name == "switch" && !math.IsInf(h, 1) {
+			t.Errorf("got hlevel %v, want +Inf", h)
+		}
+	}
+}
+
+func TestHLevel(t *testing.T) {
+	task := ground(t, "testdata/blocks-domain.pddl", "testdata/blocks-6.pddl")
+	if h := task.HLevel(task.StartState(), task.GoalState()); h != 12 {
+		t.Errorf("got %v, want 12", h)
+	}
+	g, err := search.SearchAStar(task.GoalState(), task.StartState(), task, task, task, task.HLevel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(Plan(g)); n != 12 {
+		t.Errorf("got %d steps, want 12", n)
+	}
+	if h := task.HLevel(g, g); h != 0 {
+		t.Errorf("got %v at the goal, want 0", h)
+	}
+}
@@ -0,0 +1,189 @@
+package planning
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Operator is a ground action: a schema with objects for its parameters.
+// Facts are numbered as in Task.Facts.
+type Operator struct {
+	Name     string // e.g. (pick-up a)
+	Pre      []int  // facts that must be true
+	NegPre   []int  // facts that must be false
+	Add, Del []int
+}
+
+// Ground returns the task of solving p in d. Each action schema is
+// instantiated with every combination of objects of the right types,
+// except those ruled out by static predicates, which no action changes
+// and so are checked against the initial state and dropped. Actions
+// that cannot be reached from the initial state, ignoring deletes and
+// negative preconditions, are dropped too.
+func Ground(d *Domain, p *Problem) (*Task, error) {
+	if p.Domain != "" && p.Domain != d.Name {
+		return nil, fmt.Errorf("problem %s is for domain %s, not %s", p.Name, p.Domain, d.Name)
+	}
+	g := &grounder{d: d, objType: map[string]string{}, init: map[string]bool{}, t: newTask()}
+	for _, o := range append(append([]Param{}, d.Constants...), p.Objects...) {
+		if err := d.checkType(o); err != nil {
+			return nil, err
+		}
+		if _, ok := g.objType[o.Name]; ok {
+			return nil, fmt.Errorf("object %s declared twice", o.Name)
+		}
+		g.objType[o.Name] = o.Type
+		g.objects = append(g.objects, o.Name)
+	}
+	g.fluent = map[string]bool{}
+	for _, a := range d.Actions {
+		for _, e := range a.Effects {
+			g.fluent[e.Pred] = true
+		}
+	}
+
+	for _, a := range p.Init {
+		if err := g.checkGround(a); err != nil {
+			return nil, fmt.Errorf("init: %v", err)
+		}
+		g.init[a.String()] = true
+		if g.fluent[a.Pred] {
+			g.t.Init = append(g.t.Init, g.t.fact(a.String()))
+		}
+	}
+	for _, l := range p.Goal {
+		if err := g.checkGround(l.Atom); err != nil {
+			return nil, fmt.Errorf("goal: %v", err)
+		}
+		if !g.fluent[l.Pred] {
+			if g.init[l.Atom.String()] == l.Neg {
+				return nil, fmt.Errorf("goal %v can never hold", l)
+			}
+			continue
+		}
+		f := g.t.fact(l.Atom.String())
+		if l.Neg {
+			g.t.NegGoal = append(g.t.NegGoal, f)
+		} else {
+			g.t.Goal = append(g.t.Goal, f)
+		}
+	}
+
+	for _, a := range d.Actions {
+		g.instantiate(a, map[string]string{}, 0)
+	}
+	g.t.prune()
+	g.t.link()
+	return g.t, nil
+}
+
+type grounder struct {
+	d       *Domain
+	objects []string
+	objType map[string]string
+	fluent  map[string]bool // predicates some action changes
+	init    map[string]bool // atoms true at the start
+	t       *Task
+}
+
+// checkGround reports an atom that does not match a predicate or whose
+// arguments are not objects of the right type.
+func (g *grounder) checkGround(a Atom) error {
+	if err := g.d.checkAtom(a, nil); err != nil {
+		return err
+	}
+	pred := g.d.predicate(a.Pred)
+	for i, x := range a.Args {
+		t, ok := g.objType[x]
+		if !ok {
+			return fmt.Errorf("%v: unknown object %s", a, x)
+		}
+		if !g.isA(t, pred.Params[i].Type) {
+			return fmt.Errorf("%v: %s is not a %s", a, x, pred.Params[i].Type)
+		}
+	}
+	return nil
+}
+
+// isA reports whether type t is, or is a subtype of, u.
+func (g *grounder) isA(t, u string) bool {
+	for ; t != ""; t = g.d.Types[t] {
+		if t == u {
+			return true
+		}
+	}
+	return false
+}
+
+// instantiate binds the parameters of a from the ith on, in every way,
+// adding the ground actions whose static preconditions hold.
+func (g *grounder) instantiate(a *Schema, bind map[string]string, i int) {
+	for _, l := range a.Pre {
+		if g.fluent[l.Pred] {
+			continue
+		}
+		ga, ok := substitute(l.Atom, bind)
+		if ok && g.init[ga.String()] == l.Neg {
+			return
+		}
+	}
+	if i == len(a.Params) {
+		g.add(a, bind)
+		return
+	}
+	p := a.Params[i]
+	for _, o := range g.objects {
+		if g.isA(g.objType[o], p.Type) {
+			bind[p.Name] = o
+			g.instantiate(a, bind, i+1)
+		}
+	}
+	delete(bind, p.Name)
+}
+
+// substitute replaces the parameters of a bound in bind, reporting
+// whether every argument is then an object.
+func substitute(a Atom, bind map[string]string) (Atom, bool) {
+	ga := Atom{Pred: a.Pred, Args: make([]string, len(a.Args))}
+	for i, x := range a.Args {
+		if strings.HasPrefix(x, "?") {
+			o, ok := bind[x]
+			if !ok {
+				return ga, false
+			}
+			x = o
+		}
+		ga.Args[i] = x
+	}
+	return ga, true
+}
+
+func (g *grounder) add(a *Schema, bind map[string]string) {
+	args := []string{a.Name}
+	for _, p := range a.Params {
+		args = append(args, bind[p.Name])
+	}
+	ga := &Operator{Name: "(" + strings.Join(args, " ") + ")"}
+	for _, l := range a.Pre {
+		if !g.fluent[l.Pred] {
+			continue
+		}
+		at, _ := substitute(l.Atom, bind)
+		f := g.t.fact(at.String())
+		if l.Neg {
+			ga.NegPre = append(ga.NegPre, f)
+		} else {
+			ga.Pre = append(ga.Pre, f)
+		}
+	}
+	for _, l := range a.Effects {
+		at, _ := substitute(l.Atom, bind)
+		f := g.t.fact(at.String())
+		if l.Neg {
+			ga.Del = append(ga.Del, f)
+		} else {
+			ga.Add = append(ga.Add, f)
+		}
+	}
+	g.t.Operators = append(g.t.Operators, ga)
+}
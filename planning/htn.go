@@ -0,0 +1,456 @@
+package planning
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/siuyin/ai/search"
+)
+
+// HTN is a hierarchical task network domain in the style of SHOP: tasks
+// are either primitive, done by an operator, or compound, decomposed by a
+// method into subtasks. Tasks, like atoms, are written (name args...);
+// SHOP starts the names of primitive tasks with !, but that is not
+// required here.
+type HTN struct {
+	Operators []*HTNOperator
+	Methods   []*Method
+	MaxDepth  int // of the decomposition tree; 0 means no limit
+
+	Decompositions int // methods applied by the last Plan
+	Backtracks     int // decompositions and operators undone by the last Plan
+}
+
+// HTNOperator does a primitive task. Its parameters are the variables in
+// Task, and its preconditions and effects, as in a Schema, may use them.
+type HTNOperator struct {
+	Task    Atom
+	Pre     []Literal
+	Effects []Literal
+}
+
+// Method decomposes a compound task into subtasks, done in order, when its
+// preconditions hold. Variables in the preconditions but not in the task
+// are bound by matching them to the state; every binding is tried.
+type Method struct {
+	Name     string
+	Task     Atom
+	Pre      []Literal
+	Subtasks []Atom
+}
+
+// Node is a task in a decomposition tree: a compound task with the method
+// that decomposed it and its subtasks, or a primitive task with the action
+// doing it.
+type Node struct {
+	Task     Atom // ground
+	Method   string
+	Subtasks []*Node
+	Action   search.Action // for a primitive task
+}
+
+// Primitive reports whether n is done by an action.
+func (n *Node) Primitive() bool {
+	return n.Method == ""
+}
+
+// String shows the tree under n, a task to a line, with subtasks indented.
+func (n *Node) String() string {
+	var sb strings.Builder
+	n.write(&sb, "")
+	return sb.String()
+}
+
+func (n *Node) write(sb *strings.Builder, indent string) {
+	if n.Primitive() {
+		fmt.Fprintf(sb, "%s%v\n", indent, n.Task)
+		return
+	}
+	fmt.Fprintf(sb, "%s%v by %s\n", indent, n.Task, n.Method)
+	for _, c := range n.Subtasks {
+		c.write(sb, indent+"  ")
+	}
+}
+
+// parseList parses atoms or literals written one after another, as in
+// "(at ?x) (not (busy ?x))".
+func parseList(s string) ([]*sexp, error) {
+	e, err := parseSexp(strings.NewReader("(" + s + ")"))
+	if err != nil {
+		return nil, err
+	}
+	return e.list, nil
+}
+
+func parseAtoms(s string) ([]Atom, error) {
+	ss, err := parseList(s)
+	if err != nil {
+		return nil, err
+	}
+	aa := []Atom{}
+	for _, e := range ss {
+		a, err := atom(e)
+		if err != nil {
+			return nil, err
+		}
+		aa = append(aa, a)
+	}
+	return aa, nil
+}
+
+func parseLiterals(s string) ([]Literal, error) {
+	ss, err := parseList(s)
+	if err != nil {
+		return nil, err
+	}
+	ll := []Literal{}
+	for _, e := range ss {
+		l, err := literal(e)
+		if err != nil {
+			return nil, err
+		}
+		ll = append(ll, l)
+	}
+	return ll, nil
+}
+
+func parseTask(s string) (Atom, error) {
+	aa, err := parseAtoms(s)
+	if err == nil && len(aa) != 1 {
+		err = fmt.Errorf("expected one task in %q", s)
+	}
+	if err != nil {
+		return Atom{}, err
+	}
+	return aa[0], nil
+}
+
+// AddOperator adds an operator doing task, written as in
+// "(!pick-up ?x)", with preconditions and effects each written as
+// literals one after another, as in "(clear ?x) (not (holding ?y))".
+func (h *HTN) AddOperator(task, pre, effects string) error {
+	op := &HTNOperator{}
+	var err error
+	if op.Task, err = parseTask(task); err != nil {
+		return err
+	}
+	if op.Pre, err = parseLiterals(pre); err != nil {
+		return fmt.Errorf("operator %s: %v", op.Task.Pred, err)
+	}
+	if op.Effects, err = parseLiterals(effects); err != nil {
+		return fmt.Errorf("operator %s: %v", op.Task.Pred, err)
+	}
+	vars := variables(op.Task)
+	for _, l := range op.Effects {
+		for _, x := range l.Args {
+			if isVariable(x) && !vars[x] {
+				return fmt.Errorf("operator %s: effect %v uses %s, which is not a parameter", op.Task.Pred, l, x)
+			}
+		}
+	}
+	h.Operators = append(h.Operators, op)
+	return nil
+}
+
+// AddMethod adds a method, name, decomposing task when its preconditions
+// hold into subtasks, written as atoms one after another. The name must
+// not be empty, as an empty Method marks a primitive Node.
+func (h *HTN) AddMethod(name, task, pre, subtasks string) error {
+	if name == "" {
+		return fmt.Errorf("method for %s has no name", task)
+	}
+	m := &Method{Name: name}
+	var err error
+	if m.Task, err = parseTask(task); err != nil {
+		return err
+	}
+	if m.Pre, err = parseLiterals(pre); err != nil {
+		return fmt.Errorf("method %s: %v", name, err)
+	}
+	if m.Subtasks, err = parseAtoms(subtasks); err != nil {
+		return fmt.Errorf("method %s: %v", name, err)
+	}
+	vars := variables(m.Task)
+	for _, l := range m.Pre {
+		if !l.Neg {
+			for v := range variables(l.Atom) {
+				vars[v] = true
+			}
+		}
+	}
+	for _, t := range m.Subtasks {
+		for _, x := range t.Args {
+			if isVariable(x) && !vars[x] {
+				return fmt.Errorf("method %s: subtask %v uses %s, which is not bound", name, t, x)
+			}
+		}
+	}
+	h.Methods = append(h.Methods, m)
+	return nil
+}
+
+func isVariable(x string) bool {
+	return strings.HasPrefix(x, "?")
+}
+
+func variables(a Atom) map[string]bool {
+	vars := map[string]bool{}
+	for _, x := range a.Args {
+		if isVariable(x) {
+			vars[x] = true
+		}
+	}
+	return vars
+}
+
+// htnState is a set of ground atoms. It is not changed once made.
+type htnState struct {
+	atoms map[string]Atom
+}
+
+func (s htnState) apply(effects []Literal, bind map[string]string) htnState {
+	next := htnState{atoms: map[string]Atom{}}
+	for k, a := range s.atoms {
+		next.atoms[k] = a
+	}
+	for _, l := range effects {
+		if l.Neg {
+			a, _ := substitute(l.Atom, bind)
+			delete(next.atoms, a.String())
+		}
+	}
+	for _, l := range effects {
+		if !l.Neg {
+			a, _ := substitute(l.Atom, bind)
+			next.atoms[a.String()] = a
+		}
+	}
+	return next
+}
+
+// sorted returns the atoms of s in order, so that matching is repeatable.
+func (s htnState) sorted() []Atom {
+	keys := []string{}
+	for k := range s.atoms {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	aa := []Atom{}
+	for _, k := range keys {
+		aa = append(aa, s.atoms[k])
+	}
+	return aa
+}
+
+// satisfy calls found with each extension of bind under which the
+// literals hold in s, until found returns true. A negated literal with
+// variables still unbound holds if no atom matches it.
+func (s htnState) satisfy(ll []Literal, bind map[string]string, found func(map[string]string) bool) bool {
+	if len(ll) == 0 {
+		return found(bind)
+	}
+	l := ll[0]
+	if l.Neg {
+		for _, a := range s.sorted() {
+			if _, ok := unify(l.Atom, a, bind); ok {
+				return false
+			}
+		}
+		return s.satisfy(ll[1:], bind, found)
+	}
+	for _, a := range s.sorted() {
+		if b, ok := unify(l.Atom, a, bind); ok && s.satisfy(ll[1:], b, found) {
+			return true
+		}
+	}
+	return false
+}
+
+// unify extends bind so that pattern, whose variables may be bound,
+// matches the ground atom a.
+func unify(pattern, a Atom, bind map[string]string) (map[string]string, bool) {
+	if pattern.Pred != a.Pred || len(pattern.Args) != len(a.Args) {
+		return nil, false
+	}
+	b := map[string]string{}
+	for k, v := range bind {
+		b[k] = v
+	}
+	for i, x := range pattern.Args {
+		if isVariable(x) {
+			if v, ok := b[x]; ok {
+				x = v
+			} else {
+				b[x] = a.Args[i]
+				continue
+			}
+		}
+		if x != a.Args[i] {
+			return nil, false
+		}
+	}
+	return b, true
+}
+
+// agendaItem is a task still to be done, and its place in the tree.
+type agendaItem struct {
+	node  *Node
+	depth int
+}
+
+// Plan returns the primitive actions that do tasks, written as atoms one
+// after another, in order from a state in which exactly the atoms of
+// state hold, together with the decomposition tree of each task. Each
+// action has the number of its operator as ID and the ground task as
+// Name.
+//
+// Tasks are done left to right, as in SHOP, so the state is known when
+// each is decomposed. Methods are tried in the order added, and
+// operators and methods backtrack over every binding of their
+// preconditions.
+//
+// Pseudocode after Ghallab, Nau and Traverso, Automated Planning, figure
+// 11.1:
+//
+//	function TFD(s, tasks) returns a plan, or failure
+//	    if tasks is empty then return the empty plan
+//	    t := FIRST(tasks)
+//	    if t is primitive then
+//	        for each ground instance a of an operator doing t, applicable in s do
+//	            plan := TFD(RESULT(s, a), REST(tasks))
+//	            if plan ≠ failure then return [a] + plan
+//	    else
+//	        for each ground instance m of a method for t, applicable in s do
+//	            plan := TFD(s, SUBTASKS(m) + REST(tasks))
+//	            if plan ≠ failure then return plan
+//	    return failure
+func (h *HTN) Plan(state, tasks string) ([]search.Action, []*Node, error) {
+	init, err := parseAtoms(state)
+	if err != nil {
+		return nil, nil, fmt.Errorf("state: %v", err)
+	}
+	tt, err := parseAtoms(tasks)
+	if err != nil {
+		return nil, nil, fmt.Errorf("tasks: %v", err)
+	}
+	s := htnState{atoms: map[string]Atom{}}
+	for _, a := range init {
+		if len(variables(a)) > 0 {
+			return nil, nil, fmt.Errorf("state: %v is not ground", a)
+		}
+		s.atoms[a.String()] = a
+	}
+	roots := []*Node{}
+	agenda := []agendaItem{}
+	for _, t := range tt {
+		if len(variables(t)) > 0 {
+			return nil, nil, fmt.Errorf("tasks: %v is not ground", t)
+		}
+		n := &Node{Task: t}
+		roots = append(roots, n)
+		agenda = append(agenda, agendaItem{n, 1})
+	}
+	for _, it := range agenda {
+		if err := h.check(it.node.Task); err != nil {
+			return nil, nil, err
+		}
+	}
+	h.Decompositions, h.Backtracks = 0, 0
+	p := &htnPlanner{h: h}
+	ok, err := p.seek(s, agenda, []search.Action{})
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		return nil, nil, fmt.Errorf("tasks cannot be done")
+	}
+	return p.plan, roots, nil
+}
+
+// check reports a task that no operator or method does.
+func (h *HTN) check(t Atom) error {
+	for _, op := range h.Operators {
+		if op.Task.Pred == t.Pred && len(op.Task.Args) == len(t.Args) {
+			return nil
+		}
+	}
+	for _, m := range h.Methods {
+		if m.Task.Pred == t.Pred && len(m.Task.Args) == len(t.Args) {
+			return nil
+		}
+	}
+	return fmt.Errorf("no operator or method for %v", t)
+}
+
+type htnPlanner struct {
+	h    *HTN
+	plan []search.Action
+}
+
+// seek reports whether the tasks on the agenda can be done from s, after
+// the actions in plan, setting p.plan if so. It returns an error if a task
+// has no operator or method.
+func (p *htnPlanner) seek(s htnState, agenda []agendaItem, plan []search.Action) (bool, error) {
+	if len(agenda) == 0 {
+		p.plan = plan
+		return true, nil
+	}
+	it, rest := agenda[0], agenda[1:]
+	t := it.node.Task
+	if err := p.h.check(t); err != nil {
+		return false, err
+	}
+	if p.h.MaxDepth > 0 && it.depth > p.h.MaxDepth {
+		return false, nil
+	}
+	var ok bool
+	var err error
+	for i, op := range p.h.Operators {
+		bind, match := unify(op.Task, t, nil)
+		if !match {
+			continue
+		}
+		s.satisfy(op.Pre, bind, func(b map[string]string) bool {
+			a := search.Action{ID: i, Name: t.String()}
+			it.node.Action = a
+			ok, err = p.seek(s.apply(op.Effects, b), rest, append(plan[:len(plan):len(plan)], a))
+			if ok || err != nil {
+				return true
+			}
+			p.h.Backtracks++
+			return false
+		})
+		if ok || err != nil {
+			return ok, err
+		}
+	}
+	for _, m := range p.h.Methods {
+		bind, match := unify(m.Task, t, nil)
+		if !match {
+			continue
+		}
+		s.satisfy(m.Pre, bind, func(b map[string]string) bool {
+			p.h.Decompositions++
+			it.node.Method, it.node.Subtasks = m.Name, nil
+			next := []agendaItem{}
+			for _, st := range m.Subtasks {
+				n := &Node{}
+				n.Task, _ = substitute(st, b)
+				it.node.Subtasks = append(it.node.Subtasks, n)
+				next = append(next, agendaItem{n, it.depth + 1})
+			}
+			ok, err = p.seek(s, append(next, rest...), plan)
+			if ok || err != nil {
+				return true
+			}
+			p.h.Backtracks++
+			it.node.Method, it.node.Subtasks = "", nil
+			return false
+		})
+		if ok || err != nil {
+			return ok, err
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,164 @@
+package planning
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// travel is the SHOP example domain: walk if near, otherwise take a taxi
+// if there is cash to pay for it.
+func travel(t testing.TB, taxiFirst bool) *HTN {
+	h := &HTN{}
+	for _, op := range [][3]string{
+		{"(!walk ?from ?to)", "(at ?from)", "(not (at ?from)) (at ?to)"},
+		{"(!call-taxi ?loc)", "", "(taxi-at ?loc)"},
+		{"(!ride-taxi ?from ?to)", "(at ?from) (taxi-at ?from)",
+			"(not (at ?from)) (at ?to) (not (taxi-at ?from)) (taxi-at ?to)"},
+		{"(!pay-driver)", "(cash)", "(not (cash))"},
+	} {
+		if err := h.AddOperator(op[0], op[1], op[2]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	methods := [][4]string{
+		{"by-foot", "(travel ?x ?y)", "(near ?x ?y)", "(!walk ?x ?y)"},
+		{"by-taxi", "(travel ?x ?y)", "(cash)", "(!call-taxi ?x) (!ride-taxi ?x ?y) (!pay-driver)"},
+	}
+	if taxiFirst {
+		methods[0], methods[1] = methods[1], methods[0]
+	}
+	for _, m := range methods {
+		if err := h.AddMethod(m[0], m[1], m[2], m[3]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return h
+}
+
+func ExampleHTN_Plan() {
+	h := &HTN{}
+	h.AddOperator("(!walk ?from ?to)", "(at ?from)", "(not (at ?from)) (at ?to)")
+	h.AddOperator("(!call-taxi ?loc)", "", "(taxi-at ?loc)")
+	h.AddOperator("(!ride-taxi ?from ?to)", "(at ?from) (taxi-at ?from)",
+		"(not (at ?from)) (at ?to) (not (taxi-at ?from)) (taxi-at ?to)")
+	h.AddOperator("(!pay-driver)", "(cash)", "(not (cash))")
+	h.AddMethod("by-foot", "(travel ?x ?y)", "(near ?x ?y)", "(!walk ?x ?y)")
+	h.AddMethod("by-taxi", "(travel ?x ?y)", "(cash)", "(!call-taxi ?x) (!ride-taxi ?x ?y) (!pay-driver)")
+
+	plan, tree, err := h.Plan("(at home) (cash) (near park zoo)", "(travel home park) (travel park zoo)")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, a := range plan {
+		fmt.Println(a.ID, a.Name)
+	}
+	for _, n := range tree {
+		fmt.Print(n)
+	}
+	// Output:
+	// 1 (!call-taxi home)
+	// 2 (!ride-taxi home park)
+	// 3 (!pay-driver)
+	// 0 (!walk park zoo)
+	// (travel home park) by by-taxi
+	//   (!call-taxi home)
+	//   (!ride-taxi home park)
+	//   (!pay-driver)
+	// (travel park zoo) by by-foot
+	//   (!walk park zoo)
+}
+
+func names(h *HTN, state, tasks string) (string, error) {
+	plan, _, err := h.Plan(state, tasks)
+	nn := []string{}
+	for _, a := range plan {
+		nn = append(nn, a.Name)
+	}
+	return strings.Join(nn, " "), err
+}
+
+func TestHTNBacktrack(t *testing.T) {
+	// With the taxi tried first, the first trip spends the cash needed
+	// for the second, so the planner must go back and walk instead.
+	h := travel(t, true)
+	got, err := names(h, "(at home) (cash) (near home park)", "(travel home park) (travel park zoo)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "(!walk home park) (!call-taxi park) (!ride-taxi park zoo) (!pay-driver)"
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if h.Backtracks == 0 || h.Decompositions != 3 {
+		t.Errorf("got %d backtracks, %d decompositions", h.Backtracks, h.Decompositions)
+	}
+
+	_, err = names(h, "(at home) (cash)", "(travel home park) (travel park zoo)")
+	if err == nil || err.Error() != "tasks cannot be done" {
+		t.Errorf("got error %v", err)
+	}
+}
+
+func TestHTNRecursive(t *testing.T) {
+	// Clearing the table binds the block to move from the state, and
+	// repeats until no block is on another.
+	h := &HTN{}
+	for _, err := range []error{
+		h.AddOperator("(!unstack ?x ?y)", "(on ?x ?y) (clear ?x)",
+			"(not (on ?x ?y)) (ontable ?x) (clear ?y)"),
+		h.AddMethod("unstack-one", "(clear-all)", "(on ?x ?y) (clear ?x)", "(!unstack ?x ?y) (clear-all)"),
+		h.AddMethod("done", "(clear-all)", "(not (on ?x ?y))", ""),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	plan, tree, err := h.Plan("(on a b) (on b c) (ontable c) (clear a) (on d e) (clear d) (ontable e)", "(clear-all)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []string{}
+	for _, a := range plan {
+		got = append(got, a.Name)
+	}
+	if s := strings.Join(got, " "); s != "(!unstack a b) (!unstack b c) (!unstack d e)" {
+		t.Errorf("got %s", s)
+	}
+	depth := 0
+	for n := tree[0]; !n.Primitive(); n = n.Subtasks[len(n.Subtasks)-1] {
+		if depth++; len(n.Subtasks) == 0 {
+			break
+		}
+	}
+	if depth != 4 || tree[0].Subtasks[0].Action != plan[0] {
+		t.Errorf("unexpected tree\n%v", tree[0])
+	}
+
+	h.MaxDepth = 3
+	if _, _, err := h.Plan("(on a b) (on b c) (clear a) (on d e) (clear d)", "(clear-all)"); err == nil {
+		t.Errorf("plan found deeper than MaxDepth")
+	}
+}
+
+func TestHTNErrors(t *testing.T) {
+	h := travel(t, false)
+	for _, c := range []struct {
+		err  error
+		want string
+	}{
+		{h.AddOperator("(!fly ?x)", "", "(at ?y)"), "operator !fly: effect (at ?y) uses ?y, which is not a parameter"},
+		{h.AddOperator("(!fly ?x) (!swim ?x)", "", ""), "expected one task"},
+		{h.AddMethod("m", "(go ?x)", "(not (near ?x ?y))", "(!walk ?x ?y)"), "method m: subtask (!walk ?x ?y) uses ?y, which is not bound"},
+		{h.AddMethod("m", "(go ?x)", "(or (a) (b))", ""), "method m: line 1: expected an atom"},
+		{h.AddMethod("", "(go ?x)", "", "(!walk ?x)"), "method for (go ?x) has no name"},
+		{func() error { _, err := names(h, "(at home)", "(fly home)"); return err }(), "no operator or method for (fly home)"},
+		{func() error { _, err := names(h, "(at ?x)", "(travel home park)"); return err }(), "state: (at ?x) is not ground"},
+		{func() error { _, err := names(h, "(at home)", "(travel home ?x)"); return err }(), "tasks: (travel home ?x) is not ground"},
+	} {
+		if c.err == nil || !strings.Contains(c.err.Error(), c.want) {
+			t.Errorf("got error %v, want %s", c.err, c.want)
+		}
+	}
+}
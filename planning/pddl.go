@@ -0,0 +1,465 @@
+// Package planning -- given a description of the world in PDDL: the actions
+// available, with their preconditions and effects, a start state and a goal.
+// Find a sequence of actions that reaches the goal.
+package planning
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Domain is a planning domain: the types of object, the predicates
+// describing the world and action schemas for changing it.
+type Domain struct {
+	Name       string
+	Types      map[string]string // parent of each type, "" for object
+	Constants  []Param
+	Predicates []Predicate
+	Actions    []*Schema
+}
+
+// Problem is a planning problem in a Domain.
+type Problem struct {
+	Name    string
+	Domain  string
+	Objects []Param
+	Init    []Atom
+	Goal    []Literal
+}
+
+// Param is a typed name: an action parameter, such as ?x, or an object.
+type Param struct {
+	Name, Type string
+}
+
+// Predicate declares a predicate and the types of its arguments.
+type Predicate struct {
+	Name   string
+	Params []Param
+}
+
+// Atom is a predicate applied to arguments, which are objects or, in
+// a schema, parameters.
+type Atom struct {
+	Pred string
+	Args []string
+}
+
+func (a Atom) String() string {
+	if len(a.Args) == 0 {
+		return "(" + a.Pred + ")"
+	}
+	return "(" + a.Pred + " " + strings.Join(a.Args, " ") + ")"
+}
+
+// Literal is an atom or its negation.
+type Literal struct {
+	Atom
+	Neg bool
+}
+
+func (l Literal) String() string {
+	if l.Neg {
+		return "(not " + l.Atom.String() + ")"
+	}
+	return l.Atom.String()
+}
+
+// Schema is an action schema in STRIPS form: its effects add the
+// positive literals and delete the negated ones.
+type Schema struct {
+	Name    string
+	Params  []Param
+	Pre     []Literal
+	Effects []Literal
+}
+
+// sexp is an atom, such as a name, or a list of sexps.
+type sexp struct {
+	atom string
+	list []*sexp
+	line int
+}
+
+func (s *sexp) isList() bool {
+	return s.atom == ""
+}
+
+// head returns the atom starting list s, or "".
+func (s *sexp) head() string {
+	if !s.isList() || len(s.list) == 0 {
+		return ""
+	}
+	return s.list[0].atom
+}
+
+func (s *sexp) errorf(format string, a ...interface{}) error {
+	return fmt.Errorf("line %d: %s", s.line, fmt.Sprintf(format, a...))
+}
+
+// parseSexp reads one parenthesised expression. PDDL is case
+// insensitive, so names are lowercased, and ; starts a comment.
+func parseSexp(r io.Reader) (*sexp, error) {
+	type token struct {
+		text string
+		line int
+	}
+	toks := []token{}
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := sc.Text()
+		if i := strings.IndexByte(text, ';'); i >= 0 {
+			text = text[:i]
+		}
+		text = strings.NewReplacer("(", " ( ", ")", " ) ").Replace(strings.ToLower(text))
+		for _, f := range strings.Fields(text) {
+			toks = append(toks, token{f, line})
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	var parse func(i int) (*sexp, int, error)
+	parse = func(i int) (*sexp, int, error) {
+		t := toks[i]
+		switch t.text {
+		case ")":
+			return nil, 0, fmt.Errorf("line %d: unexpected )", t.line)
+		case "(":
+			s := &sexp{line: t.line, list: []*sexp{}}
+			for i++; i < len(toks) && toks[i].text != ")"; {
+				var e *sexp
+				var err error
+				if e, i, err = parse(i); err != nil {
+					return nil, 0, err
+				}
+				s.list = append(s.list, e)
+			}
+			if i == len(toks) {
+				return nil, 0, fmt.Errorf("line %d: ( not closed", t.line)
+			}
+			return s, i + 1, nil
+		}
+		return &sexp{atom: t.text, line: t.line}, i + 1, nil
+	}
+	if len(toks) == 0 {
+		return nil, fmt.Errorf("no PDDL found")
+	}
+	s, i, err := parse(0)
+	if err != nil {
+		return nil, err
+	}
+	if i < len(toks) {
+		return nil, fmt.Errorf("line %d: text after the end", toks[i].line)
+	}
+	if !s.isList() {
+		return nil, fmt.Errorf("line %d: expected (define ...)", s.line)
+	}
+	return s, nil
+}
+
+// define checks that s is (define (kind name) ...) and returns the name
+// and the sections that follow.
+func define(s *sexp, kind string) (string, []*sexp, error) {
+	if s.head() != "define" || len(s.list) < 2 || s.list[1].head() != kind || len(s.list[1].list) != 2 {
+		return "", nil, s.errorf("expected (define (%s name) ...)", kind)
+	}
+	return s.list[1].list[1].atom, s.list[2:], nil
+}
+
+// ParseDomain reads a domain written in the STRIPS subset of PDDL, with
+// typing and negative preconditions. Other requirements, such as
+// conditional effects, quantifiers, equality and action costs, are not
+// supported.
+func ParseDomain(r io.Reader) (*Domain, error) {
+	s, err := parseSexp(r)
+	if err != nil {
+		return nil, err
+	}
+	d := &Domain{Types: map[string]string{"object": ""}}
+	var sections []*sexp
+	if d.Name, sections, err = define(s, "domain"); err != nil {
+		return nil, err
+	}
+	for _, sec := range sections {
+		if !sec.isList() || len(sec.list) == 0 {
+			return nil, sec.errorf("expected a section")
+		}
+		switch sec.head() {
+		case ":requirements":
+			for _, r := range sec.list[1:] {
+				switch r.atom {
+				case ":strips", ":typing", ":negative-preconditions":
+				default:
+					return nil, r.errorf("requirement %s not supported", r.atom)
+				}
+			}
+		case ":types":
+			tt, err := typedList(sec.list[1:], false)
+			if err != nil {
+				return nil, err
+			}
+			for _, t := range tt {
+				if t.Name != "object" {
+					d.Types[t.Name] = t.Type
+				}
+			}
+		case ":constants":
+			if d.Constants, err = typedList(sec.list[1:], false); err != nil {
+				return nil, err
+			}
+		case ":predicates":
+			for _, p := range sec.list[1:] {
+				if !p.isList() || p.head() == "" {
+					return nil, p.errorf("expected a predicate")
+				}
+				params, err := typedList(p.list[1:], true)
+				if err != nil {
+					return nil, err
+				}
+				d.Predicates = append(d.Predicates, Predicate{p.head(), params})
+			}
+		case ":action":
+			a, err := parseAction(sec)
+			if err != nil {
+				return nil, err
+			}
+			d.Actions = append(d.Actions, a)
+		default:
+			return nil, sec.errorf("section %s not supported", sec.head())
+		}
+	}
+	return d, d.check()
+}
+
+// typedList parses names, each group of which may be followed by - and
+// a type, as in "a b - block c". Names without a type are objects.
+// Variables start with ? and are required in parameter lists.
+func typedList(ss []*sexp, variables bool) ([]Param, error) {
+	pp := []Param{}
+	untyped := 0
+	for i := 0; i < len(ss); i++ {
+		s := ss[i]
+		if !s.isList() && s.atom == "-" {
+			if i+1 == len(ss) || ss[i+1].isList() {
+				return nil, s.errorf("expected a type after -")
+			}
+			i++
+			for j := untyped; j < len(pp); j++ {
+				pp[j].Type = ss[i].atom
+			}
+			untyped = len(pp)
+			continue
+		}
+		if s.isList() {
+			return nil, s.errorf("expected a name, not a list (either is not supported)")
+		}
+		if strings.HasPrefix(s.atom, "?") != variables {
+			return nil, s.errorf("unexpected name %s", s.atom)
+		}
+		pp = append(pp, Param{s.atom, "object"})
+	}
+	return pp, nil
+}
+
+func parseAction(s *sexp) (*Schema, error) {
+	if len(s.list) < 2 || s.list[1].isList() {
+		return nil, s.errorf("expected an action name")
+	}
+	a := &Schema{Name: s.list[1].atom}
+	for i := 2; i < len(s.list); i += 2 {
+		key := s.list[i].atom
+		if i+1 == len(s.list) {
+			return nil, s.list[i].errorf("%s of %s has no value", key, a.Name)
+		}
+		val := s.list[i+1]
+		var err error
+		switch key {
+		case ":parameters":
+			if !val.isList() {
+				return nil, val.errorf("expected parameters of %s", a.Name)
+			}
+			a.Params, err = typedList(val.list, true)
+		case ":precondition":
+			a.Pre, err = conjunction(val)
+		case ":effect":
+			a.Effects, err = conjunction(val)
+		default:
+			err = s.list[i].errorf("%s not supported", key)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// conjunction parses a literal or (and ...) of literals. () is empty.
+func conjunction(s *sexp) ([]Literal, error) {
+	if !s.isList() {
+		return nil, s.errorf("expected a condition, not %s", s.atom)
+	}
+	ss := []*sexp{s}
+	switch {
+	case len(s.list) == 0:
+		return []Literal{}, nil
+	case s.head() == "and":
+		ss = s.list[1:]
+	}
+	ll := []Literal{}
+	for _, e := range ss {
+		l, err := literal(e)
+		if err != nil {
+			return nil, err
+		}
+		ll = append(ll, l)
+	}
+	return ll, nil
+}
+
+func literal(s *sexp) (Literal, error) {
+	if s.head() == "not" && len(s.list) == 2 {
+		a, err := atom(s.list[1])
+		return Literal{a, true}, err
+	}
+	a, err := atom(s)
+	return Literal{Atom: a}, err
+}
+
+func atom(s *sexp) (Atom, error) {
+	switch s.head() {
+	case "", "and", "or", "not", "imply", "forall", "exists", "when", "=":
+		return Atom{}, s.errorf("expected an atom")
+	}
+	a := Atom{Pred: s.head()}
+	for _, e := range s.list[1:] {
+		if e.isList() {
+			return Atom{}, e.errorf("expected a name in %s", a.Pred)
+		}
+		a.Args = append(a.Args, e.atom)
+	}
+	return a, nil
+}
+
+// check reports types that are not declared and atoms in the actions
+// that do not match a predicate or use undeclared parameters.
+func (d *Domain) check() error {
+	for t, parent := range d.Types {
+		if _, ok := d.Types[parent]; parent != "" && !ok {
+			return fmt.Errorf("type %s of %s not declared", parent, t)
+		}
+		seen := map[string]bool{}
+		for u := t; u != ""; u = d.Types[u] {
+			if seen[u] {
+				return fmt.Errorf("type %s is its own ancestor", t)
+			}
+			seen[u] = true
+		}
+	}
+	for _, c := range d.Constants {
+		if err := d.checkType(c); err != nil {
+			return err
+		}
+	}
+	for _, a := range d.Actions {
+		names := map[string]bool{}
+		for _, p := range a.Params {
+			if err := d.checkType(p); err != nil {
+				return fmt.Errorf("action %s: %v", a.Name, err)
+			}
+			names[p.Name] = true
+		}
+		for _, l := range append(append([]Literal{}, a.Pre...), a.Effects...) {
+			if err := d.checkAtom(l.Atom, names); err != nil {
+				return fmt.Errorf("action %s: %v", a.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (d *Domain) checkType(p Param) error {
+	if _, ok := d.Types[p.Type]; !ok {
+		return fmt.Errorf("type %s of %s not declared", p.Type, p.Name)
+	}
+	return nil
+}
+
+// checkAtom reports an atom that does not match a predicate, or whose
+// variable arguments are not in vars.
+func (d *Domain) checkAtom(a Atom, vars map[string]bool) error {
+	p := d.predicate(a.Pred)
+	if p == nil {
+		return fmt.Errorf("predicate %s not declared", a.Pred)
+	}
+	if len(a.Args) != len(p.Params) {
+		return fmt.Errorf("%v should have %d arguments", a, len(p.Params))
+	}
+	for _, x := range a.Args {
+		if strings.HasPrefix(x, "?") && !vars[x] {
+			return fmt.Errorf("%v uses %s, which is not a parameter", a, x)
+		}
+	}
+	return nil
+}
+
+func (d *Domain) predicate(name string) *Predicate {
+	for i := range d.Predicates {
+		if d.Predicates[i].Name == name {
+			return &d.Predicates[i]
+		}
+	}
+	return nil
+}
+
+// ParseProblem reads a problem written in PDDL. The goal is a
+// conjunction of literals.
+func ParseProblem(r io.Reader) (*Problem, error) {
+	s, err := parseSexp(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &Problem{}
+	var sections []*sexp
+	if p.Name, sections, err = define(s, "problem"); err != nil {
+		return nil, err
+	}
+	for _, sec := range sections {
+		if !sec.isList() || len(sec.list) == 0 {
+			return nil, sec.errorf("expected a section")
+		}
+		switch sec.head() {
+		case ":domain":
+			if len(sec.list) != 2 {
+				return nil, sec.errorf("expected (:domain name)")
+			}
+			p.Domain = sec.list[1].atom
+		case ":requirements":
+		case ":objects":
+			if p.Objects, err = typedList(sec.list[1:], false); err != nil {
+				return nil, err
+			}
+		case ":init":
+			for _, e := range sec.list[1:] {
+				a, err := atom(e)
+				if err != nil {
+					return nil, err
+				}
+				p.Init = append(p.Init, a)
+			}
+		case ":goal":
+			if len(sec.list) != 2 {
+				return nil, sec.errorf("expected (:goal condition)")
+			}
+			if p.Goal, err = conjunction(sec.list[1]); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, sec.errorf("section %s not supported", sec.head())
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,79 @@
+package planning
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func readDomain(t *testing.T, name string) *Domain {
+	t.Helper()
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d, err := ParseDomain(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func readProblem(t *testing.T, name string) *Problem {
+	t.Helper()
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	p, err := ParseProblem(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestParseDomain(t *testing.T) {
+	d := readDomain(t, "testdata/logistics-domain.pddl")
+	if d.Name != "logistics" || len(d.Predicates) != 3 || len(d.Actions) != 6 {
+		t.Fatalf("unexpected domain %+v", d)
+	}
+	if d.Types["truck"] != "vehicle" || d.Types["vehicle"] != "physobj" || d.Types["city"] != "object" {
+		t.Errorf("unexpected types %v", d.Types)
+	}
+	a := d.Actions[4]
+	if a.Name != "drive-truck" || len(a.Params) != 4 || a.Params[1] != (Param{"?loc-from", "place"}) {
+		t.Errorf("unexpected action %+v", a)
+	}
+	if len(a.Pre) != 3 || len(a.Effects) != 2 || !a.Effects[0].Neg || a.Effects[1].String() != "(at ?truck ?loc-to)" {
+		t.Errorf("unexpected conditions %v %v", a.Pre, a.Effects)
+	}
+
+	p := readProblem(t, "testdata/logistics-2.pddl")
+	if p.Domain != "logistics" || len(p.Objects) != 11 || len(p.Init) != 9 || len(p.Goal) != 2 {
+		t.Errorf("unexpected problem %+v", p)
+	}
+	if p.Objects[2] != (Param{"truck1", "truck"}) {
+		t.Errorf("unexpected object %v", p.Objects[2])
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, c := range []struct{ pddl, want string }{
+		{"(define (domain d) (:predicates (p ?x))", "line 1: ( not closed"},
+		{"(define (problem d))", "expected (define (domain name) ...)"},
+		{"(define (domain d) (:requirements :adl))", "requirement :adl not supported"},
+		{"(define (domain d) (:types a - b))", "type b of a not declared"},
+		{"(define (domain d) (:predicates (p ?x))\n(:action a :parameters (?x) :precondition (q ?x)))", "predicate q not declared"},
+		{"(define (domain d) (:predicates (p ?x))\n(:action a :parameters () :effect (p ?y)))", "uses ?y, which is not a parameter"},
+		{"(define (domain d) (:predicates (p ?x))\n(:action a :parameters (?x) :precondition (or (p ?x))))", "line 2: expected an atom"},
+		{"(define (domain d) (:predicates (p ?x))\n(:action a :parameters (?x) :effect (p ?x ?x)))", "should have 1 arguments"},
+		{"(define (domain d) (:predicates (p ?x)))) ; extra", "line 1: text after the end"},
+	} {
+		_, err := ParseDomain(strings.NewReader(c.pddl))
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%q: got error %v, want %s", c.pddl, err, c.want)
+		}
+	}
+}
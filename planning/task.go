@@ -0,0 +1,438 @@
+package planning
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/siuyin/ai/search"
+)
+
+// Task is a grounded planning problem. It is a search problem: a
+// search.NextStateter, search.Actionsner and search.StepCoster, with
+// every action costing 1, so Search, SearchDFS and the informed searches
+// of package search can solve it.
+//
+// Each set of true facts met is given a state ID, except that every
+// state meeting the goal is the single state returned by GoalState, which
+// is what search looks for.
+type Task struct {
+	Facts     []string // ground atoms, e.g. (on a b)
+	Operators []*Operator
+	Init      []int // facts true at the start; all others are false
+	Goal      []int // facts that must be true at the end
+	NegGoal   []int // facts that must be false at the end
+
+	index   map[string]int // of each fact
+	states  []bitset       // facts true in each state, by ID; 0 is the goal
+	ids     map[string]int // of each state, by its facts
+	users   [][]int        // actions with each fact as a positive precondition
+	preFree []int          // actions with no positive precondition
+}
+
+// goalID is the ID of the goal state.
+const goalID = 0
+
+func newTask() *Task {
+	return &Task{index: map[string]int{}, ids: map[string]int{}, states: []bitset{nil}}
+}
+
+// fact returns the number of fact f, adding it if new.
+func (t *Task) fact(f string) int {
+	i, ok := t.index[f]
+	if !ok {
+		i = len(t.Facts)
+		t.Facts = append(t.Facts, f)
+		t.index[f] = i
+	}
+	return i
+}
+
+// prune drops the actions whose positive preconditions cannot all be
+// made true, even if no action deleted anything.
+func (t *Task) prune() {
+	reached := make([]bool, len(t.Facts))
+	for _, f := range t.Init {
+		reached[f] = true
+	}
+	used := make([]bool, len(t.Operators))
+	for changed := true; changed; {
+		changed = false
+		for i, a := range t.Operators {
+			if used[i] || !all(a.Pre, reached) {
+				continue
+			}
+			used[i], changed = true, true
+			for _, f := range a.Add {
+				reached[f] = true
+			}
+		}
+	}
+	kept := []*Operator{}
+	for i, a := range t.Operators {
+		if used[i] {
+			kept = append(kept, a)
+		}
+	}
+	t.Operators = kept
+}
+
+func all(ff []int, set []bool) bool {
+	for _, f := range ff {
+		if !set[f] {
+			return false
+		}
+	}
+	return true
+}
+
+// link records the actions using each fact, for the heuristics.
+func (t *Task) link() {
+	t.users = make([][]int, len(t.Facts))
+	t.preFree = nil
+	for i, a := range t.Operators {
+		for _, f := range a.Pre {
+			t.users[f] = append(t.users[f], i)
+		}
+		if len(a.Pre) == 0 {
+			t.preFree = append(t.preFree, i)
+		}
+	}
+}
+
+// bitset is a set of facts.
+type bitset []uint64
+
+func (b bitset) has(f int) bool {
+	return b[f/64]&(1<<uint(f%64)) != 0
+}
+
+func (b bitset) set(f int) {
+	b[f/64] |= 1 << uint(f%64)
+}
+
+func (b bitset) clear(f int) {
+	b[f/64] &^= 1 << uint(f%64)
+}
+
+func (b bitset) key() string {
+	var sb strings.Builder
+	for _, w := range b {
+		for i := uint(0); i < 64; i += 8 {
+			sb.WriteByte(byte(w >> i))
+		}
+	}
+	return sb.String()
+}
+
+func (t *Task) newBitset() bitset {
+	return make(bitset, (len(t.Facts)+63)/64)
+}
+
+// state returns the search state of facts, or the goal state if facts
+// meet the goal.
+func (t *Task) state(facts bitset) search.State {
+	if t.meets(facts) {
+		if t.states[goalID] == nil {
+			t.states[goalID] = facts
+		}
+		return search.State{ID: goalID, Description: "goal"}
+	}
+	k := facts.key()
+	id, ok := t.ids[k]
+	if !ok {
+		id = len(t.states)
+		t.ids[k] = id
+		t.states = append(t.states, facts)
+	}
+	return search.State{ID: id}
+}
+
+func (t *Task) meets(facts bitset) bool {
+	for _, f := range t.Goal {
+		if !facts.has(f) {
+			return false
+		}
+	}
+	for _, f := range t.NegGoal {
+		if facts.has(f) {
+			return false
+		}
+	}
+	return true
+}
+
+// StartState returns the initial state.
+func (t *Task) StartState() search.State {
+	b := t.newBitset()
+	for _, f := range t.Init {
+		b.set(f)
+	}
+	return t.state(b)
+}
+
+// GoalState returns the state that stands for every state meeting the goal.
+func (t *Task) GoalState() search.State {
+	return search.State{ID: goalID, Description: "goal"}
+}
+
+// States returns the number of states met so far, a measure of the
+// work done by searches.
+func (t *Task) States() int {
+	return len(t.states)
+}
+
+// True returns the facts true in s, which must be a state met in search.
+func (t *Task) True(s search.State) []string {
+	ff := []string{}
+	if b := t.states[s.ID]; b != nil {
+		for f, name := range t.Facts {
+			if b.has(f) {
+				ff = append(ff, name)
+			}
+		}
+	}
+	sort.Strings(ff)
+	return ff
+}
+
+// Actions returns the actions applicable in s. The goal state has none,
+// since search stops there.
+func (t *Task) Actions(s search.State) []search.Action {
+	aa := []search.Action{}
+	if s.ID == goalID {
+		return aa
+	}
+	b := t.states[s.ID]
+	for i, a := range t.Operators {
+		if t.applicable(a, b) {
+			aa = append(aa, search.Action{ID: i, Name: a.Name})
+		}
+	}
+	return aa
+}
+
+func (t *Task) applicable(a *Operator, b bitset) bool {
+	for _, f := range a.Pre {
+		if !b.has(f) {
+			return false
+		}
+	}
+	for _, f := range a.NegPre {
+		if b.has(f) {
+			return false
+		}
+	}
+	return true
+}
+
+// NextState returns the state after action a in s: its deletes are
+// made false, then its adds true.
+func (t *Task) NextState(s search.State, a search.Action) search.State {
+	b := append(bitset{}, t.states[s.ID]...)
+	act := t.Operators[a.ID]
+	for _, f := range act.Del {
+		b.clear(f)
+	}
+	for _, f := range act.Add {
+		b.set(f)
+	}
+	return t.state(b)
+}
+
+// StepCost returns 1: plans are measured by their number of actions.
+func (t *Task) StepCost(s search.State, a search.Action, next search.State) float64 {
+	return 1
+}
+
+// Plan returns the names of the actions on the path from the start to
+// goal, a state returned by a search.
+func Plan(goal search.State) []string {
+	pp := goal.Path()
+	names := []string{}
+	for i := len(pp) - 2; i >= 0; i-- {
+		names = append(names, pp[i].ParentAction.Name)
+	}
+	return names
+}
+
+// Validate reports whether the named actions, applied in order from the
+// initial state, are each applicable and reach the goal.
+func (t *Task) Validate(plan []string) error {
+	byName := map[string]*Operator{}
+	for _, a := range t.Operators {
+		byName[a.Name] = a
+	}
+	b := t.newBitset()
+	for _, f := range t.Init {
+		b.set(f)
+	}
+	for i, name := range plan {
+		a, ok := byName[name]
+		if !ok || !t.applicable(a, b) {
+			return fmt.Errorf("step %d: %s is not applicable", i+1, name)
+		}
+		for _, f := range a.Del {
+			b.clear(f)
+		}
+		for _, f := range a.Add {
+			b.set(f)
+		}
+	}
+	if !t.meets(b) {
+		return fmt.Errorf("plan does not reach the goal")
+	}
+	return nil
+}
+
+// HMax estimates the number of actions from s to the goal as the
+// greatest cost of a goal fact, where the cost of a fact is that of
+// reaching it ignoring deletes, and an action costs 1 plus the greatest
+// cost of its preconditions. It never overestimates. It is +Inf if the
+// goal cannot be reached even ignoring deletes.
+// The second argument is ignored, so HMax is a search.Heuristic.
+func (t *Task) HMax(s, _ search.State) float64 {
+	if s.ID == goalID {
+		return 0
+	}
+	cost, _ := t.relaxed(s, math.Max)
+	return t.goalCost(cost, math.Max)
+}
+
+// HAdd is like HMax but sums costs where HMax takes their greatest,
+// assuming goals and preconditions are reached independently. It is
+// better informed but can overestimate.
+func (t *Task) HAdd(s, _ search.State) float64 {
+	if s.ID == goalID {
+		return 0
+	}
+	cost, _ := t.relaxed(s, sum)
+	return t.goalCost(cost, sum)
+}
+
+// HFF, the heuristic of the FF planner, estimates the number of actions
+// from s to the goal as the length of a plan that ignores deletes. The
+// plan is found backwards from the goal facts, achieving each with the
+// action by which HAdd reached it most cheaply. It can overestimate.
+func (t *Task) HFF(s, _ search.State) float64 {
+	if s.ID == goalID {
+		return 0
+	}
+	cost, best := t.relaxed(s, sum)
+	if math.IsInf(t.goalCost(cost, math.Max), 1) {
+		return math.Inf(1)
+	}
+	inPlan := map[int]bool{}
+	done := map[int]bool{}
+	open := append([]int{}, t.Goal...)
+	for len(open) > 0 {
+		f := open[len(open)-1]
+		open = open[:len(open)-1]
+		if done[f] || cost[f] == 0 {
+			continue
+		}
+		done[f] = true
+		a := best[f]
+		if inPlan[a] {
+			continue
+		}
+		inPlan[a] = true
+		open = append(open, t.Operators[a].Pre...)
+	}
+	return float64(len(inPlan))
+}
+
+func sum(a, b float64) float64 { return a + b }
+
+func (t *Task) goalCost(cost []float64, combine func(a, b float64) float64) float64 {
+	c := 0.0
+	for _, f := range t.Goal {
+		c = combine(c, cost[f])
+	}
+	return c
+}
+
+// relaxed returns the cost of reaching each fact from s ignoring deletes
+// and negative preconditions, combining the costs of preconditions with
+// combine, and the action that reaches each most cheaply. Costs are
+// found by a generalised Dijkstra's algorithm over facts: an action is
+// applied once its last precondition has its final cost.
+func (t *Task) relaxed(s search.State, combine func(a, b float64) float64) ([]float64, []int) {
+	b := t.states[s.ID]
+	cost := make([]float64, len(t.Facts))
+	best := make([]int, len(t.Facts))
+	q := &factQueue{}
+	for f := range cost {
+		cost[f], best[f] = math.Inf(1), -1
+		if b.has(f) {
+			cost[f] = 0
+			q.push(f, 0)
+		}
+	}
+	waiting := make([]int, len(t.Operators)) // preconditions not yet final
+	for i, a := range t.Operators {
+		waiting[i] = len(a.Pre)
+	}
+	apply := func(i int) {
+		a := t.Operators[i]
+		c := 0.0
+		for _, f := range a.Pre {
+			c = combine(c, cost[f])
+		}
+		c++
+		for _, f := range a.Add {
+			if c < cost[f] {
+				cost[f], best[f] = c, i
+				q.push(f, c)
+			}
+		}
+	}
+	for _, i := range t.preFree {
+		apply(i)
+	}
+	final := make([]bool, len(t.Facts))
+	for q.Len() > 0 {
+		f, c := q.pop()
+		if final[f] || c > cost[f] {
+			continue
+		}
+		final[f] = true
+		for _, i := range t.users[f] {
+			if waiting[i]--; waiting[i] == 0 {
+				apply(i)
+			}
+		}
+	}
+	return cost, best
+}
+
+// factQueue is a priority queue of facts by cost.
+type factQueue struct {
+	items []factItem
+}
+
+type factItem struct {
+	fact int
+	cost float64
+}
+
+func (q *factQueue) Len() int           { return len(q.items) }
+func (q *factQueue) Less(i, j int) bool { return q.items[i].cost < q.items[j].cost }
+func (q *factQueue) Swap(i, j int)      { q.items[i], q.items[j] = q.items[j], q.items[i] }
+func (q *factQueue) Push(x interface{}) { q.items = append(q.items, x.(factItem)) }
+func (q *factQueue) Pop() interface{} {
+	it := q.items[len(q.items)-1]
+	q.items = q.items[:len(q.items)-1]
+	return it
+}
+
+func (q *factQueue) push(f int, c float64) {
+	heap.Push(q, factItem{f, c})
+}
+
+func (q *factQueue) pop() (int, float64) {
+	it := heap.Pop(q).(factItem)
+	return it.fact, it.cost
+}
@@ -0,0 +1,181 @@
+package planning
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/siuyin/ai/search"
+)
+
+func ground(t *testing.T, domain, problem string) *Task {
+	t.Helper()
+	task, err := Ground(readDomain(t, domain), readProblem(t, problem))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return task
+}
+
+func ExampleGround() {
+	df, _ := os.Open("testdata/blocks-domain.pddl")
+	defer df.Close()
+	d, _ := ParseDomain(df)
+	pf, _ := os.Open("testdata/blocks-sussman.pddl")
+	defer pf.Close()
+	p, _ := ParseProblem(pf)
+
+	t, _ := Ground(d, p)
+	g, _ := search.SearchAStar(t.GoalState(), t.StartState(), t, t, t, t.HMax)
+	for _, a := range Plan(g) {
+		fmt.Println(a)
+	}
+	// Output:
+	// (unstack c a)
+	// (put-down c)
+	// (pick-up b)
+	// (stack b c)
+	// (pick-up a)
+	// (stack a b)
+}
+
+func TestGround(t *testing.T) {
+	task := ground(t, "testdata/blocks-domain.pddl", "testdata/blocks-sussman.pddl")
+	if len(task.Operators) != 24 {
+		t.Errorf("got %d blocks actions, want 24", len(task.Operators))
+	}
+
+	// Trucks only drive within their city, which is static, and planes
+	// only fly between airports, by type.
+	task = ground(t, "testdata/logistics-domain.pddl", "testdata/logistics-2.pddl")
+	if len(task.Facts) != 26 || len(task.Operators) != 36 {
+		t.Errorf("got %d facts and %d actions, want 26 and 36", len(task.Facts), len(task.Operators))
+	}
+	for _, a := range task.Operators {
+		if strings.HasPrefix(a.Name, "(drive-truck truck1 ") && strings.Contains(a.Name, "2") ||
+			strings.HasPrefix(a.Name, "(fly-airplane") && strings.Contains(a.Name, "post") {
+			t.Errorf("unexpected action %s", a.Name)
+		}
+	}
+	for _, f := range task.Facts {
+		if strings.HasPrefix(f, "(in-city") {
+			t.Errorf("static fact %s kept", f)
+		}
+	}
+}
+
+func TestGroundErrors(t *testing.T) {
+	d := readDomain(t, "testdata/logistics-domain.pddl")
+	for _, c := range []struct{ problem, want string }{
+		{"(define (problem p) (:domain logistics) (:objects c - city) (:init) (:goal (in-city d c)))", "unknown object d"},
+		{"(define (problem p) (:domain logistics) (:objects l - location c - city) (:init) (:goal (in-city l c)))", "goal (in-city l c) can never hold"},
+		{"(define (problem p) (:domain logistics) (:objects c - city) (:init (at c c)) (:goal (and)))", "init: (at c c): c is not a physobj"},
+		{"(define (problem p) (:domain blocks) (:init) (:goal (and)))", "problem p is for domain blocks, not logistics"},
+		{"(define (problem p) (:domain logistics) (:objects c - city c - city) (:init) (:goal (and)))", "object c declared twice"},
+	} {
+		p, err := ParseProblem(strings.NewReader(c.problem))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = Ground(d, p)
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%q: got error %v, want %s", c.problem, err, c.want)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	for _, c := range []struct {
+		domain, problem string
+		optimal         int
+	}{
+		{"blocks-domain.pddl", "blocks-sussman.pddl", 6},
+		{"blocks-domain.pddl", "blocks-6.pddl", 12},
+		{"logistics-domain.pddl", "logistics-2.pddl", 18},
+	} {
+		solve := map[string]func(task *Task) (search.State, error){
+			"bfs": func(task *Task) (search.State, error) {
+				return search.Search(task.GoalState(), task.StartState(), task, task)
+			},
+			"astar": func(task *Task) (search.State, error) {
+				return search.SearchAStar(task.GoalState(), task.StartState(), task, task, task, task.HMax)
+			},
+			"greedy": func(task *Task) (search.State, error) {
+				return search.SearchGreedy(task.GoalState(), task.StartState(), task, task, task.HFF)
+			},
+		}
+		for name, f := range solve {
+			task := ground(t, "testdata/"+c.domain, "testdata/"+c.problem)
+			g, err := f(task)
+			if err != nil {
+				t.Errorf("%s %s: %v", name, c.problem, err)
+				continue
+			}
+			plan := Plan(g)
+			if err := task.Validate(plan); err != nil {
+				t.Errorf("%s %s: %v", name, c.problem, err)
+			}
+			if name != "greedy" && len(plan) != c.optimal {
+				t.Errorf("%s %s: got %d steps, want %d", name, c.problem, len(plan), c.optimal)
+			}
+		}
+	}
+}
+
+func TestHeuristics(t *testing.T) {
+	task := ground(t, "testdata/blocks-domain.pddl", "testdata/blocks-sussman.pddl")
+	s, goal := task.StartState(), task.GoalState()
+	if h := task.HMax(s, goal); h != 3 {
+		t.Errorf("hmax: got %v, want 3", h)
+	}
+	if h := task.HAdd(s, goal); h != 5 {
+		t.Errorf("hadd: got %v, want 5", h)
+	}
+	if h := task.HFF(s, goal); h != 5 {
+		t.Errorf("hff: got %v, want 5", h)
+	}
+	for _, h := range []search.Heuristic{task.HMax, task.HAdd, task.HFF} {
+		if v := h(goal, goal); v != 0 {
+			t.Errorf("got %v at the goal, want 0", v)
+		}
+	}
+}
+
+func TestNegative(t *testing.T) {
+	d, err := ParseDomain(strings.NewReader(`(define (domain lamp)
+		(:requirements :strips :negative-preconditions)
+		(:predicates (on) (broken))
+		(:action switch-on :parameters () :precondition (not (on)) :effect (on))
+		(:action switch-off :parameters () :precondition (on) :effect (not (on)))
+		(:action break :parameters () :precondition (not (broken)) :effect (broken)))`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := ParseProblem(strings.NewReader(`(define (problem p) (:domain lamp)
+		(:init (on)) (:goal (and (not (on)) (broken))))`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := Ground(d, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := search.Search(task.GoalState(), task.StartState(), task, task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan := Plan(g)
+	if len(plan) != 2 {
+		t.Errorf("got plan %v, want 2 steps", plan)
+	}
+	if err := task.Validate(plan); err != nil {
+		t.Error(err)
+	}
+	if err := task.Validate([]string{"(switch-on)"}); err == nil || err.Error() != "step 1: (switch-on) is not applicable" {
+		t.Errorf("got %v", err)
+	}
+	if err := task.Validate([]string{"(break)"}); err == nil || err.Error() != "plan does not reach the goal" {
+		t.Errorf("got %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package search
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// NondeterministicTransitioner is an interface for a transition model
+// where an action may lead to any one of several states.
+type NondeterministicTransitioner interface {
+	Results(s State, a Action) []State
+}
+
+// ConditionalPlan is a contingency plan: take Action, observe which of
+// the Outcomes came about and carry on with that outcome's plan.
+// A plan with no Outcomes is empty, i.e. the goal has been reached.
+type ConditionalPlan struct {
+	Action   Action
+	Outcomes []Outcome
+}
+
+// Outcome is one possible result of a ConditionalPlan's action
+// together with the plan to follow from there.
+type Outcome struct {
+	State State
+	Then  *ConditionalPlan
+}
+
+// SearchAndOr returns a conditional plan that reaches a state passing
+// goal from state s whatever outcomes the nondeterministic actions have,
+// and a non-nil error if no such acyclic plan exists.
+func SearchAndOr(goal GoalTest, s State, tm NondeterministicTransitioner, aa Actionsner) (*ConditionalPlan, error) {
+	searcher := &andOrSearch{goal: goal, transitionModel: tm, availableActions: aa}
+	p, ok := searcher.orSearch(s, nil)
+	if !ok {
+		return nil, fmt.Errorf("search failed to find conditional plan")
+	}
+	return p, nil
+}
+
+type andOrSearch struct {
+	goal             GoalTest
+	transitionModel  NondeterministicTransitioner
+	availableActions Actionsner
+}
+
+// Pseudocode from Russell and Norvig, AIMA 3rd ed. figure 4.11:
+//
+//	function OR-SEARCH(state, problem, path) returns a conditional plan, or failure
+//	    if problem.GOAL-TEST(state) then return the empty plan
+//	    if state is on path then return failure
+//	    for each action in problem.ACTIONS(state) do
+//	        plan := AND-SEARCH(RESULTS(state, action), problem, [state | path])
+//	        if plan != failure then return [action | plan]
+//	    return failure
+//
+//	function AND-SEARCH(states, problem, path) returns a conditional plan, or failure
+//	    for each s_i in states do
+//	        plan_i := OR-SEARCH(s_i, problem, path)
+//	        if plan_i = failure then return failure
+//	    return [if s_1 then plan_1 else ... if s_n then plan_n]
+func (a *andOrSearch) orSearch(s State, path []int) (*ConditionalPlan, bool) {
+	if a.goal(s) {
+		return &ConditionalPlan{}, true
+	}
+	for _, id := range path {
+		if id == s.ID {
+			return nil, false
+		}
+	}
+	for _, action := range a.availableActions.Actions(s) {
+		outcomes, ok := a.andSearch(a.transitionModel.Results(s, action), append([]int{s.ID}, path...))
+		if ok {
+			return &ConditionalPlan{Action: action, Outcomes: outcomes}, true
+		}
+	}
+	return nil, false
+}
+
+func (a *andOrSearch) andSearch(ss []State, path []int) ([]Outcome, bool) {
+	outcomes := []Outcome{}
+	for _, s := range ss {
+		p, ok := a.orSearch(s, path)
+		if !ok {
+			return nil, false
+		}
+		outcomes = append(outcomes, Outcome{State: s, Then: p})
+	}
+	return outcomes, true
+}
+
+// String returns the plan in the bracketed form used by AIMA, e.g.
+// [Suck, if 5 then [Right, Suck] else if 7 then []].
+// Steps with a single outcome are written as a plain sequence.
+func (p *ConditionalPlan) String() string {
+	return "[" + strings.Join(p.steps(), ", ") + "]"
+}
+
+func (p *ConditionalPlan) steps() []string {
+	if p == nil || len(p.Outcomes) == 0 {
+		return nil
+	}
+	ss := []string{p.Action.Name}
+	if len(p.Outcomes) == 1 {
+		return append(ss, p.Outcomes[0].Then.steps()...)
+	}
+	conds := []string{}
+	for _, o := range p.Outcomes {
+		conds = append(conds, fmt.Sprintf("if %s then %s", stateLabel(o.State), o.Then))
+	}
+	return append(ss, strings.Join(conds, " else "))
+}
+
+func stateLabel(s State) string {
+	if s.Description != "" {
+		return s.Description
+	}
+	return fmt.Sprint(s.ID)
+}
+
+// Print writes the plan to w as an indented if-then tree.
+func (p *ConditionalPlan) Print(w io.Writer) {
+	p.print(w, "")
+}
+
+func (p *ConditionalPlan) print(w io.Writer, indent string) {
+	if p == nil || len(p.Outcomes) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%s%s\n", indent, p.Action.Name)
+	if len(p.Outcomes) == 1 {
+		p.Outcomes[0].Then.print(w, indent)
+		return
+	}
+	for _, o := range p.Outcomes {
+		fmt.Fprintf(w, "%sif %s:\n", indent, stateLabel(o.State))
+		o.Then.print(w, indent+"  ")
+	}
+}
@@ -0,0 +1,91 @@
+package search
+
+import (
+	"fmt"
+	"os"
+)
+
+// erraticVacuum is the erratic vacuum world from AIMA section 4.3.
+// States are numbered 1 to 8 as in the book:
+// odd states have the vacuum on the left, even on the right and
+// dirt is in both squares (1,2), left only (3,4), right only (5,6) or neither (7,8).
+type erraticVacuum struct{}
+
+func vacuumState(right, dirtL, dirtR bool) State {
+	id := 1
+	if right {
+		id++
+	}
+	if !dirtL {
+		id += 4
+	}
+	if !dirtR {
+		id += 2
+	}
+	return State{ID: id}
+}
+
+func vacuumParts(s State) (right, dirtL, dirtR bool) {
+	n := s.ID - 1
+	return n%2 == 1, n < 4, n/2%2 == 0
+}
+
+func (erraticVacuum) Actions(s State) []Action {
+	return []Action{{ID: 1, Name: "Suck"}, {ID: 2, Name: "Right"}, {ID: 3, Name: "Left"}}
+}
+
+func (erraticVacuum) Results(s State, a Action) []State {
+	right, dl, dr := vacuumParts(s)
+	switch a.Name {
+	case "Right":
+		return []State{vacuumState(true, dl, dr)}
+	case "Left":
+		return []State{vacuumState(false, dl, dr)}
+	}
+	dirty := (right && dr) || (!right && dl)
+	if dirty {
+		// cleans the current square and sometimes the adjacent one too.
+		var cur State
+		if right {
+			cur = vacuumState(right, dl, false)
+		} else {
+			cur = vacuumState(right, false, dr)
+		}
+		both := vacuumState(right, false, false)
+		if cur.ID == both.ID {
+			return []State{cur}
+		}
+		return []State{cur, both}
+	}
+	// sucking a clean square sometimes deposits dirt on it.
+	if right {
+		return []State{s, vacuumState(right, dl, true)}
+	}
+	return []State{s, vacuumState(right, true, dr)}
+}
+
+func ExampleSearchAndOr() {
+	ev := erraticVacuum{}
+	p, err := SearchAndOr(Goals(State{ID: 7}, State{ID: 8}), State{ID: 1}, ev, ev)
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(p)
+	p.Print(os.Stdout)
+	// Output:
+	// [Suck, if 5 then [Right, Suck] else if 7 then []]
+	// Suck
+	// if 5:
+	//   Right
+	//   Suck
+	// if 7:
+}
+
+func ExampleSearchAndOr_failure() {
+	ev := erraticVacuum{}
+
+	_, err := SearchAndOr(Goals(State{ID: 9}), State{ID: 1}, ev, ev)
+	fmt.Println(err)
+	// Output:
+	// search failed to find conditional plan
+}
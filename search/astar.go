@@ -0,0 +1,131 @@
+package search
+
+import (
+	"fmt"
+	"math"
+)
+
+// SearchAStar is like Search but expands first the state with the least
+// cost so far plus h, the estimated cost from there to goal. If h never
+// overestimates, and falls by no more than the cost of each action, the
+// path found is one of least cost.
+// A nil sc gives every action a cost of 1 and a nil h makes it uniform
+// cost search. States for which h is +Inf are dead ends and not expanded.
+//
+// Pseudocode from Russell and Norvig, AIMA 3rd ed. figure 3.14, with
+// the priority g + h in place of the path cost g:
+//
+//	function UNIFORM-COST-SEARCH(problem) returns a solution, or failure
+//	    node := a node with STATE = problem.INITIAL-STATE, PATH-COST = 0
+//	    frontier := a priority queue ordered by PATH-COST, with node as the only element
+//	    explored := an empty set
+//	    loop do
+//	        if EMPTY?(frontier) then return failure
+//	        node := POP(frontier)
+//	        if problem.GOAL-TEST(node.STATE) then return SOLUTION(node)
+//	        add node.STATE to explored
+//	        for each action in problem.ACTIONS(node.STATE) do
+//	            child := CHILD-NODE(problem, node, action)
+//	            if child.STATE is not in explored or frontier then
+//	                frontier := INSERT(child, frontier)
+//	            else if child.STATE is in frontier with higher PATH-COST then
+//	                replace that frontier node with child
+func SearchAStar(goal, s State, tm NextStateter, aa Actionsner, sc StepCoster, h Heuristic) (State, error) {
+	b := &bestFirst{goal: goal, stepCost: sc, transitionModel: tm, availableActions: aa, h: h}
+	return b.search(s, func(g, h float64) dKey { return dKey{g + h, h} })
+}
+
+// SearchGreedy is like SearchAStar but expands first the state that h
+// estimates to be nearest the goal, ignoring the cost so far. It often
+// finds a path much sooner, but not necessarily one of least cost.
+func SearchGreedy(goal, s State, tm NextStateter, aa Actionsner, h Heuristic) (State, error) {
+	b := &bestFirst{goal: goal, transitionModel: tm, availableActions: aa, h: h}
+	seq := 0.0
+	return b.search(s, func(g, h float64) dKey {
+		seq++
+		return dKey{h, seq}
+	})
+}
+
+type bestFirst struct {
+	goal             State
+	stepCost         StepCoster
+	transitionModel  NextStateter
+	availableActions Actionsner
+	h                Heuristic
+}
+
+// search expands states in order of the key made by priority from the
+// cost so far and the heuristic estimate.
+func (b *bestFirst) search(s State, priority func(g, h float64) dKey) (State, error) {
+	s = bare(s)
+	g := map[int]float64{s.ID: 0}
+	states := map[int]State{s.ID: s}
+	actions := map[int]Action{}
+	parents := map[int]int{}
+	hs := map[int]float64{}
+	done := map[int]bool{}
+	q := &dQueue{pos: map[int]*dItem{}}
+	q.upsert(s.ID, priority(0, b.estimate(s, hs)))
+	for q.Len() > 0 {
+		u := q.top().id
+		q.remove(u)
+		done[u] = true
+		if u == b.goal.ID {
+			return chain(u, s.ID, states, parents, actions), nil
+		}
+		for _, a := range b.availableActions.Actions(states[u]) {
+			w := bare(b.transitionModel.NextState(states[u], a))
+			if done[w.ID] {
+				continue
+			}
+			c := 1.0
+			if b.stepCost != nil {
+				c = b.stepCost.StepCost(states[u], a, w)
+			}
+			d := g[u] + c
+			if old, ok := g[w.ID]; ok && d >= old {
+				continue
+			}
+			est := b.estimate(w, hs)
+			if math.IsInf(est, 1) {
+				continue
+			}
+			g[w.ID], states[w.ID] = d, w
+			parents[w.ID], actions[w.ID] = u, a
+			q.upsert(w.ID, priority(d, est))
+		}
+	}
+	return State{}, fmt.Errorf("search failed to find goal")
+}
+
+// estimate returns h for s, computing it only once for each state.
+func (b *bestFirst) estimate(s State, hs map[int]float64) float64 {
+	if b.h == nil {
+		return 0
+	}
+	v, ok := hs[s.ID]
+	if !ok {
+		v = b.h(s, b.goal)
+		hs[s.ID] = v
+	}
+	return v
+}
+
+// chain links the states from start to id through their parents into the
+// State chain that Path walks.
+func chain(id, start int, states map[int]State, parents map[int]int, actions map[int]Action) State {
+	ids := []int{id}
+	for id != start {
+		id = parents[id]
+		ids = append(ids, id)
+	}
+	cur := states[start]
+	for i := len(ids) - 2; i >= 0; i-- {
+		prev := cur
+		cur = states[ids[i]]
+		cur.ParentState = &prev
+		cur.ParentAction = actions[ids[i]]
+	}
+	return cur
+}
@@ -0,0 +1,63 @@
+package search
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func ExampleSearchAStar() {
+	yg := yenGraph{}
+	g, err := SearchAStar(yenState("H"), yenState("C"), yg, yg, yg, nil)
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(route(g), pathCostOf(g))
+	// Output:
+	// CEFH 5
+}
+
+func TestSearchAStarMatchesDStarLite(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	for i := 0; i < 50; i++ {
+		g := NewGrid(20, 20)
+		for n := 0; n < 120; n++ {
+			g.Block(r.Intn(20), r.Intn(20))
+		}
+		start, goal := g.State(0, 0), g.State(19, 19)
+		if g.Blocked(0, 0) || g.Blocked(19, 19) {
+			continue
+		}
+		d := NewDStarLite(goal, start, g, g, g, g.Octile)
+		_, derr := d.Plan()
+		s, err := SearchAStar(goal, start, g, g, g, g.Octile)
+		if (derr == nil) != (err == nil) {
+			t.Fatalf("case %d: solvable mismatch: %v vs %v", i, derr, err)
+		}
+		if err != nil {
+			continue
+		}
+		if c := pathCost(g, s); math.Abs(c-d.Cost()) > 1e-9 {
+			t.Errorf("case %d: A* cost %v, D* Lite cost %v", i, c, d.Cost())
+		}
+		if _, err := SearchGreedy(goal, start, g, g, g.Octile); err != nil {
+			t.Errorf("case %d: greedy search failed: %v", i, err)
+		}
+	}
+}
+
+func TestSearchAStarDeadEnds(t *testing.T) {
+	// a heuristic of +Inf everywhere but the start cuts off every path.
+	g := NewGrid(3, 1)
+	start := g.State(0, 0)
+	h := func(a, b State) float64 {
+		if a.ID == start.ID {
+			return 0
+		}
+		return math.Inf(1)
+	}
+	if _, err := SearchAStar(g.State(2, 0), start, g, g, g, h); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,165 @@
+package search
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// BeliefProblem lifts a physical problem into belief space, where each
+// State stands for the set of physical states the agent might be in.
+// It satisfies NextStateter and Actionsner, so Search, SearchDFS and
+// SearchGoal solve sensorless (conformant) problems with it unchanged.
+//
+// When Percept is set, BeliefProblem also satisfies
+// NondeterministicTransitioner: each possible percept after an action
+// is a separate outcome, and SearchAndOr finds a contingency plan for
+// the partially observable problem.
+type BeliefProblem struct {
+	// Percept returns what the agent perceives in physical state s.
+	// It is optional; a nil Percept means the agent is sensorless.
+	Percept func(s State) string
+
+	transitionModel  NextStateter
+	availableActions Actionsner
+	ids              map[string]int
+	members          map[int][]State
+}
+
+// NewBeliefProblem returns the belief-space version of the
+// physical problem described by tm and aa.
+func NewBeliefProblem(tm NextStateter, aa Actionsner) *BeliefProblem {
+	return &BeliefProblem{
+		transitionModel:  tm,
+		availableActions: aa,
+		ids:              map[string]int{},
+		members:          map[int][]State{},
+	}
+}
+
+// Belief returns the belief state for the set of physical states ss.
+// The same set always yields the same State, so belief states
+// may be compared by ID like any other State.
+func (b *BeliefProblem) Belief(ss ...State) State {
+	seen := map[int]bool{}
+	mm := []State{}
+	for _, s := range ss {
+		if !seen[s.ID] {
+			seen[s.ID] = true
+			mm = append(mm, bare(s))
+		}
+	}
+	sort.Slice(mm, func(i, j int) bool { return mm[i].ID < mm[j].ID })
+
+	ids := []string{}
+	for _, m := range mm {
+		ids = append(ids, fmt.Sprint(m.ID))
+	}
+	key := "{" + strings.Join(ids, " ") + "}"
+
+	id, ok := b.ids[key]
+	if !ok {
+		id = len(b.ids) + 1
+		b.ids[key] = id
+		b.members[id] = mm
+	}
+	return State{ID: id, Description: key}
+}
+
+// Members returns the physical states in belief state s.
+func (b *BeliefProblem) Members(s State) []State {
+	return b.members[s.ID]
+}
+
+// Goal returns the GoalTest passed by belief states that are not empty
+// and whose members are all among the physical goals. Pass it to
+// SearchGoal for a sensorless plan, or to SearchAndOr for a contingency
+// plan, that ends in a goal whichever state the agent is really in.
+func (b *BeliefProblem) Goal(goals ...State) GoalTest {
+	physical := Goals(goals...)
+	return func(s State) bool {
+		mm := b.Members(s)
+		for _, m := range mm {
+			if !physical(m) {
+				return false
+			}
+		}
+		return len(mm) > 0
+	}
+}
+
+// Actions returns every action available in any member of belief s.
+// Taking an action in a physical state where it is not available is
+// assumed to leave that state unchanged.
+func (b *BeliefProblem) Actions(s State) []Action {
+	seen := map[int]bool{}
+	aa := []Action{}
+	for _, m := range b.Members(s) {
+		for _, a := range b.availableActions.Actions(m) {
+			if !seen[a.ID] {
+				seen[a.ID] = true
+				aa = append(aa, a)
+			}
+		}
+	}
+	return aa
+}
+
+// NextState returns the belief state predicted after taking action a in belief s.
+func (b *BeliefProblem) NextState(s State, a Action) State {
+	next := []State{}
+	for _, m := range b.Members(s) {
+		next = append(next, b.physicalNext(m, a))
+	}
+	return b.Belief(next...)
+}
+
+func (b *BeliefProblem) physicalNext(m State, a Action) State {
+	for _, legal := range b.availableActions.Actions(m) {
+		if legal.ID == a.ID {
+			return b.transitionModel.NextState(m, a)
+		}
+	}
+	return m
+}
+
+// Results returns the belief states that may follow action a in belief s,
+// one for each percept the agent could then receive.
+func (b *BeliefProblem) Results(s State, a Action) []State {
+	predicted := b.NextState(s, a)
+	if b.Percept == nil {
+		return []State{predicted}
+	}
+	percepts := []string{}
+	for _, m := range b.Members(predicted) {
+		p := b.Percept(m)
+		if !containsString(percepts, p) {
+			percepts = append(percepts, p)
+		}
+	}
+	ss := []State{}
+	for _, p := range percepts {
+		ss = append(ss, b.Update(predicted, p))
+	}
+	return ss
+}
+
+// Update returns the members of belief s that are consistent with percept.
+func (b *BeliefProblem) Update(s State, percept string) State {
+	mm := []State{}
+	for _, m := range b.Members(s) {
+		if b.Percept == nil || b.Percept(m) == percept {
+			mm = append(mm, m)
+		}
+	}
+	return b.Belief(mm...)
+}
+
+func containsString(ss []string, s string) bool {
+	for _, e := range ss {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package search
+
+import (
+	"fmt"
+	"testing"
+)
+
+func ExampleBeliefProblem() {
+	gw := gridWorld{w: 3, h: 3}
+	b := NewBeliefProblem(gw, gw)
+
+	// the robot could be anywhere. Coerce it into the left column.
+	all := []State{}
+	for id := 0; id < 9; id++ {
+		all = append(all, State{ID: id})
+	}
+	start := b.Belief(all...)
+	goal := b.Goal(State{ID: 0}, State{ID: 3}, State{ID: 6})
+
+	g, err := SearchGoal(goal, start, b, b)
+	if err != nil {
+		fmt.Println(err)
+	}
+	for _, s := range g.Path() {
+		fmt.Println(s.Description, s.ParentAction.Name)
+	}
+	// Output:
+	// {0 3 6} W
+	// {0 1 3 4 6 7} W
+	// {0 1 2 3 4 5 6 7 8}
+}
+
+func TestBeliefProblemGoal(t *testing.T) {
+	gw := gridWorld{w: 3, h: 3}
+	b := NewBeliefProblem(gw, gw)
+	goal := b.Goal(State{ID: 0}, State{ID: 1}, State{ID: 2})
+	for _, c := range []struct {
+		belief State
+		want   bool
+	}{
+		{b.Belief(State{ID: 0}, State{ID: 1}, State{ID: 2}), true},
+		{b.Belief(State{ID: 1}), true},
+		{b.Belief(State{ID: 0}, State{ID: 3}), false},
+		{b.Belief(), false},
+	} {
+		if got := goal(c.belief); got != c.want {
+			t.Errorf("goal(%s) = %v, want %v", c.belief.Description, got, c.want)
+		}
+	}
+
+	// the prediction is kept as it is, not widened to every goal.
+	n := Action{ID: 1, Name: "N"}
+	if next := b.NextState(b.Belief(State{ID: 3}, State{ID: 4}), n); next.Description != "{0 1}" {
+		t.Errorf("unexpected prediction: %s", next.Description)
+	}
+
+	// a start that already meets the goal needs no actions.
+	start := b.Belief(State{ID: 2})
+	if g, err := SearchGoal(goal, start, b, b); err != nil || len(g.Path()) != 1 {
+		t.Errorf("start is a goal: %v, %v", g.Path(), err)
+	}
+}
+
+// vacuumWorld is the deterministic vacuum world, numbered as erraticVacuum.
+type vacuumWorld struct{}
+
+func (vacuumWorld) Actions(s State) []Action {
+	return erraticVacuum{}.Actions(s)
+}
+
+func (vacuumWorld) NextState(s State, a Action) State {
+	right, dl, dr := vacuumParts(s)
+	switch a.Name {
+	case "Right":
+		right = true
+	case "Left":
+		right = false
+	case "Suck":
+		if right {
+			dr = false
+		} else {
+			dl = false
+		}
+	}
+	return vacuumState(right, dl, dr)
+}
+
+func ExampleBeliefProblem_partiallyObservable() {
+	vw := vacuumWorld{}
+	b := NewBeliefProblem(vw, vw)
+
+	// the agent senses its position and whether its own square is dirty.
+	b.Percept = func(s State) string {
+		right, dl, dr := vacuumParts(s)
+		if right {
+			return fmt.Sprint("R", dr)
+		}
+		return fmt.Sprint("L", dl)
+	}
+
+	start := b.Belief(State{ID: 1}, State{ID: 3})
+	p, err := SearchAndOr(b.Goal(State{ID: 7}, State{ID: 8}), start, b, b)
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(p)
+	// Output:
+	// [Suck, Right, if {6} then [Suck] else if {8} then []]
+}
@@ -0,0 +1,324 @@
+package search
+
+import (
+	"fmt"
+	"math"
+)
+
+// StepCoster is an interface for a cost model that calls StepCost.
+// StepCost returns the cost of taking action a in state s to reach state next.
+type StepCoster interface {
+	StepCost(s State, a Action, next State) float64
+}
+
+// Heuristic estimates the cost of travelling between states a and b.
+// Planners that promise optimal paths require it to never overestimate.
+type Heuristic func(a, b State) float64
+
+// DStarLite is an incremental planner.
+// It plans a path from a start state to a goal state and
+// then repairs that plan when edge costs change or the agent moves,
+// rather than searching again from scratch.
+type DStarLite struct {
+	goal, start, last int
+	km                float64
+	h                 Heuristic
+	tm                NextStateter
+	aa                Actionsner
+	sc                StepCoster
+	back              Predecessorser // nil if the states were enumerated up front
+
+	states   map[int]State
+	succ     map[int][]dEdge
+	pred     map[int][]int
+	g, rhs   map[int]float64
+	u        *dQueue
+	expanded int
+}
+
+// Predecessorser is an interface for a transition model that can be run
+// backwards, calling Predecessors. Predecessors returns the states from
+// which some action leads to s.
+type Predecessorser interface {
+	Predecessors(s State) []State
+}
+
+type dEdge struct {
+	to     int
+	action Action
+	cost   float64
+}
+
+// NewDStarLite returns a D* Lite planner from state s to goal.
+// A nil sc gives every action a cost of 1 and
+// a nil h makes the planner uninformed.
+//
+// D* Lite searches backwards from the goal, so it needs the predecessors
+// of each state. If tm is also a Predecessorser, states are discovered as
+// the search reaches them, and only the part of the state space it
+// explores is ever generated. Otherwise tm and aa only give successors,
+// so NewDStarLite enumerates every state reachable from s once, up front,
+// and the reachable state space must be finite. Either way tm, aa and sc
+// are asked about each state once; later changes are made with
+// UpdateEdgeCost.
+func NewDStarLite(goal, s State, tm NextStateter, aa Actionsner, sc StepCoster, h Heuristic) *DStarLite {
+	if h == nil {
+		h = func(a, b State) float64 { return 0 }
+	}
+	d := &DStarLite{
+		goal:   goal.ID,
+		start:  s.ID,
+		last:   s.ID,
+		h:      h,
+		tm:     tm,
+		aa:     aa,
+		sc:     sc,
+		states: map[int]State{},
+		succ:   map[int][]dEdge{},
+		pred:   map[int][]int{},
+		g:      map[int]float64{},
+		rhs:    map[int]float64{},
+		u:      &dQueue{pos: map[int]*dItem{}},
+	}
+	d.states[goal.ID] = bare(goal)
+	d.states[s.ID] = bare(s)
+	if back, ok := tm.(Predecessorser); ok {
+		d.back = back
+	} else {
+		d.explore(s.ID)
+	}
+
+	d.rhs[d.goal] = 0
+	d.u.upsert(d.goal, d.key(d.goal))
+	return d
+}
+
+// explore enumerates the finite state space breadth-first from s,
+// recording successor and predecessor edges.
+func (d *DStarLite) explore(s int) {
+	q := []int{s}
+	seen := map[int]bool{s: true}
+	for len(q) > 0 {
+		v := q[0]
+		q = q[1:]
+		for _, e := range d.successors(v) {
+			d.pred[e.to] = append(d.pred[e.to], v)
+			if !seen[e.to] {
+				seen[e.to] = true
+				q = append(q, e.to)
+			}
+		}
+	}
+}
+
+// successors returns the edges out of known state u,
+// asking tm, aa and sc for them the first time.
+func (d *DStarLite) successors(u int) []dEdge {
+	if ee, ok := d.succ[u]; ok {
+		return ee
+	}
+	v := d.states[u]
+	ee := []dEdge{}
+	for _, a := range d.aa.Actions(v) {
+		w := bare(d.tm.NextState(v, a))
+		c := 1.0
+		if d.sc != nil {
+			c = d.sc.StepCost(v, a, w)
+		}
+		ee = append(ee, dEdge{to: w.ID, action: a, cost: c})
+		if _, ok := d.states[w.ID]; !ok {
+			d.states[w.ID] = w
+		}
+	}
+	d.succ[u] = ee
+	return ee
+}
+
+// predecessors returns the states with an edge to known state u. Without
+// a Predecessorser they were all recorded by explore.
+func (d *DStarLite) predecessors(u int) []int {
+	if pp, ok := d.pred[u]; ok || d.back == nil {
+		return pp
+	}
+	pp := []int{}
+	for _, p := range d.back.Predecessors(d.states[u]) {
+		if _, ok := d.states[p.ID]; !ok {
+			d.states[p.ID] = bare(p)
+		}
+		pp = append(pp, p.ID)
+	}
+	d.pred[u] = pp
+	return pp
+}
+
+// know adds s to the known states if the planner discovers states lazily.
+func (d *DStarLite) know(s State) error {
+	if _, ok := d.states[s.ID]; ok {
+		return nil
+	}
+	if d.back == nil {
+		return fmt.Errorf("unknown state: %v", s.ID)
+	}
+	d.states[s.ID] = bare(s)
+	return nil
+}
+
+func bare(s State) State {
+	return State{ID: s.ID, Description: s.Description}
+}
+
+// Plan returns the goal state and nil error when a path exists from
+// the current start state. goal.Path can then be called to provide the
+// path from the goal state tracking back to the current start state.
+//
+// The first call plans from scratch, later calls only repair the
+// parts of the plan affected by UpdateEdgeCost and MoveTo.
+func (d *DStarLite) Plan() (State, error) {
+	d.computeShortestPath()
+	if math.IsInf(d.gOf(d.start), 1) {
+		return State{}, fmt.Errorf("search failed to find goal")
+	}
+
+	cur := d.states[d.start]
+	for n := 0; cur.ID != d.goal; n++ {
+		if n > len(d.states) {
+			return State{}, fmt.Errorf("search failed to find goal")
+		}
+		best, bestCost := dEdge{}, math.Inf(1)
+		for _, e := range d.successors(cur.ID) {
+			if c := e.cost + d.gOf(e.to); c < bestCost {
+				best, bestCost = e, c
+			}
+		}
+		if math.IsInf(bestCost, 1) {
+			return State{}, fmt.Errorf("search failed to find goal")
+		}
+		prev := cur
+		cur = d.states[best.to]
+		cur.ParentState = &prev
+		cur.ParentAction = best.action
+	}
+	return cur, nil
+}
+
+// Cost returns the cost of the current plan from the start state to the goal.
+// It is only meaningful after Plan has been called.
+func (d *DStarLite) Cost() float64 {
+	return d.gOf(d.start)
+}
+
+// Expanded returns the number of state expansions performed so far
+// across all calls to Plan.
+func (d *DStarLite) Expanded() int {
+	return d.expanded
+}
+
+// MoveTo tells the planner the agent is now at state s.
+// The next call to Plan returns a path from s.
+func (d *DStarLite) MoveTo(s State) error {
+	if err := d.know(s); err != nil {
+		return err
+	}
+	d.start = s.ID
+	return nil
+}
+
+// UpdateEdgeCost changes the cost of the edge from state from to state to.
+// Use math.Inf(1) to mark the edge as blocked.
+func (d *DStarLite) UpdateEdgeCost(from, to State, cost float64) error {
+	if d.know(from) != nil {
+		return fmt.Errorf("no edge from %v to %v", from.ID, to.ID)
+	}
+	found := false
+	for i, e := range d.successors(from.ID) {
+		if e.to == to.ID {
+			d.succ[from.ID][i].cost = cost
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("no edge from %v to %v", from.ID, to.ID)
+	}
+	if d.start != d.last {
+		d.km += d.h(d.states[d.last], d.states[d.start])
+		d.last = d.start
+	}
+	d.updateVertex(from.ID)
+	return nil
+}
+
+// Pseudocode from Koenig and Likhachev, "D* Lite" (2002):
+//
+//	procedure ComputeShortestPath()
+//	    while U.TopKey() < CalculateKey(s_start) OR rhs(s_start) != g(s_start)
+//	        k_old := U.TopKey(); u := U.Pop()
+//	        if k_old < CalculateKey(u)
+//	            U.Insert(u, CalculateKey(u))
+//	        else if g(u) > rhs(u)
+//	            g(u) := rhs(u)
+//	            for all s in Pred(u): UpdateVertex(s)
+//	        else
+//	            g(u) := infinity
+//	            for all s in Pred(u) + {u}: UpdateVertex(s)
+func (d *DStarLite) computeShortestPath() {
+	for d.u.Len() > 0 {
+		top := d.u.top()
+		if !top.key.less(d.key(d.start)) && d.rhsOf(d.start) == d.gOf(d.start) {
+			return
+		}
+		u := top.id
+		kOld := top.key
+		d.u.remove(u)
+		d.expanded++
+		switch {
+		case kOld.less(d.key(u)):
+			d.u.upsert(u, d.key(u))
+		case d.gOf(u) > d.rhsOf(u):
+			d.g[u] = d.rhsOf(u)
+			for _, p := range d.predecessors(u) {
+				d.updateVertex(p)
+			}
+		default:
+			d.g[u] = math.Inf(1)
+			for _, p := range d.predecessors(u) {
+				d.updateVertex(p)
+			}
+			d.updateVertex(u)
+		}
+	}
+}
+
+func (d *DStarLite) updateVertex(u int) {
+	if u != d.goal {
+		best := math.Inf(1)
+		for _, e := range d.successors(u) {
+			if c := e.cost + d.gOf(e.to); c < best {
+				best = c
+			}
+		}
+		d.rhs[u] = best
+	}
+	d.u.remove(u)
+	if d.gOf(u) != d.rhsOf(u) {
+		d.u.upsert(u, d.key(u))
+	}
+}
+
+func (d *DStarLite) key(s int) dKey {
+	m := math.Min(d.gOf(s), d.rhsOf(s))
+	return dKey{m + d.h(d.states[d.start], d.states[s]) + d.km, m}
+}
+
+func (d *DStarLite) gOf(s int) float64 {
+	if v, ok := d.g[s]; ok {
+		return v
+	}
+	return math.Inf(1)
+}
+
+func (d *DStarLite) rhsOf(s int) float64 {
+	if v, ok := d.rhs[s]; ok {
+		return v
+	}
+	return math.Inf(1)
+}
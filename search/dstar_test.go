@@ -0,0 +1,194 @@
+package search
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// gridWorld is a 4-connected grid where state ID is y*w + x.
+type gridWorld struct {
+	w, h int
+}
+
+func (g gridWorld) NextState(s State, a Action) State {
+	switch a.Name {
+	case "N":
+		return State{ID: s.ID - g.w}
+	case "S":
+		return State{ID: s.ID + g.w}
+	case "E":
+		return State{ID: s.ID + 1}
+	case "W":
+		return State{ID: s.ID - 1}
+	}
+	return State{}
+}
+
+func (g gridWorld) Actions(s State) []Action {
+	x, y := s.ID%g.w, s.ID/g.w
+	aa := []Action{}
+	if y > 0 {
+		aa = append(aa, Action{ID: 1, Name: "N"})
+	}
+	if y < g.h-1 {
+		aa = append(aa, Action{ID: 2, Name: "S"})
+	}
+	if x < g.w-1 {
+		aa = append(aa, Action{ID: 3, Name: "E"})
+	}
+	if x > 0 {
+		aa = append(aa, Action{ID: 4, Name: "W"})
+	}
+	return aa
+}
+
+func (g gridWorld) manhattan(a, b State) float64 {
+	return math.Abs(float64(a.ID%g.w-b.ID%g.w)) + math.Abs(float64(a.ID/g.w-b.ID/g.w))
+}
+
+func ExampleDStarLite() {
+	gw := gridWorld{w: 5, h: 3}
+	d := NewDStarLite(State{ID: 4}, State{ID: 0}, gw, gw, nil, gw.manhattan)
+
+	g, _ := d.Plan()
+	fmt.Println(g.Path(), d.Cost())
+
+	// block the way east along the top row in both directions.
+	d.UpdateEdgeCost(State{ID: 2}, State{ID: 3}, math.Inf(1))
+	d.UpdateEdgeCost(State{ID: 3}, State{ID: 2}, math.Inf(1))
+	d.Plan()
+	fmt.Println(d.Cost())
+	// Output:
+	// [(4: E) (3: E) (2: E) (1: E) (0: )] 4
+	// 6
+}
+
+func TestDStarLiteReplan(t *testing.T) {
+	gw := gridWorld{w: 8, h: 8}
+	start, goal := State{ID: 0}, State{ID: 63}
+	d := NewDStarLite(goal, start, gw, gw, nil, gw.manhattan)
+	if _, err := d.Plan(); err != nil {
+		t.Fatal(err)
+	}
+	if d.Cost() != 14 {
+		t.Errorf("unexpected cost: %v", d.Cost())
+	}
+
+	// walk two steps along the plan, then wall off column 4 except the last row.
+	g, _ := d.Plan()
+	p := g.Path()
+	at := *p[len(p)-3]
+	d.MoveTo(at)
+	for y := 0; y < 7; y++ {
+		a, b := State{ID: y*8 + 3}, State{ID: y*8 + 4}
+		d.UpdateEdgeCost(a, b, math.Inf(1))
+		d.UpdateEdgeCost(b, a, math.Inf(1))
+	}
+	g, err := d.Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.ID != goal.ID {
+		t.Errorf("unexpected goal: %v", g)
+	}
+	p = g.Path()
+	if p[len(p)-1].ID != at.ID || len(p)-1 != int(d.Cost()) {
+		t.Errorf("path length %d does not match cost %v", len(p)-1, d.Cost())
+	}
+	for _, s := range p {
+		if s.ParentState == nil {
+			continue
+		}
+		x0, x1 := s.ParentState.ID%8, s.ID%8
+		if x0 == 3 && x1 == 4 && s.ID/8 != 7 {
+			t.Errorf("path crosses blocked edge at %v", s)
+		}
+	}
+}
+
+func TestDStarLiteNoPath(t *testing.T) {
+	gw := gridWorld{w: 2, h: 1}
+	d := NewDStarLite(State{ID: 1}, State{ID: 0}, gw, gw, nil, nil)
+	d.UpdateEdgeCost(State{ID: 0}, State{ID: 1}, math.Inf(1))
+	if _, err := d.Plan(); err == nil {
+		t.Error("expected error")
+	}
+}
+
+// lazyGridWorld is a gridWorld that can be run backwards,
+// so D* Lite discovers its states as it goes.
+type lazyGridWorld struct {
+	gridWorld
+}
+
+func (g lazyGridWorld) Predecessors(s State) []State {
+	ss := []State{}
+	for _, a := range g.Actions(s) {
+		ss = append(ss, g.NextState(s, a))
+	}
+	return ss
+}
+
+func TestDStarLiteRepairExpandsLess(t *testing.T) {
+	for _, gw := range []interface {
+		NextStateter
+		Actionsner
+	}{gridWorld{w: 30, h: 30}, lazyGridWorld{gridWorld{w: 30, h: 30}}} {
+		g := gridWorld{w: 30, h: 30}
+		start, goal := State{ID: 0}, State{ID: 899}
+		d := NewDStarLite(goal, start, gw, gw, nil, g.manhattan)
+		p, err := d.Plan()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the robot takes three steps, then finds a wall across
+		// row 10 from the left edge to column 20.
+		path := p.Path()
+		at := *path[len(path)-4]
+		d.MoveTo(at)
+		block := func(d *DStarLite) {
+			for x := 0; x <= 20; x++ {
+				c := State{ID: 10*30 + x}
+				for _, a := range g.Actions(c) {
+					n := g.NextState(c, a)
+					d.UpdateEdgeCost(n, c, math.Inf(1))
+					d.UpdateEdgeCost(c, n, math.Inf(1))
+				}
+			}
+		}
+		before := d.Expanded()
+		block(d)
+		if _, err := d.Plan(); err != nil {
+			t.Fatal(err)
+		}
+		repaired := d.Expanded() - before
+
+		fresh := NewDStarLite(goal, at, gw, gw, nil, g.manhattan)
+		block(fresh)
+		if _, err := fresh.Plan(); err != nil {
+			t.Fatal(err)
+		}
+		if fresh.Cost() != d.Cost() {
+			t.Errorf("%T: repaired plan costs %v, fresh plan %v", gw, d.Cost(), fresh.Cost())
+		}
+		if repaired >= fresh.Expanded() {
+			t.Errorf("%T: repair expanded %d states, fresh plan %d", gw, repaired, fresh.Expanded())
+		}
+	}
+}
+
+func TestDStarLiteLazy(t *testing.T) {
+	gw := lazyGridWorld{gridWorld{w: 1000, h: 1000}}
+	d := NewDStarLite(State{ID: 10*1000 + 10}, State{ID: 0}, gw, gw, nil, gw.manhattan)
+	if _, err := d.Plan(); err != nil {
+		t.Fatal(err)
+	}
+	if d.Cost() != 20 {
+		t.Errorf("unexpected cost: %v", d.Cost())
+	}
+	if len(d.states) > 1000 {
+		t.Errorf("generated %d of a million states", len(d.states))
+	}
+}
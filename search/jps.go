@@ -0,0 +1,336 @@
+package search
+
+import (
+	"fmt"
+	"math"
+)
+
+// Grid is an 8-connected grid map. Orthogonal moves cost 1 and
+// diagonal moves cost √2. A diagonal move is only allowed when
+// both orthogonal cells it passes between are free.
+//
+// The State for cell (x, y) has ID y*Width + x.
+// Grid satisfies NextStateter, Actionsner and StepCoster so it can be
+// solved by the general searches as well as by SearchJPS, and
+// Predecessorser so D* Lite only generates the cells it reaches.
+type Grid struct {
+	Width, Height int
+	blocked       []bool
+}
+
+// NewGrid returns an empty w by h grid.
+func NewGrid(w, h int) *Grid {
+	return &Grid{Width: w, Height: h, blocked: make([]bool, w*h)}
+}
+
+// Block marks cell (x, y) as an obstacle.
+func (g *Grid) Block(x, y int) {
+	if g.inside(x, y) {
+		g.blocked[y*g.Width+x] = true
+	}
+}
+
+// Blocked reports whether cell (x, y) is an obstacle or off the grid.
+func (g *Grid) Blocked(x, y int) bool {
+	return !g.inside(x, y) || g.blocked[y*g.Width+x]
+}
+
+func (g *Grid) inside(x, y int) bool {
+	return x >= 0 && x < g.Width && y >= 0 && y < g.Height
+}
+
+func (g *Grid) free(x, y int) bool {
+	return !g.Blocked(x, y)
+}
+
+// State returns the state for cell (x, y).
+func (g *Grid) State(x, y int) State {
+	return State{ID: y*g.Width + x}
+}
+
+// XY returns the cell coordinates of state s.
+func (g *Grid) XY(s State) (int, int) {
+	return s.ID % g.Width, s.ID / g.Width
+}
+
+// gridMoves are indexed by Action.ID - 1.
+var gridMoves = []struct {
+	dx, dy int
+	name   string
+}{
+	{0, -1, "N"}, {1, -1, "NE"}, {1, 0, "E"}, {1, 1, "SE"},
+	{0, 1, "S"}, {-1, 1, "SW"}, {-1, 0, "W"}, {-1, -1, "NW"},
+}
+
+func gridAction(dx, dy int) Action {
+	for i, m := range gridMoves {
+		if m.dx == dx && m.dy == dy {
+			return Action{ID: i + 1, Name: m.name}
+		}
+	}
+	return Action{}
+}
+
+func (g *Grid) canMove(x, y, dx, dy int) bool {
+	if !g.free(x+dx, y+dy) {
+		return false
+	}
+	if dx != 0 && dy != 0 {
+		return g.free(x+dx, y) && g.free(x, y+dy)
+	}
+	return true
+}
+
+// Actions returns the moves available from s.
+func (g *Grid) Actions(s State) []Action {
+	x, y := g.XY(s)
+	aa := []Action{}
+	for i, m := range gridMoves {
+		if g.canMove(x, y, m.dx, m.dy) {
+			aa = append(aa, Action{ID: i + 1, Name: m.name})
+		}
+	}
+	return aa
+}
+
+// NextState returns the state reached by taking move a from s.
+func (g *Grid) NextState(s State, a Action) State {
+	if a.ID < 1 || a.ID > len(gridMoves) {
+		return State{}
+	}
+	x, y := g.XY(s)
+	m := gridMoves[a.ID-1]
+	return g.State(x+m.dx, y+m.dy)
+}
+
+// Predecessors returns the free cells from which a move leads to s. Moves are
+// symmetric, so they are the cells s can move to, unless s is blocked.
+func (g *Grid) Predecessors(s State) []State {
+	if x, y := g.XY(s); g.Blocked(x, y) {
+		return nil
+	}
+	ss := []State{}
+	for _, a := range g.Actions(s) {
+		ss = append(ss, g.NextState(s, a))
+	}
+	return ss
+}
+
+// StepCost returns 1 for orthogonal and √2 for diagonal moves.
+func (g *Grid) StepCost(s State, a Action, next State) float64 {
+	if a.ID%2 == 0 {
+		return math.Sqrt2
+	}
+	return 1
+}
+
+// Octile is an admissible Heuristic for Grid.
+func (g *Grid) Octile(a, b State) float64 {
+	ax, ay := g.XY(a)
+	bx, by := g.XY(b)
+	dx, dy := math.Abs(float64(ax-bx)), math.Abs(float64(ay-by))
+	return math.Max(dx, dy) + (math.Sqrt2-1)*math.Min(dx, dy)
+}
+
+// SearchJPS finds an optimal path on grid g with jump point search.
+// Like Search it returns the goal state, whose Path runs back to s
+// through every intermediate cell, and a non-nil error on failure.
+//
+// Jump point search prunes the symmetric paths a uniform-cost grid
+// produces, so it expands far fewer states than the general searches.
+func SearchJPS(goal, s State, g *Grid) (State, error) {
+	searcher := newJumpPointSearch(goal, g)
+	return searcher.search(s)
+}
+
+type jumpPointSearch struct {
+	goal     State
+	grid     *Grid
+	open     *dQueue
+	gScore   map[int]float64
+	parent   map[int]int
+	closed   map[int]bool
+	expanded int
+}
+
+func newJumpPointSearch(goal State, g *Grid) *jumpPointSearch {
+	return &jumpPointSearch{
+		goal:   goal,
+		grid:   g,
+		open:   &dQueue{pos: map[int]*dItem{}},
+		gScore: map[int]float64{},
+		parent: map[int]int{},
+		closed: map[int]bool{},
+	}
+}
+
+func (j *jumpPointSearch) search(s State) (State, error) {
+	g := j.grid
+	if x, y := g.XY(s); g.Blocked(x, y) {
+		return State{}, fmt.Errorf("search failed to find goal")
+	}
+	j.gScore[s.ID] = 0
+	j.parent[s.ID] = -1
+	j.open.upsert(s.ID, dKey{g.Octile(s, j.goal), 0})
+	for j.open.Len() > 0 {
+		cur := j.open.top().id
+		j.open.remove(cur)
+		j.closed[cur] = true
+		j.expanded++
+		if cur == j.goal.ID {
+			return j.path(s.ID), nil
+		}
+		x, y := g.XY(State{ID: cur})
+		for _, n := range j.neighbours(cur) {
+			jx, jy, ok := j.jump(n[0], n[1], x, y)
+			if !ok {
+				continue
+			}
+			id := g.State(jx, jy).ID
+			if j.closed[id] {
+				continue
+			}
+			dx, dy := math.Abs(float64(jx-x)), math.Abs(float64(jy-y))
+			d := math.Max(dx, dy) + (math.Sqrt2-1)*math.Min(dx, dy)
+			ng := j.gScore[cur] + d
+			if old, seen := j.gScore[id]; seen && ng >= old {
+				continue
+			}
+			j.gScore[id] = ng
+			j.parent[id] = cur
+			j.open.upsert(id, dKey{ng + g.Octile(State{ID: id}, j.goal), -ng})
+		}
+	}
+	return State{}, fmt.Errorf("search failed to find goal")
+}
+
+// neighbours returns the cells to jump towards from cur,
+// pruned by the direction cur was reached from.
+func (j *jumpPointSearch) neighbours(cur int) [][2]int {
+	g := j.grid
+	x, y := g.XY(State{ID: cur})
+	nn := [][2]int{}
+	add := func(nx, ny int) {
+		if g.free(nx, ny) {
+			nn = append(nn, [2]int{nx, ny})
+		}
+	}
+
+	p := j.parent[cur]
+	if p < 0 {
+		for _, m := range gridMoves {
+			if g.canMove(x, y, m.dx, m.dy) {
+				nn = append(nn, [2]int{x + m.dx, y + m.dy})
+			}
+		}
+		return nn
+	}
+
+	px, py := g.XY(State{ID: p})
+	dx, dy := sign(x-px), sign(y-py)
+	switch {
+	case dx != 0 && dy != 0:
+		add(x, y+dy)
+		add(x+dx, y)
+		if g.canMove(x, y, dx, dy) {
+			nn = append(nn, [2]int{x + dx, y + dy})
+		}
+	case dx != 0:
+		if g.free(x+dx, y) {
+			nn = append(nn, [2]int{x + dx, y})
+			if g.free(x, y+1) {
+				add(x+dx, y+1)
+			}
+			if g.free(x, y-1) {
+				add(x+dx, y-1)
+			}
+		}
+		add(x, y+1)
+		add(x, y-1)
+	default:
+		if g.free(x, y+dy) {
+			nn = append(nn, [2]int{x, y + dy})
+			if g.free(x+1, y) {
+				add(x+1, y+dy)
+			}
+			if g.free(x-1, y) {
+				add(x-1, y+dy)
+			}
+		}
+		add(x+1, y)
+		add(x-1, y)
+	}
+	return nn
+}
+
+// jump moves from (px, py) through (x, y) in a straight line until it
+// reaches the goal, a cell with a forced neighbour or an obstacle.
+func (j *jumpPointSearch) jump(x, y, px, py int) (int, int, bool) {
+	g := j.grid
+	dx, dy := x-px, y-py
+	for {
+		if !g.free(x, y) {
+			return 0, 0, false
+		}
+		if g.State(x, y).ID == j.goal.ID {
+			return x, y, true
+		}
+		switch {
+		case dx != 0 && dy != 0:
+			if _, _, ok := j.jump(x+dx, y, x, y); ok {
+				return x, y, true
+			}
+			if _, _, ok := j.jump(x, y+dy, x, y); ok {
+				return x, y, true
+			}
+		case dx != 0:
+			if (g.free(x, y-1) && !g.free(x-dx, y-1)) ||
+				(g.free(x, y+1) && !g.free(x-dx, y+1)) {
+				return x, y, true
+			}
+		default:
+			if (g.free(x-1, y) && !g.free(x-1, y-dy)) ||
+				(g.free(x+1, y) && !g.free(x+1, y-dy)) {
+				return x, y, true
+			}
+		}
+		if !g.canMove(x, y, dx, dy) {
+			return 0, 0, false
+		}
+		x, y = x+dx, y+dy
+	}
+}
+
+// path expands the chain of jump points ending at the goal
+// into one State per cell.
+func (j *jumpPointSearch) path(start int) State {
+	g := j.grid
+	points := []int{}
+	for id := j.goal.ID; id >= 0; id = j.parent[id] {
+		points = append([]int{id}, points...)
+	}
+
+	cur := g.State(g.XY(State{ID: start}))
+	for _, id := range points[1:] {
+		tx, ty := g.XY(State{ID: id})
+		for cur.ID != id {
+			x, y := g.XY(cur)
+			dx, dy := sign(tx-x), sign(ty-y)
+			prev := cur
+			cur = g.State(x+dx, y+dy)
+			cur.ParentState = &prev
+			cur.ParentAction = gridAction(dx, dy)
+		}
+	}
+	return cur
+}
+
+func sign(n int) int {
+	switch {
+	case n > 0:
+		return 1
+	case n < 0:
+		return -1
+	}
+	return 0
+}
@@ -0,0 +1,119 @@
+package search
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func ExampleSearchJPS() {
+	g := NewGrid(5, 3)
+	g.Block(2, 0)
+	g.Block(2, 1)
+
+	goal, err := SearchJPS(g.State(4, 0), g.State(0, 0), g)
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(goal.Path())
+	// Output:
+	// [(4: N) (9: NE) (13: E) (12: E) (11: S) (6: SE) (0: )]
+}
+
+func pathCost(g *Grid, goal State) float64 {
+	c := 0.0
+	for s := &goal; s.ParentState != nil; s = s.ParentState {
+		c += g.StepCost(*s.ParentState, s.ParentAction, *s)
+	}
+	return c
+}
+
+func TestSearchJPSMatchesOptimalCost(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		g := NewGrid(20, 20)
+		for n := 0; n < 100; n++ {
+			g.Block(r.Intn(20), r.Intn(20))
+		}
+		start, goal := g.State(0, 0), g.State(19, 19)
+		if g.Blocked(0, 0) || g.Blocked(19, 19) {
+			continue
+		}
+
+		d := NewDStarLite(goal, start, g, g, g, g.Octile)
+		_, derr := d.Plan()
+		s, err := SearchJPS(goal, start, g)
+		if (derr == nil) != (err == nil) {
+			t.Fatalf("case %d: solvable mismatch: %v vs %v", i, derr, err)
+		}
+		if err != nil {
+			continue
+		}
+		for p := &s; p.ParentState != nil; p = p.ParentState {
+			x, y := g.XY(*p.ParentState)
+			nx, ny := g.XY(*p)
+			if !g.canMove(x, y, nx-x, ny-y) {
+				t.Fatalf("case %d: illegal move %v -> %v", i, *p.ParentState, *p)
+			}
+		}
+		if c := pathCost(g, s); math.Abs(c-d.Cost()) > 1e-9 {
+			t.Errorf("case %d: cost %v, want %v", i, c, d.Cost())
+		}
+	}
+}
+
+func TestSearchJPSExpandsLessThanDStarLite(t *testing.T) {
+	g := NewGrid(50, 50)
+	for y := 0; y < 40; y++ {
+		g.Block(25, y)
+	}
+	start, goal := g.State(0, 0), g.State(49, 0)
+
+	j := newJumpPointSearch(goal, g)
+	if _, err := j.search(start); err != nil {
+		t.Fatal(err)
+	}
+	d := NewDStarLite(goal, start, g, g, g, g.Octile)
+	d.Plan()
+	if j.expanded*5 > d.Expanded() {
+		t.Errorf("jps expanded %d states, d* lite %d", j.expanded, d.Expanded())
+	}
+}
+
+func TestGridPredecessors(t *testing.T) {
+	g := NewGrid(4, 4)
+	g.Block(1, 1)
+	g.Block(2, 2)
+	for id := 0; id < 16; id++ {
+		want := map[int]bool{}
+		for from := 0; from < 16; from++ {
+			if x, y := g.XY(State{ID: from}); g.Blocked(x, y) {
+				continue
+			}
+			for _, a := range g.Actions(State{ID: from}) {
+				if g.NextState(State{ID: from}, a).ID == id {
+					want[from] = true
+				}
+			}
+		}
+		got := map[int]bool{}
+		for _, p := range g.Predecessors(State{ID: id}) {
+			got[p.ID] = true
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("predecessors of %d: %v, want %v", id, got, want)
+		}
+	}
+}
+
+func TestSearchJPSNoPath(t *testing.T) {
+	g := NewGrid(3, 3)
+	for y := 0; y < 3; y++ {
+		g.Block(1, y)
+	}
+	if _, err := SearchJPS(g.State(2, 2), g.State(0, 0), g); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,294 @@
+package search
+
+import (
+	"fmt"
+	"sort"
+)
+
+// KShortest returns up to k loopless paths from s to goal in order of
+// increasing cost, using Yen's algorithm. Each returned goal state's Path
+// runs back to s, as with Search.
+// A nil sc gives every action a cost of 1.
+//
+// If k is not positive no paths are returned. If there is no path at
+// all, error is non-nil.
+func KShortest(goal, s State, tm NextStateter, aa Actionsner, sc StepCoster, k int) ([]State, error) {
+	if k < 1 {
+		return []State{}, nil
+	}
+	y := &yen{goal: goal, transitionModel: tm, availableActions: aa, stepCost: sc}
+	first, ok := y.shortest(bare(s), map[int]bool{}, map[outcomeKey]bool{})
+	if !ok {
+		return nil, fmt.Errorf("search failed to find goal")
+	}
+
+	found := []weightedPath{first}
+	candidates := []weightedPath{}
+	for len(found) < k {
+		last := found[len(found)-1]
+		for i := 0; i < len(last.steps)-1; i++ {
+			root := last.steps[:i+1]
+			removedEdges := map[outcomeKey]bool{}
+			for _, p := range found {
+				if len(p.steps) > i+1 && sameSteps(p.steps[:i+1], root) {
+					removedEdges[outcomeKey{p.steps[i].state.ID, p.steps[i+1].action.ID}] = true
+				}
+			}
+			removedNodes := map[int]bool{}
+			for _, r := range root[:i] {
+				removedNodes[r.state.ID] = true
+			}
+
+			spur, ok := y.shortest(root[i].state, removedNodes, removedEdges)
+			if !ok {
+				continue
+			}
+			total := weightedPath{steps: append(append([]pathStep{}, root...), spur.steps[1:]...)}
+			total.cost = y.cost(total.steps)
+			if !containsPath(found, total) && !containsPath(candidates, total) {
+				candidates = append(candidates, total)
+			}
+		}
+		if len(candidates) == 0 {
+			break
+		}
+		sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].cost < candidates[j].cost })
+		found = append(found, candidates[0])
+		candidates = candidates[1:]
+	}
+
+	ss := []State{}
+	for _, p := range found {
+		ss = append(ss, p.goalState())
+	}
+	return ss, nil
+}
+
+type pathStep struct {
+	state  State
+	action Action
+}
+
+type weightedPath struct {
+	steps []pathStep
+	cost  float64
+}
+
+// goalState links the steps of p into the State chain that Path walks.
+func (p weightedPath) goalState() State {
+	cur := p.steps[0].state
+	for _, st := range p.steps[1:] {
+		prev := cur
+		cur = st.state
+		cur.ParentState = &prev
+		cur.ParentAction = st.action
+	}
+	return cur
+}
+
+func sameSteps(a, b []pathStep) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].state.ID != b[i].state.ID || a[i].action.ID != b[i].action.ID {
+			return false
+		}
+	}
+	return true
+}
+
+func containsPath(pp []weightedPath, p weightedPath) bool {
+	for _, q := range pp {
+		if sameSteps(q.steps, p.steps) {
+			return true
+		}
+	}
+	return false
+}
+
+type yen struct {
+	goal             State
+	transitionModel  NextStateter
+	availableActions Actionsner
+	stepCost         StepCoster
+}
+
+func (y *yen) stepCostOf(s State, a Action, next State) float64 {
+	if y.stepCost == nil {
+		return 1
+	}
+	return y.stepCost.StepCost(s, a, next)
+}
+
+func (y *yen) cost(steps []pathStep) float64 {
+	c := 0.0
+	for i := 1; i < len(steps); i++ {
+		c += y.stepCostOf(steps[i-1].state, steps[i].action, steps[i].state)
+	}
+	return c
+}
+
+// shortest is Dijkstra's algorithm from s to the goal that
+// avoids the removed states and edges.
+func (y *yen) shortest(s State, removedNodes map[int]bool, removedEdges map[outcomeKey]bool) (weightedPath, bool) {
+	dist := map[int]float64{s.ID: 0}
+	prev := map[int]pathStep{}
+	states := map[int]State{s.ID: s}
+	done := map[int]bool{}
+	q := &dQueue{pos: map[int]*dItem{}}
+	q.upsert(s.ID, dKey{0, 0})
+	seq := 0.0
+	for q.Len() > 0 {
+		u := q.top().id
+		q.remove(u)
+		done[u] = true
+		if u == y.goal.ID {
+			break
+		}
+		for _, a := range y.availableActions.Actions(states[u]) {
+			if removedEdges[outcomeKey{u, a.ID}] {
+				continue
+			}
+			w := bare(y.transitionModel.NextState(states[u], a))
+			if removedNodes[w.ID] || done[w.ID] {
+				continue
+			}
+			d := dist[u] + y.stepCostOf(states[u], a, w)
+			if old, ok := dist[w.ID]; ok && d >= old {
+				continue
+			}
+			dist[w.ID] = d
+			states[w.ID] = w
+			prev[w.ID] = pathStep{state: states[u], action: a}
+			seq++
+			q.upsert(w.ID, dKey{d, seq})
+		}
+	}
+	if !done[y.goal.ID] {
+		return weightedPath{}, false
+	}
+
+	steps := []pathStep{{state: states[y.goal.ID]}}
+	for id := y.goal.ID; id != s.ID; {
+		p := prev[id]
+		steps[0].action = p.action
+		steps = append([]pathStep{{state: p.state}}, steps...)
+		id = p.state.ID
+	}
+	return weightedPath{steps: steps, cost: dist[y.goal.ID]}, true
+}
+
+// Bound limits the paths enumerated by Solutions.
+// A zero field means that dimension is unbounded.
+type Bound struct {
+	MaxDepth int
+	MaxCost  float64
+}
+
+// Solutions returns an iterator over every loopless path from s to goal
+// within bound b, in depth-first order. A nil sc gives every action a
+// cost of 1. Leaving b unbounded is only safe on finite state spaces.
+//
+//	it := Solutions(goal, start, tm, aa, nil, Bound{MaxDepth: 10})
+//	for it.Next() {
+//		fmt.Println(it.State().Path())
+//	}
+func Solutions(goal, s State, tm NextStateter, aa Actionsner, sc StepCoster, b Bound) *SolutionIterator {
+	it := &SolutionIterator{
+		goal:             goal,
+		transitionModel:  tm,
+		availableActions: aa,
+		bound:            b,
+		y:                &yen{stepCost: sc},
+		onPath:           map[int]bool{},
+	}
+	it.push(pathStep{state: bare(s)}, 0)
+	return it
+}
+
+// SolutionIterator streams solutions found by Solutions.
+type SolutionIterator struct {
+	goal             State
+	transitionModel  NextStateter
+	availableActions Actionsner
+	bound            Bound
+	y                *yen
+
+	stack   []solutionFrame
+	onPath  map[int]bool
+	current weightedPath
+}
+
+type solutionFrame struct {
+	step    pathStep
+	cost    float64
+	actions []Action
+	next    int
+	checked bool
+}
+
+func (it *SolutionIterator) push(st pathStep, cost float64) {
+	it.stack = append(it.stack, solutionFrame{
+		step:    st,
+		cost:    cost,
+		actions: it.availableActions.Actions(st.state),
+	})
+	it.onPath[st.state.ID] = true
+}
+
+func (it *SolutionIterator) pop() {
+	top := it.stack[len(it.stack)-1]
+	delete(it.onPath, top.step.state.ID)
+	it.stack = it.stack[:len(it.stack)-1]
+}
+
+// Next advances to the next solution and reports whether there is one.
+func (it *SolutionIterator) Next() bool {
+	for len(it.stack) > 0 {
+		top := &it.stack[len(it.stack)-1]
+		if !top.checked {
+			top.checked = true
+			if top.step.state.ID == it.goal.ID {
+				top.next = len(top.actions) // a path does not continue through the goal.
+				it.current = it.snapshot()
+				return true
+			}
+		}
+		if top.next >= len(top.actions) || (it.bound.MaxDepth > 0 && len(it.stack)-1 >= it.bound.MaxDepth) {
+			it.pop()
+			continue
+		}
+		a := top.actions[top.next]
+		top.next++
+		w := bare(it.transitionModel.NextState(top.step.state, a))
+		if it.onPath[w.ID] {
+			continue
+		}
+		c := top.cost + it.y.stepCostOf(top.step.state, a, w)
+		if it.bound.MaxCost > 0 && c > it.bound.MaxCost {
+			continue
+		}
+		it.push(pathStep{state: w, action: a}, c)
+	}
+	return false
+}
+
+func (it *SolutionIterator) snapshot() weightedPath {
+	steps := []pathStep{}
+	for _, f := range it.stack {
+		steps = append(steps, f.step)
+	}
+	return weightedPath{steps: steps, cost: it.stack[len(it.stack)-1].cost}
+}
+
+// State returns the goal state of the current solution.
+// Path can then be called on it to provide the full path.
+func (it *SolutionIterator) State() State {
+	return it.current.goalState()
+}
+
+// Cost returns the cost of the current solution.
+func (it *SolutionIterator) Cost() float64 {
+	return it.current.cost
+}
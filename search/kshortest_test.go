@@ -0,0 +1,145 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// yenGraph is the example graph from the wikipedia article on
+// Yen's algorithm. States are C to H and the action to reach a state has
+// that state's ID.
+type yenGraph struct{}
+
+var yenEdges = map[string]map[string]float64{
+	"C": {"D": 3, "E": 2},
+	"D": {"F": 4},
+	"E": {"D": 1, "F": 2, "G": 3},
+	"F": {"G": 2, "H": 1},
+	"G": {"H": 2},
+}
+
+func yenState(name string) State {
+	return State{ID: int(name[0]), Description: name}
+}
+
+func (yenGraph) Actions(s State) []Action {
+	aa := []Action{}
+	for _, to := range []string{"C", "D", "E", "F", "G", "H"} {
+		if _, ok := yenEdges[s.Description][to]; ok {
+			aa = append(aa, Action{ID: int(to[0]), Name: to})
+		}
+	}
+	return aa
+}
+
+func (yenGraph) NextState(s State, a Action) State {
+	return yenState(a.Name)
+}
+
+func (yenGraph) StepCost(s State, a Action, next State) float64 {
+	return yenEdges[s.Description][next.Description]
+}
+
+func route(g State) string {
+	p := g.Path()
+	names := []string{}
+	for i := len(p) - 1; i >= 0; i-- {
+		names = append(names, p[i].Description)
+	}
+	return strings.Join(names, "")
+}
+
+func ExampleKShortest() {
+	yg := yenGraph{}
+	ss, err := KShortest(yenState("H"), yenState("C"), yg, yg, yg, 3)
+	if err != nil {
+		fmt.Println(err)
+	}
+	for _, s := range ss {
+		fmt.Println(route(s))
+	}
+	// Output:
+	// CEFH
+	// CEGH
+	// CDFH
+}
+
+func ExampleSolutions() {
+	yg := yenGraph{}
+	it := Solutions(yenState("H"), yenState("C"), yg, yg, yg, Bound{MaxCost: 8})
+	for it.Next() {
+		fmt.Println(route(it.State()), it.Cost())
+	}
+	// Output:
+	// CDFH 8
+	// CEDFH 8
+	// CEFGH 8
+	// CEFH 5
+	// CEGH 7
+}
+
+func TestKShortestMoreThanExist(t *testing.T) {
+	yg := yenGraph{}
+	ss, err := KShortest(yenState("H"), yenState("C"), yg, yg, yg, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ss) != 7 {
+		t.Errorf("unexpected number of paths: %d", len(ss))
+	}
+	all := 0
+	it := Solutions(yenState("H"), yenState("C"), yg, yg, yg, Bound{})
+	for it.Next() {
+		all++
+	}
+	if all != len(ss) {
+		t.Errorf("yen found %d paths, enumeration %d", len(ss), all)
+	}
+	for i := 1; i < len(ss); i++ {
+		if c0, c1 := pathCostOf(ss[i-1]), pathCostOf(ss[i]); c0 > c1 {
+			t.Errorf("paths out of order: %v before %v", c0, c1)
+		}
+	}
+}
+
+func pathCostOf(g State) float64 {
+	c := 0.0
+	for s := &g; s.ParentState != nil; s = s.ParentState {
+		c += yenGraph{}.StepCost(*s.ParentState, s.ParentAction, *s)
+	}
+	return c
+}
+
+func TestSolutionsDepthBound(t *testing.T) {
+	tm := transitionModel{}
+	aa := availableActions{}
+	it := Solutions(State{ID: 4}, State{ID: 12}, tm, aa, nil, Bound{MaxDepth: 8})
+	n := 0
+	for it.Next() {
+		n++
+		if it.Cost() != 8 {
+			t.Errorf("unexpected cost: %v", it.Cost())
+		}
+	}
+	if n != 1 {
+		t.Errorf("unexpected number of solutions: %d", n)
+	}
+}
+
+func TestKShortestNoPath(t *testing.T) {
+	yg := yenGraph{}
+	if _, err := KShortest(yenState("C"), yenState("H"), yg, yg, yg, 2); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestKShortestNone(t *testing.T) {
+	yg := yenGraph{}
+	for _, k := range []int{0, -1} {
+		ss, err := KShortest(yenState("H"), yenState("C"), yg, yg, yg, k)
+		if err != nil || len(ss) != 0 {
+			t.Errorf("k=%d: unexpected result: %v, %v", k, ss, err)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package search
+
+import (
+	"fmt"
+	"math"
+)
+
+// Agent is an online search agent. It does not know the transition model
+// and instead learns it by acting: Step receives the current state as its
+// percept and returns the action to take next, or Stop.
+type Agent interface {
+	Step(percept State) Action
+}
+
+// Stop is returned by an Agent when it has reached the goal
+// or has no action left worth taking.
+var Stop = Action{ID: -1, Name: "stop"}
+
+// RunOnline drives agent from state s in the environment tm,
+// which is hidden from the agent, for at most maxSteps actions.
+// It returns the state the agent stopped in and a nil error if that is goal.
+// Path can then be called on it to provide the trajectory the agent took.
+func RunOnline(goal, s State, tm NextStateter, agent Agent, maxSteps int) (State, error) {
+	cur := s
+	for n := 0; n < maxSteps; n++ {
+		a := agent.Step(bare(cur))
+		if a.ID == Stop.ID {
+			if cur.ID != goal.ID {
+				return cur, fmt.Errorf("agent stopped before reaching goal")
+			}
+			return cur, nil
+		}
+		prev := cur
+		cur = tm.NextState(bare(cur), a)
+		cur.ParentState = &prev
+		cur.ParentAction = a
+	}
+	return cur, fmt.Errorf("agent did not stop within %d steps", maxSteps)
+}
+
+type outcomeKey struct {
+	s, a int
+}
+
+// OnlineDFSAgent explores depth-first by acting, backtracking physically
+// when a state has no untried actions left.
+// Every action must be reversible for it to work.
+type OnlineDFSAgent struct {
+	goal             State
+	availableActions Actionsner
+
+	result        map[outcomeKey]int
+	untried       map[int][]Action
+	unbacktracked map[int][]int
+	actions       map[int][]Action
+	s             *State
+	a             Action
+}
+
+// NewOnlineDFSAgent returns an online depth-first search agent looking for goal.
+func NewOnlineDFSAgent(goal State, aa Actionsner) *OnlineDFSAgent {
+	return &OnlineDFSAgent{
+		goal:             goal,
+		availableActions: aa,
+		result:           map[outcomeKey]int{},
+		untried:          map[int][]Action{},
+		unbacktracked:    map[int][]int{},
+		actions:          map[int][]Action{},
+	}
+}
+
+// Pseudocode from Russell and Norvig, AIMA 3rd ed. figure 4.21:
+//
+//	function ONLINE-DFS-AGENT(s') returns an action
+//	    if GOAL-TEST(s') then return stop
+//	    if s' is a new state then untried[s'] := ACTIONS(s')
+//	    if s is not null then
+//	        result[s, a] := s'
+//	        add s to the front of unbacktracked[s']
+//	    if untried[s'] is empty then
+//	        if unbacktracked[s'] is empty then return stop
+//	        else a := an action b such that result[s', b] = POP(unbacktracked[s'])
+//	    else a := POP(untried[s'])
+//	    s := s'
+//	    return a
+//
+// s is only added to unbacktracked[s'] the first time result[s, a] is
+// learned, otherwise backtracking would queue the state it came from again.
+func (o *OnlineDFSAgent) Step(percept State) Action {
+	sp := percept.ID
+	if sp == o.goal.ID {
+		o.s = nil
+		return Stop
+	}
+	if _, seen := o.actions[sp]; !seen {
+		o.actions[sp] = o.availableActions.Actions(percept)
+		o.untried[sp] = append([]Action{}, o.actions[sp]...)
+	}
+	if o.s != nil {
+		k := outcomeKey{o.s.ID, o.a.ID}
+		if _, known := o.result[k]; !known {
+			o.result[k] = sp
+			if o.s.ID != sp {
+				o.unbacktracked[sp] = append([]int{o.s.ID}, o.unbacktracked[sp]...)
+			}
+		}
+	}
+
+	if len(o.untried[sp]) == 0 {
+		a, ok := o.backtrack(sp)
+		if !ok {
+			o.s = nil
+			return Stop
+		}
+		o.a = a
+	} else {
+		o.a = o.untried[sp][0]
+		o.untried[sp] = o.untried[sp][1:]
+	}
+	o.s = &State{ID: sp}
+	return o.a
+}
+
+func (o *OnlineDFSAgent) backtrack(sp int) (Action, bool) {
+	for len(o.unbacktracked[sp]) > 0 {
+		to := o.unbacktracked[sp][0]
+		o.unbacktracked[sp] = o.unbacktracked[sp][1:]
+		for _, b := range o.actions[sp] {
+			if r, ok := o.result[outcomeKey{sp, b.ID}]; ok && r == to {
+				return b, true
+			}
+		}
+	}
+	return Action{}, false
+}
+
+// LRTAStarAgent is a learning real-time A* agent. It moves to the
+// apparently best neighbour and updates its cost-to-goal estimate for the
+// state it left, so repeated trials converge on an optimal path.
+//
+// After reaching the goal the agent forgets its position but keeps what
+// it has learned, so it may be run again from a new start.
+type LRTAStarAgent struct {
+	goal             State
+	availableActions Actionsner
+	stepCost         StepCoster
+	h                Heuristic
+
+	result  map[outcomeKey]int
+	est     map[int]float64
+	actions map[int][]Action
+	s       *State
+	a       Action
+}
+
+// NewLRTAStarAgent returns an LRTA* agent looking for goal.
+// A nil sc gives every action a cost of 1 and a nil h estimates 0 everywhere.
+func NewLRTAStarAgent(goal State, aa Actionsner, sc StepCoster, h Heuristic) *LRTAStarAgent {
+	if h == nil {
+		h = func(a, b State) float64 { return 0 }
+	}
+	return &LRTAStarAgent{
+		goal:             goal,
+		availableActions: aa,
+		stepCost:         sc,
+		h:                h,
+		result:           map[outcomeKey]int{},
+		est:              map[int]float64{},
+		actions:          map[int][]Action{},
+	}
+}
+
+// Pseudocode from Russell and Norvig, AIMA 3rd ed. figure 4.24:
+//
+//	function LRTA*-AGENT(s') returns an action
+//	    if GOAL-TEST(s') then return stop
+//	    if s' is a new state (not in H) then H[s'] := h(s')
+//	    if s is not null
+//	        result[s, a] := s'
+//	        H[s] := min over b in ACTIONS(s) of LRTA*-COST(s, b, result[s, b], H)
+//	    a := an action b in ACTIONS(s') that minimizes LRTA*-COST(s', b, result[s', b], H)
+//	    s := s'
+//	    return a
+//
+//	function LRTA*-COST(s, a, s', H) returns a cost estimate
+//	    if s' is undefined then return h(s)
+//	    else return c(s, a, s') + H[s']
+//
+// The goal test is made after learning from the last move, so the
+// step into the goal is remembered for later trials.
+func (l *LRTAStarAgent) Step(percept State) Action {
+	sp := percept.ID
+	if _, seen := l.est[sp]; !seen && sp != l.goal.ID {
+		l.est[sp] = l.h(percept, l.goal)
+		l.actions[sp] = l.availableActions.Actions(percept)
+	}
+	if l.s != nil {
+		l.result[outcomeKey{l.s.ID, l.a.ID}] = sp
+		best := math.Inf(1)
+		for _, b := range l.actions[l.s.ID] {
+			best = math.Min(best, l.cost(l.s.ID, b))
+		}
+		l.est[l.s.ID] = best
+	}
+
+	if sp == l.goal.ID || len(l.actions[sp]) == 0 {
+		l.s = nil
+		return Stop
+	}
+	l.a = l.actions[sp][0]
+	best := l.cost(sp, l.a)
+	for _, b := range l.actions[sp][1:] {
+		if c := l.cost(sp, b); c < best {
+			l.a, best = b, c
+		}
+	}
+	l.s = &State{ID: sp}
+	return l.a
+}
+
+func (l *LRTAStarAgent) cost(s int, a Action) float64 {
+	next, ok := l.result[outcomeKey{s, a.ID}]
+	if !ok {
+		return l.h(State{ID: s}, l.goal)
+	}
+	c := 1.0
+	if l.stepCost != nil {
+		c = l.stepCost.StepCost(State{ID: s}, a, State{ID: next})
+	}
+	return c + l.est[next]
+}
+
+// Estimate returns the agent's learned cost-to-goal estimate for s.
+func (l *LRTAStarAgent) Estimate(s State) float64 {
+	if v, ok := l.est[s.ID]; ok {
+		return v
+	}
+	return l.h(s, l.goal)
+}
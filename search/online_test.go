@@ -0,0 +1,72 @@
+package search
+
+import (
+	"fmt"
+	"testing"
+)
+
+func ExampleRunOnline() {
+	tm := transitionModel{}
+	aa := availableActions{}
+
+	agent := NewLRTAStarAgent(State{ID: 4}, aa, nil, func(a, b State) float64 {
+		d := float64(a.ID - b.ID)
+		if d < 0 {
+			return -d
+		}
+		return d
+	})
+	g, err := RunOnline(State{ID: 4}, State{ID: 12}, tm, agent, 100)
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(g.Path())
+	// Output:
+	// [(4: <--) (5: <--) (6: <--) (7: <--) (8: <--) (9: <--) (10: <--) (11: <--) (12: )]
+}
+
+func TestOnlineDFSAgent(t *testing.T) {
+	gw := gridWorld{w: 4, h: 4}
+	goal := State{ID: 15}
+	agent := NewOnlineDFSAgent(goal, gw)
+	g, err := RunOnline(goal, State{ID: 0}, gw, agent, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.ID != goal.ID {
+		t.Errorf("unexpected state: %v", g)
+	}
+}
+
+func TestOnlineDFSAgentUnreachable(t *testing.T) {
+	gw := gridWorld{w: 3, h: 3}
+	agent := NewOnlineDFSAgent(State{ID: 99}, gw)
+	_, err := RunOnline(State{ID: 99}, State{ID: 4}, gw, agent, 1000)
+	if err == nil || err.Error() != "agent stopped before reaching goal" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestLRTAStarAgentLearns(t *testing.T) {
+	gw := gridWorld{w: 5, h: 5}
+	goal := State{ID: 24}
+	agent := NewLRTAStarAgent(goal, gw, nil, nil)
+
+	lengths := []int{}
+	for trial := 0; trial < 30; trial++ {
+		g, err := RunOnline(goal, State{ID: 0}, gw, agent, 1000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lengths = append(lengths, len(g.Path())-1)
+	}
+	if lengths[0] <= 8 {
+		t.Errorf("uninformed first trial should wander, took %d steps", lengths[0])
+	}
+	if last := lengths[len(lengths)-1]; last != 8 {
+		t.Errorf("trials did not converge to optimal path: %v", lengths)
+	}
+	if e := agent.Estimate(State{ID: 0}); e != 8 {
+		t.Errorf("unexpected estimate: %v", e)
+	}
+}
@@ -0,0 +1,70 @@
+package search
+
+import "container/heap"
+
+// dKey orders the entries of a dQueue lexicographically, so the second
+// element breaks ties in the first.
+type dKey [2]float64
+
+func (k dKey) less(o dKey) bool {
+	if k[0] != o[0] {
+		return k[0] < o[0]
+	}
+	return k[1] < o[1]
+}
+
+// dQueue is a priority queue of state IDs that supports
+// removal and key updates of arbitrary entries. D* Lite introduced it;
+// the other searches that need a priority queue share it.
+type dQueue struct {
+	items []*dItem
+	pos   map[int]*dItem
+}
+
+type dItem struct {
+	id    int
+	key   dKey
+	index int
+}
+
+func (q *dQueue) Len() int           { return len(q.items) }
+func (q *dQueue) Less(i, j int) bool { return q.items[i].key.less(q.items[j].key) }
+func (q *dQueue) Swap(i, j int) {
+	q.items[i], q.items[j] = q.items[j], q.items[i]
+	q.items[i].index = i
+	q.items[j].index = j
+}
+func (q *dQueue) Push(x interface{}) {
+	it := x.(*dItem)
+	it.index = len(q.items)
+	q.items = append(q.items, it)
+}
+func (q *dQueue) Pop() interface{} {
+	it := q.items[len(q.items)-1]
+	q.items = q.items[:len(q.items)-1]
+	return it
+}
+
+func (q *dQueue) top() *dItem {
+	return q.items[0]
+}
+
+func (q *dQueue) upsert(id int, k dKey) {
+	if it, ok := q.pos[id]; ok {
+		it.key = k
+		heap.Fix(q, it.index)
+		return
+	}
+	it := &dItem{id: id, key: k}
+	q.pos[id] = it
+	heap.Push(q, it)
+}
+
+func (q *dQueue) remove(id int) {
+	it, ok := q.pos[id]
+	if !ok {
+		return
+	}
+	heap.Remove(q, it.index)
+	delete(q.pos, id)
+}
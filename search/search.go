@@ -21,6 +21,26 @@ func Search(goal, s State, tm NextStateter, aa Actionsner) (State, error) {
 
 // schE OMIT
 
+// GoalTest reports whether s is a goal state.
+type GoalTest func(s State) bool
+
+// Goals returns the GoalTest passed by the states with the IDs of goals.
+func Goals(goals ...State) GoalTest {
+	ids := map[int]bool{}
+	for _, g := range goals {
+		ids[g.ID] = true
+	}
+	return func(s State) bool { return ids[s.ID] }
+}
+
+// SearchGoal is like Search but returns the first state found that
+// passes goal, for problems with more than one goal state.
+func SearchGoal(goal GoalTest, s State, tm NextStateter, aa Actionsner) (State, error) {
+	searcher := newBreadthFirstSearch(State{}, s, tm, aa)
+	searcher.goalTest = goal
+	return searcher.search(s)
+}
+
 // NextStateter is an interface for a transition model that calls NextState.
 type NextStateter interface {
 	NextState(s State, a Action) State
@@ -87,6 +107,7 @@ type breadthFirstSearch struct {
 	discovered       map[State]struct{}
 	transitionModel  NextStateter
 	availableActions Actionsner
+	goalTest         GoalTest // if set, used in place of Goal
 }
 
 // Pseudocode from wikipedia below, where start_v is
@@ -149,6 +170,9 @@ func (b *breadthFirstSearch) dequeue() State {
 	return s
 }
 func (b *breadthFirstSearch) atGoal(s State) bool {
+	if b.goalTest != nil {
+		return b.goalTest(s)
+	}
 	return s.ID == b.Goal.ID
 }
 